@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// annotationsFileName is the name of the file in the data directory that
+// holds the annotations of documents that have no sidecar file.
+const annotationsFileName = "annotations.json"
+
+// annotationSidecarSuffix is appended to the path of a local document to name
+// the sidecar file that holds its annotations.
+const annotationSidecarSuffix = ".annotations.json"
+
+// annotationSummarySuffix replaces the extension of a local document to name
+// the file that its annotation summary is exported to.
+const annotationSummarySuffix = ".annotations.md"
+
+// annotationSidecar is the contents of a sidecar file.
+type annotationSidecar struct {
+	Annotations []mdk.Annotation `json:"annotations"`
+}
+
+// annotationStore loads and saves the annotations of documents. Annotations of
+// local files are kept in a sidecar file next to the document, so that they
+// travel with it; annotations of other documents, or of every document if
+// sidecars are disabled, are kept in a file in the data directory.
+type annotationStore struct {
+	path     string
+	fsys     fileSystem
+	sidecars bool
+
+	// Documents maps a document source to its annotations.
+	Documents map[string][]mdk.Annotation `json:"documents,omitempty"`
+}
+
+// loadAnnotations reads the annotations file at the given path. A missing
+// file yields an empty store.
+func loadAnnotations(path string, fsys fileSystem, sidecars bool) (*annotationStore, error) {
+	s := &annotationStore{path: path, fsys: fsys, sidecars: sidecars}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// isLocalSource reports whether a document source is a local file.
+func isLocalSource(source string) bool {
+	return source != "" && !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://")
+}
+
+// sidecarPath returns the path of the sidecar file of the given document, or
+// false if its annotations are kept in the data directory.
+func (s *annotationStore) sidecarPath(source string) (string, bool) {
+	if !s.sidecars || !isLocalSource(source) {
+		return "", false
+	}
+	return source + annotationSidecarSuffix, true
+}
+
+// load returns the annotations of the given document.
+func (s *annotationStore) load(source string) ([]mdk.Annotation, error) {
+	path, ok := s.sidecarPath(source)
+	if !ok {
+		return s.Documents[source], nil
+	}
+	data, err := s.fsys.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var sidecar annotationSidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return sidecar.Annotations, nil
+}
+
+// save writes the annotations of the given document. A sidecar file is only
+// created once a document has annotations.
+func (s *annotationStore) save(source string, annotations []mdk.Annotation) error {
+	path, ok := s.sidecarPath(source)
+	if !ok {
+		if s.path == "" {
+			return fmt.Errorf("no data directory")
+		}
+		if len(annotations) == 0 {
+			delete(s.Documents, source)
+		} else {
+			if s.Documents == nil {
+				s.Documents = map[string][]mdk.Annotation{}
+			}
+			s.Documents[source] = annotations
+		}
+		return writeJSON(s.fsys, s.path, s)
+	}
+
+	if len(annotations) == 0 {
+		if _, err := s.fsys.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		annotations = []mdk.Annotation{}
+	}
+	return writeJSON(s.fsys, path, annotationSidecar{Annotations: annotations})
+}
+
+// writeJSON writes a value to the given path as indented JSON.
+func writeJSON(fsys fileSystem, path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return fsys.WriteFile(path, data, 0o644)
+}
+
+// restoreAnnotations sets the saved annotations of a tab's document.
+func (r *markdownReader) restoreAnnotations(t *tab) {
+	if r.annotations == nil || t.currentSource == "" || t.showSource {
+		return
+	}
+	annotations, err := r.annotations.load(t.currentSource)
+	if err != nil {
+		r.logger.Error("annotations_load_error", "source", t.currentSource, "error", err)
+		return
+	}
+	t.view.SetAnnotations(annotations)
+}
+
+// saveAnnotations writes the annotations of the active tab's document,
+// reporting any error in a dialog.
+func (r *markdownReader) saveAnnotations() tea.Cmd {
+	at := r.active()
+	if r.annotations == nil || at.currentSource == "" || at.showSource {
+		return r.flashStatus("Annotations on this page are not saved")
+	}
+	annotations := at.view.Annotations()
+	if err := r.annotations.save(at.currentSource, annotations); err != nil {
+		r.logger.Error("annotations_write_error", "source", at.currentSource, "error", err)
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error saving annotations: %v", err)
+	}
+
+	// Other tabs may show the same document, e.g. beside it in a split.
+	for i := range r.tabs {
+		if t := &r.tabs[i]; t != at && t.currentSource == at.currentSource && !t.showSource {
+			t.view.SetAnnotations(annotations)
+		}
+	}
+	return nil
+}
+
+// exportAnnotations writes the annotations of the active tab's document as a
+// Markdown summary. The summary of a local file is written next to it; the
+// summary of any other document is copied to the clipboard.
+func (r *markdownReader) exportAnnotations() tea.Cmd {
+	at := r.active()
+	summary := at.view.AnnotationSummary()
+	if summary == "" {
+		return r.flashStatus("No annotations to export")
+	}
+	if at.showSource || !isLocalSource(at.currentSource) {
+		return tea.Batch(r.copyText(summary), r.flashStatus("Copied annotation summary"))
+	}
+
+	path := strings.TrimSuffix(at.currentSource, filepath.Ext(at.currentSource)) + annotationSummarySuffix
+	if _, err := r.fsys.Stat(path); err == nil {
+		return r.flashStatus(fmt.Sprintf("%s already exists", filepath.Base(path)))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error exporting annotations: %v", err)
+		return nil
+	}
+	if err := r.fsys.WriteFile(path, []byte(summary), 0o644); err != nil {
+		r.logger.Error("annotations_export_error", "path", path, "error", err)
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error exporting annotations: %v", err)
+		return nil
+	}
+	return r.flashStatus("Wrote " + filepath.Base(path))
+}
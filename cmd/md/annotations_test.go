@@ -0,0 +1,154 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+const annotationsPath = "/data/md/annotations.json"
+
+const annotatedDoc = "# Design\n\nSee [the docs](https://docs.example) first.\n"
+
+// annotationReader returns a sized reader for the given source with an empty
+// annotation store.
+func annotationReader(t *testing.T, source string, fs *memFS, sidecars bool) markdownReader {
+	t.Helper()
+	r := testReader("", annotatedDoc, source)
+	r.fsys = fs
+	store, err := loadAnnotations(annotationsPath, fs, sidecars)
+	if err != nil {
+		t.Fatalf("loadAnnotations: %v", err)
+	}
+	r.annotations = store
+	r.restoreAnnotations(r.active())
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	return model.(markdownReader)
+}
+
+func TestLoadAnnotations_Invalid(t *testing.T) {
+	fs := newMemFS()
+	fs.files[annotationsPath] = []byte("{")
+	if _, err := loadAnnotations(annotationsPath, fs, true); err == nil {
+		t.Error("expected an error for an invalid annotations file")
+	}
+}
+
+func TestAnnotationStore_Sidecar(t *testing.T) {
+	fs := newMemFS()
+	s, err := loadAnnotations(annotationsPath, fs, true)
+	if err != nil {
+		t.Fatalf("loadAnnotations: %v", err)
+	}
+
+	// Saving no annotations does not create a sidecar.
+	if err := s.save("/docs/a.md", nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if len(fs.files) != 0 {
+		t.Errorf("expected no files, got %v", fs.files)
+	}
+
+	want := []mdk.Annotation{{Text: "the docs", Offset: 15, Note: "good"}}
+	if err := s.save("/docs/a.md", want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, ok := fs.files["/docs/a.md"+annotationSidecarSuffix]; !ok {
+		t.Fatal("expected a sidecar file")
+	}
+	got, err := s.load("/docs/a.md")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("load = %+v, want %+v", got, want)
+	}
+
+	// Web pages have no sidecar.
+	if err := s.save("https://example.com/a.md", want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, ok := fs.files[annotationsPath]; !ok {
+		t.Error("expected annotations of a web page in the data directory")
+	}
+}
+
+func TestAnnotationStore_SidecarsDisabled(t *testing.T) {
+	fs := newMemFS()
+	s, _ := loadAnnotations(annotationsPath, fs, false)
+	want := []mdk.Annotation{{Text: "the docs", Note: "good"}}
+	if err := s.save("/docs/a.md", want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, ok := fs.files["/docs/a.md"+annotationSidecarSuffix]; ok {
+		t.Error("expected no sidecar file")
+	}
+
+	reloaded, err := loadAnnotations(annotationsPath, fs, false)
+	if err != nil {
+		t.Fatalf("loadAnnotations: %v", err)
+	}
+	got, _ := reloaded.load("/docs/a.md")
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("load = %+v, want %+v", got, want)
+	}
+
+	// Deleting the last annotation removes the document.
+	if err := reloaded.save("/docs/a.md", nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if len(reloaded.Documents) != 0 {
+		t.Errorf("Documents = %v, want empty", reloaded.Documents)
+	}
+}
+
+func TestUpdate_AnnotationSavedAndRestored(t *testing.T) {
+	fs := newMemFS()
+	r := annotationReader(t, "/docs/a.md", fs, true)
+
+	// The note keys reach the view's prompt rather than the reader.
+	r, _ = pressMark(t, r, "]", "a", "o", "k", "enter")
+	data, ok := fs.files["/docs/a.md"+annotationSidecarSuffix]
+	if !ok {
+		t.Fatal("expected the annotation to be saved")
+	}
+	if !strings.Contains(string(data), `"note": "ok"`) {
+		t.Errorf("sidecar = %s", data)
+	}
+
+	r = annotationReader(t, "/docs/a.md", fs, true)
+	got := r.active().view.Annotations()
+	if len(got) != 1 || got[0].Text != "the docs" || got[0].Note != "ok" {
+		t.Errorf("restored annotations = %+v", got)
+	}
+}
+
+func TestUpdate_ExportAnnotations(t *testing.T) {
+	fs := newMemFS()
+	r := annotationReader(t, "/docs/a.md", fs, true)
+	r = press(t, r, "E")
+	if _, ok := fs.files["/docs/a"+annotationSummarySuffix]; ok {
+		t.Error("expected no summary without annotations")
+	}
+
+	r.active().view.SetAnnotations([]mdk.Annotation{{Text: "the docs", Note: "Read these."}})
+	press(t, r, "E")
+	summary := string(fs.files["/docs/a"+annotationSummarySuffix])
+	want := "# Annotations: Design\n\n## Design\n\n> the docs\n\nRead these.\n"
+	if summary != want {
+		t.Errorf("summary = %q, want %q", summary, want)
+	}
+
+	// An existing summary is not overwritten.
+	r.active().view.SetAnnotations([]mdk.Annotation{{Text: "the docs", Note: "Changed."}})
+	r = press(t, r, "E")
+	if got := string(fs.files["/docs/a"+annotationSummarySuffix]); got != want {
+		t.Errorf("summary was overwritten: %q", got)
+	}
+	if !strings.Contains(ansi.Strip(r.View().Content), "a.annotations.md already exists") {
+		t.Error("expected an already exists message")
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// bookmarkPicker is a list selector with text filtering for jumping to a
+// bookmark. ctrl+d deletes the bookmark under the cursor.
+type bookmarkPicker struct {
+	input     textinput.Model
+	all       []bookmark // full list
+	filtered  []bookmark // after text filter
+	cursor    int
+	minIdx    int
+	maxIdx    int
+	height    int
+	width     int
+	selected  bool
+	deleted   string // name of a bookmark deleted since the reader last checked
+	dismissed bool
+}
+
+// newBookmarkPicker creates a bookmark picker listing the given bookmarks.
+func newBookmarkPicker(bookmarks []bookmark, height, width int) bookmarkPicker {
+	ti := textinput.New()
+	ti.Prompt = "  Filter: "
+	ti.Placeholder = "type to filter..."
+	innerW := width - 4 // account for border + padding
+	ti.SetWidth(innerW - lipgloss.Width(ti.Prompt) - 1)
+
+	listHeight := height - 1 // subtract input line
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	return bookmarkPicker{
+		input:    ti,
+		all:      append([]bookmark(nil), bookmarks...),
+		filtered: append([]bookmark(nil), bookmarks...),
+		minIdx:   0,
+		maxIdx:   listHeight - 1,
+		height:   listHeight,
+		width:    width,
+	}
+}
+
+func (bp bookmarkPicker) Update(msg tea.Msg) (bookmarkPicker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			bp.dismissed = true
+			return bp, nil
+
+		case "enter":
+			if len(bp.filtered) == 0 || bp.cursor < 0 {
+				return bp, nil
+			}
+			bp.selected = true
+			return bp, nil
+
+		case "ctrl+d":
+			if len(bp.filtered) == 0 || bp.cursor < 0 {
+				return bp, nil
+			}
+			bp.deleted = bp.filtered[bp.cursor].Name
+			for i, b := range bp.all {
+				if b.Name == bp.deleted {
+					bp.all = append(bp.all[:i:i], bp.all[i+1:]...)
+					break
+				}
+			}
+			bp.filter()
+			return bp, nil
+
+		case "up", "ctrl+p":
+			bp.cursor--
+			if bp.cursor < 0 {
+				bp.cursor = 0
+			}
+			if bp.cursor < bp.minIdx {
+				bp.minIdx = bp.cursor
+				bp.maxIdx = bp.minIdx + bp.height - 1
+			}
+			return bp, nil
+
+		case "down", "ctrl+n":
+			bp.cursor++
+			if bp.cursor >= len(bp.filtered) {
+				bp.cursor = len(bp.filtered) - 1
+			}
+			if bp.cursor < 0 {
+				bp.cursor = 0
+			}
+			if bp.cursor > bp.maxIdx {
+				bp.maxIdx = bp.cursor
+				bp.minIdx = bp.maxIdx - bp.height + 1
+			}
+			return bp, nil
+
+		default:
+			prevValue := bp.input.Value()
+			var cmd tea.Cmd
+			bp.input, cmd = bp.input.Update(msg)
+			if bp.input.Value() != prevValue {
+				bp.filter()
+			}
+			return bp, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	bp.input, cmd = bp.input.Update(msg)
+	return bp, cmd
+}
+
+// filter applies case-insensitive subsequence matching on bookmark name,
+// title, and source.
+func (bp *bookmarkPicker) filter() {
+	query := strings.ToLower(bp.input.Value())
+	bp.filtered = nil
+	for _, b := range bp.all {
+		if query == "" || strings.ToLower(b.Name) == query || subsequenceMatch(strings.ToLower(b.Title), query) || subsequenceMatch(strings.ToLower(b.Source), query) {
+			bp.filtered = append(bp.filtered, b)
+		}
+	}
+
+	if bp.cursor >= len(bp.filtered) {
+		bp.cursor = max(0, len(bp.filtered)-1)
+	}
+	bp.minIdx = 0
+	bp.maxIdx = bp.height - 1
+	if bp.cursor > bp.maxIdx {
+		bp.minIdx = bp.cursor - bp.height + 1
+		bp.maxIdx = bp.cursor
+	}
+}
+
+func (bp bookmarkPicker) View() string {
+	var s strings.Builder
+
+	s.WriteString(bp.input.View())
+	s.WriteRune('\n')
+
+	if len(bp.all) == 0 {
+		s.WriteString(hpEmptyStyle.Render("  No bookmarks. Set one with m followed by an uppercase letter."))
+		s.WriteRune('\n')
+	} else if len(bp.filtered) == 0 {
+		s.WriteString(hpEmptyStyle.Render("  No matching bookmarks."))
+		s.WriteRune('\n')
+	} else {
+		for i, b := range bp.filtered {
+			if i < bp.minIdx || i > bp.maxIdx {
+				continue
+			}
+
+			title := b.Title
+			if title == "" {
+				title = "(untitled)"
+			}
+			name := b.Name + "  " + title
+
+			// Truncate source to fit.
+			sourceMaxW := bp.width - ansi.StringWidth(name) - 6 // cursor + spaces + padding
+			source := b.Source
+			if sourceMaxW > 0 && ansi.StringWidth(source) > sourceMaxW {
+				source = "..." + source[len(source)-sourceMaxW+3:]
+			}
+
+			if i == bp.cursor {
+				s.WriteString(hpCursorStyle.Render(">") + hpSelectedStyle.Render(" "+name+"  "+source))
+			} else {
+				s.WriteString(hpCursorStyle.Render(" "))
+				s.WriteString(" " + hpNameStyle.Render(name))
+				s.WriteString("  " + hpSourceStyle.Render(source))
+			}
+			s.WriteRune('\n')
+		}
+	}
+
+	// Pad remaining height.
+	rendered := lipgloss.Height(s.String())
+	for i := rendered; i <= bp.height+1; i++ {
+		s.WriteRune('\n')
+	}
+
+	return s.String()
+}
+
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (bp *bookmarkPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 1, bp.minIdx, bp.maxIdx, len(bp.filtered))
+	if ok {
+		bp.cursor = i
+	}
+	return ok
+}
+
+// DidSelect returns whether a bookmark was chosen and, if so, the bookmark.
+func (bp bookmarkPicker) DidSelect() (bool, bookmark) {
+	if !bp.selected || bp.cursor < 0 || bp.cursor >= len(bp.filtered) {
+		return false, bookmark{}
+	}
+	return true, bp.filtered[bp.cursor]
+}
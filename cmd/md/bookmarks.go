@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// bookmarksFileName is the name of the bookmarks file in the data directory.
+const bookmarksFileName = "bookmarks.json"
+
+// bookmark is a position in a document saved with an uppercase mark.
+type bookmark struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Title  string `json:"title,omitempty"`
+	Offset int    `json:"offset"`
+}
+
+// bookmarkStore holds the global bookmarks and the lowercase marks of each
+// document. It is persisted as JSON.
+type bookmarkStore struct {
+	path string
+	fsys fileSystem
+
+	// Bookmarks are the uppercase marks, ordered by name.
+	Bookmarks []bookmark `json:"bookmarks,omitempty"`
+	// Marks maps a document source to the source offsets of its lowercase
+	// marks.
+	Marks map[string]map[string]int `json:"marks,omitempty"`
+}
+
+// loadBookmarks reads the bookmarks file at the given path. A missing file
+// yields an empty store.
+func loadBookmarks(path string, fsys fileSystem) (*bookmarkStore, error) {
+	s := &bookmarkStore{path: path, fsys: fsys}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// save writes the store to its file.
+func (s *bookmarkStore) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.fsys.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return s.fsys.WriteFile(s.path, data, 0o644)
+}
+
+// lookup returns the bookmark with the given name.
+func (s *bookmarkStore) lookup(name string) (bookmark, bool) {
+	for _, b := range s.Bookmarks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return bookmark{}, false
+}
+
+// set adds a bookmark, replacing any bookmark with the same name.
+func (s *bookmarkStore) set(b bookmark) {
+	s.remove(b.Name)
+	s.Bookmarks = append(s.Bookmarks, b)
+	sort.Slice(s.Bookmarks, func(i, j int) bool {
+		return s.Bookmarks[i].Name < s.Bookmarks[j].Name
+	})
+}
+
+// remove deletes the bookmark with the given name, if any.
+func (s *bookmarkStore) remove(name string) {
+	for i, b := range s.Bookmarks {
+		if b.Name == name {
+			s.Bookmarks = append(s.Bookmarks[:i], s.Bookmarks[i+1:]...)
+			return
+		}
+	}
+}
+
+// setMark records a lowercase mark for the given document.
+func (s *bookmarkStore) setMark(source, name string, offset int) {
+	if s.Marks == nil {
+		s.Marks = map[string]map[string]int{}
+	}
+	if s.Marks[source] == nil {
+		s.Marks[source] = map[string]int{}
+	}
+	s.Marks[source][name] = offset
+}
+
+// restoreMarks sets the saved lowercase marks of the given document in a view.
+func (s *bookmarkStore) restoreMarks(source string, view *mdk.Model) {
+	for name, offset := range s.Marks[source] {
+		if r := []rune(name); len(r) == 1 {
+			view.SetMarkOffset(r[0], offset)
+		}
+	}
+}
+
+// restoreMarks sets the saved lowercase marks of a tab's document.
+func (r *markdownReader) restoreMarks(t *tab) {
+	if r.bookmarks != nil && t.currentSource != "" {
+		r.bookmarks.restoreMarks(t.currentSource, &t.view)
+	}
+}
+
+// saveBookmarks writes the bookmarks file, reporting any error in a dialog.
+func (r *markdownReader) saveBookmarks() {
+	if err := r.bookmarks.save(); err != nil {
+		r.logger.Error("bookmarks_write_error", "path", r.bookmarks.path, "error", err)
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error saving bookmarks: %v", err)
+	}
+}
+
+// flashStatus shows a status message in the active tab's gutter for a few
+// seconds.
+func (r *markdownReader) flashStatus(text string) tea.Cmd {
+	r.active().view.SetStatusMessage(text)
+	return tea.Tick(3*time.Second, func(time.Time) tea.Msg { return clearStatusMsg{} })
+}
+
+// setMark saves a mark set in the active tab. Uppercase marks become global
+// bookmarks; lowercase marks are saved with their document.
+func (r *markdownReader) setMark(msg mdk.MarkSetMsg) tea.Cmd {
+	at := r.active()
+	if r.bookmarks == nil || at.showSource || at.currentSource == "" {
+		if unicode.IsUpper(msg.Name) {
+			return r.flashStatus("Cannot bookmark this page")
+		}
+		return nil
+	}
+
+	name := string(msg.Name)
+	if !unicode.IsUpper(msg.Name) {
+		r.bookmarks.setMark(at.currentSource, name, msg.Offset)
+		r.saveBookmarks()
+		return nil
+	}
+
+	r.bookmarks.set(bookmark{
+		Name:   name,
+		Source: at.currentSource,
+		Title:  at.displayName(),
+		Offset: msg.Offset,
+	})
+	r.saveBookmarks()
+	return r.flashStatus(fmt.Sprintf("Bookmark %s set", name))
+}
+
+// jumpToMark jumps to the bookmark with the given name.
+func (r *markdownReader) jumpToMark(name rune) tea.Cmd {
+	if r.bookmarks == nil {
+		return nil
+	}
+	b, ok := r.bookmarks.lookup(string(name))
+	if !ok {
+		return r.flashStatus(fmt.Sprintf("Bookmark %c not set", name))
+	}
+	return r.jumpToBookmark(b)
+}
+
+// jumpToBookmark shows the bookmarked position, switching to a tab that shows
+// its document or loading the document into the active tab.
+func (r *markdownReader) jumpToBookmark(b bookmark) tea.Cmd {
+	for i := range r.tabs {
+		if t := &r.tabs[i]; t.currentSource == b.Source && !t.showSource {
+			r.selectTab(i)
+			t.view.GotoSourceOffset(b.Offset)
+			return nil
+		}
+	}
+	cmd := r.loadPage(b.Source, "", false)
+	if cmd != nil {
+		r.pendingJump = &b
+	}
+	return cmd
+}
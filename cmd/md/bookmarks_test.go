@@ -0,0 +1,244 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+const bookmarksPath = "/data/md/bookmarks.json"
+
+// markDoc returns a document long enough to scroll, with the given title.
+func markDoc(title string) string {
+	filler := strings.Repeat("Some text.\n\n", 10)
+	return "# " + title + "\n\n" + filler + "## Middle\n\n" + filler + "## End\n\n" + filler
+}
+
+// markReader returns a sized reader with an empty bookmark store.
+func markReader(t *testing.T, source string) (markdownReader, *memFS) {
+	t.Helper()
+	fs := newMemFS()
+	r := testReader("", markDoc("First"), source)
+	r.fsys = fs
+	store, err := loadBookmarks(bookmarksPath, fs)
+	if err != nil {
+		t.Fatalf("loadBookmarks: %v", err)
+	}
+	r.bookmarks = store
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	return model.(markdownReader), fs
+}
+
+// pressMark sends the key presses of a mark command and delivers the
+// resulting message back to the reader.
+func pressMark(t *testing.T, r markdownReader, keys ...string) (markdownReader, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var model tea.Model
+		model, cmd = r.Update(keyMsg(k))
+		r = model.(markdownReader)
+	}
+	if cmd == nil {
+		return r, nil
+	}
+	model, cmd := r.Update(cmd())
+	return model.(markdownReader), cmd
+}
+
+func TestLoadBookmarks_Missing(t *testing.T) {
+	s, err := loadBookmarks(bookmarksPath, newMemFS())
+	if err != nil {
+		t.Fatalf("loadBookmarks: %v", err)
+	}
+	if len(s.Bookmarks) != 0 || len(s.Marks) != 0 {
+		t.Errorf("expected an empty store, got %+v", s)
+	}
+}
+
+func TestLoadBookmarks_Invalid(t *testing.T) {
+	fs := newMemFS()
+	fs.files[bookmarksPath] = []byte("{not json")
+	if _, err := loadBookmarks(bookmarksPath, fs); err == nil {
+		t.Error("expected an error for an invalid bookmarks file")
+	}
+}
+
+func TestBookmarkStore_SaveAndLoad(t *testing.T) {
+	fs := newMemFS()
+	s, _ := loadBookmarks(bookmarksPath, fs)
+	s.set(bookmark{Name: "B", Source: "/b.md", Title: "B", Offset: 20})
+	s.set(bookmark{Name: "A", Source: "/a.md", Title: "A", Offset: 10})
+	s.set(bookmark{Name: "B", Source: "/c.md", Title: "C", Offset: 30})
+	s.setMark("/a.md", "x", 5)
+	if err := s.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := loadBookmarks(bookmarksPath, fs)
+	if err != nil {
+		t.Fatalf("loadBookmarks: %v", err)
+	}
+	if len(loaded.Bookmarks) != 2 {
+		t.Fatalf("expected 2 bookmarks, got %+v", loaded.Bookmarks)
+	}
+	// Bookmarks are kept in name order, and setting a name replaces it.
+	if loaded.Bookmarks[0].Name != "A" || loaded.Bookmarks[1].Source != "/c.md" {
+		t.Errorf("unexpected bookmarks: %+v", loaded.Bookmarks)
+	}
+	if loaded.Marks["/a.md"]["x"] != 5 {
+		t.Errorf("unexpected marks: %+v", loaded.Marks)
+	}
+
+	loaded.remove("A")
+	if _, ok := loaded.lookup("A"); ok {
+		t.Error("expected bookmark A to be removed")
+	}
+}
+
+func TestUpdate_LowercaseMarkSavedAndRestored(t *testing.T) {
+	r, fs := markReader(t, "/docs/a.md")
+	r.active().view.GotoSourceOffset(strings.Index(markDoc("First"), "Middle"))
+	want := r.active().view.SourceOffset()
+
+	r, _ = pressMark(t, r, "m", "a")
+	if got := r.bookmarks.Marks["/docs/a.md"]["a"]; got != want {
+		t.Errorf("saved offset = %d, want %d", got, want)
+	}
+	if _, ok := fs.files[bookmarksPath]; !ok {
+		t.Error("expected the bookmarks file to be written")
+	}
+
+	// Reloading the document restores its marks.
+	model, _ := r.Update(pageLoadedMsg{markdown: markDoc("First"), source: "/docs/a.md", reload: true})
+	r = model.(markdownReader)
+	if got := r.active().view.MarkOffsets()['a']; got != want {
+		t.Errorf("restored offset = %d, want %d", got, want)
+	}
+}
+
+func TestUpdate_UppercaseMarkKeysGoToView(t *testing.T) {
+	r, _ := markReader(t, "/docs/a.md")
+
+	// "M" opens the user guide, but not while a mark name is pending.
+	r, _ = pressMark(t, r, "m", "M")
+	if len(r.tabs) != 1 {
+		t.Fatalf("expected the mark name not to open the user guide")
+	}
+	b, ok := r.bookmarks.lookup("M")
+	if !ok {
+		t.Fatal("expected bookmark M to be set")
+	}
+	if b.Source != "/docs/a.md" || b.Title != "First" {
+		t.Errorf("unexpected bookmark: %+v", b)
+	}
+}
+
+func TestUpdate_UppercaseMarkWithoutSource(t *testing.T) {
+	r, _ := markReader(t, "")
+	r, _ = pressMark(t, r, "m", "A")
+	if len(r.bookmarks.Bookmarks) != 0 {
+		t.Errorf("expected no bookmarks, got %+v", r.bookmarks.Bookmarks)
+	}
+}
+
+func TestUpdate_JumpToBookmarkLoadsDocument(t *testing.T) {
+	r, fs := markReader(t, "/docs/a.md")
+	other := markDoc("Second")
+	fs.files["/docs/b.md"] = []byte(other)
+	offset := strings.Index(other, "End")
+	r.bookmarks.set(bookmark{Name: "B", Source: "/docs/b.md", Offset: offset})
+
+	model, cmd := r.Update(mdk.JumpToMarkMsg{Name: 'B'})
+	r = model.(markdownReader)
+	if cmd == nil || !r.loading || r.pendingJump == nil {
+		t.Fatal("expected the bookmarked document to be loading")
+	}
+
+	model, _ = r.Update(pageLoadedMsg{markdown: other, source: "/docs/b.md"})
+	r = model.(markdownReader)
+	if r.pendingJump != nil {
+		t.Error("expected the pending jump to be consumed")
+	}
+	if got := r.active().view.SourceOffset(); got != offset {
+		t.Errorf("SourceOffset = %d, want %d", got, offset)
+	}
+	if len(r.active().pageStack) != 1 {
+		t.Errorf("expected the previous page on the back stack")
+	}
+}
+
+func TestUpdate_JumpToBookmarkSwitchesTab(t *testing.T) {
+	r, _ := markReader(t, "/docs/a.md")
+	other := markDoc("Second")
+	r.openNewTab("", other, "/docs/b.md")
+	r.activeTab = 0
+	offset := strings.Index(other, "Middle")
+	r.bookmarks.set(bookmark{Name: "B", Source: "/docs/b.md", Offset: offset})
+
+	model, cmd := r.Update(mdk.JumpToMarkMsg{Name: 'B'})
+	r = model.(markdownReader)
+	if cmd != nil || r.loading {
+		t.Error("expected no page load")
+	}
+	if r.activeTab != 1 {
+		t.Fatalf("activeTab = %d, want 1", r.activeTab)
+	}
+	if got := r.active().view.SourceOffset(); got != offset {
+		t.Errorf("SourceOffset = %d, want %d", got, offset)
+	}
+}
+
+func TestUpdate_BookmarkPicker(t *testing.T) {
+	r, _ := markReader(t, "/docs/a.md")
+	doc := markDoc("First")
+	r.bookmarks.set(bookmark{Name: "A", Source: "/docs/a.md", Title: "First", Offset: strings.Index(doc, "End")})
+	r.bookmarks.set(bookmark{Name: "B", Source: "/docs/b.md", Title: "Second"})
+
+	model, _ := r.Update(keyMsg("B"))
+	r = model.(markdownReader)
+	if !r.showBookmarks {
+		t.Fatal("expected the bookmark picker to be shown")
+	}
+	if !strings.Contains(r.bookmarkPicker.View(), "Second") {
+		t.Error("expected the picker to list bookmarks")
+	}
+
+	// Delete the second bookmark, then jump to the first.
+	model, _ = r.Update(keyMsg("down"))
+	r = model.(markdownReader)
+	model, _ = r.Update(tea.KeyPressMsg{Code: 'd', Mod: tea.ModCtrl})
+	r = model.(markdownReader)
+	if _, ok := r.bookmarks.lookup("B"); ok {
+		t.Error("expected bookmark B to be deleted")
+	}
+	if len(r.bookmarkPicker.filtered) != 1 {
+		t.Errorf("expected 1 remaining entry, got %d", len(r.bookmarkPicker.filtered))
+	}
+
+	model, _ = r.Update(keyMsg("enter"))
+	r = model.(markdownReader)
+	if r.showBookmarks {
+		t.Error("expected the picker to close")
+	}
+	if got := r.active().view.SourceOffset(); got != strings.Index(doc, "End") {
+		t.Errorf("SourceOffset = %d, want %d", got, strings.Index(doc, "End"))
+	}
+}
+
+func TestBookmarkPicker_Filter(t *testing.T) {
+	bp := newBookmarkPicker([]bookmark{
+		{Name: "A", Source: "/docs/alpha.md", Title: "Alpha"},
+		{Name: "B", Source: "/docs/beta.md", Title: "Beta"},
+	}, 20, 60)
+	bp.input.Focus()
+
+	for _, r := range "bet" {
+		bp, _ = bp.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if len(bp.filtered) != 1 || bp.filtered[0].Name != "B" {
+		t.Errorf("unexpected filtered entries: %+v", bp.filtered)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/atotto/clipboard"
+)
+
+// clipboardBackend selects how copied text reaches the clipboard.
+type clipboardBackend int
+
+const (
+	// clipboardAuto uses the system clipboard if one is available and OSC 52
+	// otherwise.
+	clipboardAuto clipboardBackend = iota
+	// clipboardSystem uses the system clipboard through a platform utility
+	// such as pbcopy, xclip or wl-copy.
+	clipboardSystem
+	// clipboardOSC52 asks the terminal to set the clipboard with the OSC 52
+	// escape sequence. This works over SSH and without a display server, as
+	// long as the terminal supports it.
+	clipboardOSC52
+)
+
+func (b clipboardBackend) String() string {
+	switch b {
+	case clipboardSystem:
+		return "system"
+	case clipboardOSC52:
+		return "osc52"
+	}
+	return "auto"
+}
+
+func parseClipboardBackend(s string) (clipboardBackend, error) {
+	switch s {
+	case "", "auto":
+		return clipboardAuto, nil
+	case "system":
+		return clipboardSystem, nil
+	case "osc52":
+		return clipboardOSC52, nil
+	}
+	return 0, fmt.Errorf("clipboard: unknown backend %q (want \"auto\", \"system\", or \"osc52\")", s)
+}
+
+// resolveClipboardBackend resolves clipboardAuto to the system clipboard or
+// OSC 52. The system clipboard is not used if no clipboard utility was found,
+// if md runs in an SSH session, where it would copy to the remote machine, or
+// if there is no display server on a platform that needs one.
+func resolveClipboardBackend(b clipboardBackend, unsupported bool, goos string, getenv func(string) string) clipboardBackend {
+	if b != clipboardAuto {
+		return b
+	}
+	switch {
+	case unsupported:
+		return clipboardOSC52
+	case getenv("SSH_CONNECTION") != "" || getenv("SSH_TTY") != "":
+		return clipboardOSC52
+	case goos != "darwin" && goos != "windows" && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "":
+		return clipboardOSC52
+	}
+	return clipboardSystem
+}
+
+// newClipboardWriter returns a function that writes text to the clipboard with
+// the given backend, resolving clipboardAuto for the current environment.
+func newClipboardWriter(b clipboardBackend, logger *slog.Logger) func(text string) tea.Cmd {
+	b = resolveClipboardBackend(b, clipboard.Unsupported, runtime.GOOS, os.Getenv)
+	logger.Info("clipboard_backend", "backend", b.String())
+	return clipboardWriter(b, logger)
+}
+
+// clipboardWriter returns a function that writes text to the clipboard with
+// the given resolved backend. If the system clipboard fails, the text is sent
+// with OSC 52 instead.
+func clipboardWriter(b clipboardBackend, logger *slog.Logger) func(text string) tea.Cmd {
+	if b == clipboardOSC52 {
+		return func(text string) tea.Cmd {
+			logger.Info("clipboard_write", "backend", b.String(), "length", len(text))
+			return tea.SetClipboard(text)
+		}
+	}
+	return func(text string) tea.Cmd {
+		return func() tea.Msg {
+			logger.Info("clipboard_write", "backend", b.String(), "length", len(text))
+			if err := clipboard.WriteAll(text); err != nil {
+				logger.Error("clipboard_write_error", "error", err)
+				return tea.SetClipboard(text)()
+			}
+			return nil
+		}
+	}
+}
+
+// copyText returns a command that writes text to the clipboard.
+func (r *markdownReader) copyText(text string) tea.Cmd {
+	if r.clipboard != nil {
+		return r.clipboard(text)
+	}
+	return tea.SetClipboard(text)
+}
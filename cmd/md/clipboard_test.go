@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/markdown-kit/styles"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+func TestParseClipboardBackend(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    clipboardBackend
+		wantErr bool
+	}{
+		{"", clipboardAuto, false},
+		{"auto", clipboardAuto, false},
+		{"system", clipboardSystem, false},
+		{"osc52", clipboardOSC52, false},
+		{"xclip", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseClipboardBackend(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseClipboardBackend(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseClipboardBackend(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestResolveClipboardBackend(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	display := map[string]string{"DISPLAY": ":0"}
+
+	tests := []struct {
+		name        string
+		backend     clipboardBackend
+		unsupported bool
+		goos        string
+		env         map[string]string
+		want        clipboardBackend
+	}{
+		{"explicit osc52", clipboardOSC52, false, "linux", display, clipboardOSC52},
+		{"explicit system", clipboardSystem, true, "linux", nil, clipboardSystem},
+		{"local display", clipboardAuto, false, "linux", display, clipboardSystem},
+		{"wayland", clipboardAuto, false, "linux", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, clipboardSystem},
+		{"macos", clipboardAuto, false, "darwin", nil, clipboardSystem},
+		{"no utility", clipboardAuto, true, "linux", display, clipboardOSC52},
+		{"no display", clipboardAuto, false, "linux", nil, clipboardOSC52},
+		{"ssh", clipboardAuto, false, "darwin", map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"}, clipboardOSC52},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveClipboardBackend(tt.backend, tt.unsupported, tt.goos, env(tt.env))
+			if got != tt.want {
+				t.Errorf("resolveClipboardBackend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate_CopyAs(t *testing.T) {
+	var copied string
+	copyText := func(text string) tea.Cmd {
+		copied = text
+		return nil
+	}
+	r := newMarkdownReader(
+		"", "See [the docs](https://docs.example).\n", "/docs/a.md",
+		styles.GlamourDark,
+		[]mdk.Option{mdk.WithClipboard(copyText)},
+		&fakeConverter{},
+		nil,
+		nil,
+		&fakeHTTPClient{},
+		newMemFS(),
+		nil,
+		discardLogger(),
+	)
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+
+	// The format key reaches the view's copy menu rather than the reader.
+	r = press(t, model.(markdownReader), "]", "Y", "m")
+	if r.active().view.CopyMenuActive() {
+		t.Error("expected the copy menu to close")
+	}
+	if copied != "[the docs](https://docs.example)" {
+		t.Errorf("copied = %q", copied)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
@@ -14,6 +16,7 @@ import (
 	"github.com/alecthomas/chroma"
 	chromaStyles "github.com/alecthomas/chroma/styles"
 	"github.com/pgavlin/markdown-kit/docsearch"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/styles"
 )
 
@@ -113,18 +116,130 @@ func (c searchConfig) newEmbedder() docsearch.Embedder {
 }
 
 type config struct {
-	Theme         string                  `toml:"theme"`
-	StripDataURIs *bool                   `toml:"strip_data_uris"`
-	Keys          map[string]any          `toml:"keys"`
-	Converter     converterConfig         `toml:"converter"`
-	Converters    []formatConverterConfig `toml:"converters"`
-	Search        searchConfig            `toml:"search"`
+	Theme          string                  `toml:"theme"`
+	StripDataURIs  *bool                   `toml:"strip_data_uris"`
+	ResumePosition *bool                   `toml:"resume_position"`
+	Mouse          bool                    `toml:"mouse"`
+	Clipboard      string                  `toml:"clipboard"`
+	CheckHTTPLinks bool                    `toml:"check_http_links"`
+	Watch          bool                    `toml:"watch"`
+	Slides         slidesConfig            `toml:"slides"`
+	Run            runConfig               `toml:"run"`
+	Annotations    annotationsConfig       `toml:"annotations"`
+	Keys           map[string]any          `toml:"keys"`
+	Converter      converterConfig         `toml:"converter"`
+	Converters     []formatConverterConfig `toml:"converters"`
+	Search         searchConfig            `toml:"search"`
+	Markdown       markdownConfig          `toml:"markdown"`
+
+	dir string // directory containing the config file; used to resolve relative paths
 }
 
 func (c config) stripDataURIs() bool {
 	return c.StripDataURIs == nil || *c.StripDataURIs
 }
 
+func (c config) resumePosition() bool {
+	return c.ResumePosition == nil || *c.ResumePosition
+}
+
+// slidesConfig configures slide presentations.
+type slidesConfig struct {
+	// Split selects where slides begin: "rule" at thematic breaks, "h1" also
+	// at level 1 headings, and "h2" also at level 2 headings.
+	Split string `toml:"split"`
+	// Incremental reveals lists one item at a time.
+	Incremental bool `toml:"incremental"`
+	// Notes shows the speaker notes pane when a presentation starts.
+	Notes bool `toml:"notes"`
+}
+
+// runConfig configures running code blocks.
+type runConfig struct {
+	// Allow lists the directories whose documents may run their code blocks,
+	// including the directories below them. Running code blocks is off if
+	// the list is empty.
+	Allow []string `toml:"allow"`
+	// Interpreters maps code block languages to the commands that run them,
+	// adding to and overriding the defaults. An empty command stops a
+	// language from running.
+	Interpreters map[string][]string `toml:"interpreters"`
+}
+
+// allowedDirs returns the absolute paths of the directories that may run code
+// blocks. A leading "~" is expanded to the user's home directory, and other
+// relative paths are resolved against dir.
+func (c runConfig) allowedDirs(dir string) []string {
+	var dirs []string
+	for _, d := range c.Allow {
+		if d == "~" || strings.HasPrefix(d, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+			d = filepath.Join(home, d[1:])
+		}
+		if !filepath.IsAbs(d) && dir != "" {
+			d = filepath.Join(dir, d)
+		}
+		if abs, err := filepath.Abs(d); err == nil {
+			dirs = append(dirs, abs)
+		}
+	}
+	return dirs
+}
+
+// interpreters returns the default interpreters merged with the configured
+// ones.
+func (c runConfig) interpreters() map[string][]string {
+	interpreters := make(map[string][]string, len(defaultInterpreters)+len(c.Interpreters))
+	for lang, command := range defaultInterpreters {
+		interpreters[lang] = command
+	}
+	for lang, command := range c.Interpreters {
+		lang = strings.ToLower(lang)
+		if len(command) == 0 {
+			delete(interpreters, lang)
+			continue
+		}
+		interpreters[lang] = command
+	}
+	return interpreters
+}
+
+// markdownConfig configures how documents are parsed. The same parser is used
+// to display documents, to index them for search, and to check their links.
+type markdownConfig struct {
+	// FrontMatter parses a YAML block delimited by "---" lines at the start
+	// of a document as front matter, which is not displayed.
+	FrontMatter bool `toml:"front_matter"`
+	// Typographer substitutes curly quotes for straight quotes and dashes
+	// for "--" and "---".
+	Typographer bool `toml:"typographer"`
+	// Attributes parses heading attributes, e.g. "# Title {#id}".
+	Attributes bool `toml:"attributes"`
+}
+
+// newParser returns a parser with the configured options.
+func (c markdownConfig) newParser() *markdown.Parser {
+	return markdown.NewParser(
+		markdown.WithFrontMatter(c.FrontMatter),
+		markdown.WithTypographer(c.Typographer),
+		markdown.WithAttributes(c.Attributes),
+	)
+}
+
+// annotationsConfig configures where annotations are saved.
+type annotationsConfig struct {
+	// Sidecars saves the annotations of local files next to them. If false,
+	// all annotations are saved in the data directory.
+	Sidecars *bool `toml:"sidecars"`
+}
+
+func (c annotationsConfig) sidecars() bool {
+	return c.Sidecars == nil || *c.Sidecars
+}
+
 func configPath() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
@@ -153,6 +268,7 @@ func loadConfig(path string, fsys fileSystem, logger *slog.Logger) (config, erro
 		logger.Error("config_load_error", "path", path, "error", err)
 		return config{}, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	cfg.dir = filepath.Dir(path)
 	logger.Info("config_loaded", "path", path)
 	return cfg, nil
 }
@@ -160,7 +276,10 @@ func loadConfig(path string, fsys fileSystem, logger *slog.Logger) (config, erro
 const defaultConfig = `# md configuration file
 # See https://github.com/pgavlin/markdown-kit for documentation.
 
-# Color theme (any Chroma style name, e.g. "monokai", "dracula").
+# Color theme (any Chroma style name, e.g. "monokai", "dracula"), or the path
+# to a theme file: a glamour style (.json), a native theme (.toml), or a base16
+# scheme (.yaml/.yml). Relative paths are resolved against this directory.
+# Use "md theme preview <theme>" to try a theme out.
 # Defaults to a built-in dark theme when empty.
 # theme = ""
 
@@ -168,6 +287,63 @@ const defaultConfig = `# md configuration file
 # Enabled by default. Set to false to preserve them.
 # strip_data_uris = true
 
+# Reopen documents at the position where you left off. Positions are stored
+# in the document search index. Enabled by default. Set to false to always
+# start at the top.
+# resume_position = true
+
+# Scroll with the mouse wheel, click links, tabs and picker entries, and
+# drag to select text. Disabled by default, because capturing the mouse
+# disables the terminal's own text selection.
+# mouse = false
+
+# How copied text reaches the clipboard: "system" uses the system clipboard,
+# "osc52" asks the terminal to set it (this works over SSH), and "auto" uses
+# the system clipboard when one is available and OSC 52 otherwise.
+# clipboard = "auto"
+
+# Check HTTP links as well as local ones when checking a document's links.
+# Each link is requested from its server, so this is disabled by default.
+# check_http_links = false
+
+# Reload the current file whenever it changes on disk. Disabled by default.
+# watch = false
+
+# Slides start at thematic breaks ("rule"), and also at level 1 ("h1") or
+# level 1 and 2 ("h2") headings. Set incremental to reveal lists one item at a
+# time, and notes to show the speaker notes when a presentation starts.
+# [slides]
+# split = "rule"
+# incremental = false
+# notes = false
+
+# Code blocks run with the interpreter of their language after a confirmation
+# prompt, in the directory of their document. Running code blocks is disabled
+# unless the document is in one of the allowed directories (or below them).
+# Interpreters are added to the defaults: sh, shell, bash, zsh, python, py.
+# [run]
+# allow = ["~/notebooks"]
+#
+# [run.interpreters]
+# node = ["node"]
+# ruby = ["ruby", "-w"]
+
+# Annotations of local files are saved next to them, in a sidecar file named
+# after the document (e.g. design.md.annotations.json). Set sidecars to false
+# to save all annotations in the data directory instead.
+# [annotations]
+# sidecars = true
+
+# Markdown parsing. Documents are displayed, indexed and link-checked with
+# the same parser. Tables and strikethrough are always enabled. Set
+# front_matter to hide YAML front matter delimited by "---" lines at the start
+# of a document, typographer to use curly quotes and dashes, and attributes
+# to parse heading attributes such as "# Title {#id}".
+# [markdown]
+# front_matter = false
+# typographer = false
+# attributes = false
+
 # Content converter for HTML-to-Markdown when opening URLs.
 # [converter]
 # command = "pandoc -f html -t markdown"  # shell command to convert HTML to Markdown
@@ -212,66 +388,189 @@ func createDefaultConfig(path string, fsys fileSystem) error {
 	return fsys.WriteFile(path, []byte(defaultConfig), 0o644)
 }
 
-func (c config) theme() *chroma.Style {
+// theme returns the configured theme. Theme files are loaded from fsys; an
+// error is returned if a theme file cannot be read or is invalid. Unknown theme
+// names fall back to the automatic theme.
+func (c config) theme(fsys fileSystem) (*chroma.Style, error) {
 	if c.Theme == "" || c.Theme == "auto" {
-		return styles.AutoTheme()
+		return styles.AutoTheme(), nil
+	}
+	if styles.IsThemeFile(c.Theme) {
+		return loadThemeFile(c.Theme, c.dir, fsys)
 	}
 	s := chromaStyles.Get(c.Theme)
 	if s == chromaStyles.Fallback {
-		return styles.AutoTheme()
+		return styles.AutoTheme(), nil
+	}
+	return s, nil
+}
+
+// loadThemeFile reads and parses a theme file. A leading "~/" is expanded to the
+// user's home directory, and other relative paths are resolved against dir.
+func loadThemeFile(path, dir string, fsys fileSystem) (*chroma.Style, error) {
+	path, data, err := readThemeFile(path, dir, fsys)
+	if err != nil {
+		return nil, err
+	}
+	return styles.ParseThemeFile(path, data)
+}
+
+// readThemeFile resolves the path of a theme file as loadThemeFile does and
+// reads it, returning the resolved path and the file's contents.
+func readThemeFile(path, dir string, fsys fileSystem) (string, []byte, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
 	}
-	return s
+	if !filepath.IsAbs(path) && dir != "" {
+		path = filepath.Join(dir, path)
+	}
+
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading theme: %w", err)
+	}
+	return path, data, nil
+}
+
+// reThemeSetting matches a theme setting line, whether or not it is commented out.
+var reThemeSetting = regexp.MustCompile(`(?m)^(#\s*)?theme\s*=.*$`)
+
+// reTableHeader matches the first table header in a config file. Top-level
+// settings must appear before it.
+var reTableHeader = regexp.MustCompile(`(?m)^\s*\[`)
+
+// saveThemeSetting writes the theme setting to the config file at path,
+// preserving the rest of the file. An existing theme setting is replaced; if
+// there is none, a commented-out setting is replaced, and otherwise the setting
+// is inserted before the first table. The default config is written first if
+// the file does not exist.
+func saveThemeSetting(path, theme string, fsys fileSystem) error {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err := fsys.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		data = []byte(defaultConfig)
+	}
+
+	text := string(data)
+	topLevel := len(text)
+	if loc := reTableHeader.FindStringIndex(text); loc != nil {
+		topLevel = loc[0]
+	}
+
+	setting := "theme = " + strconv.Quote(theme)
+	var start, end int
+	found := false
+	for _, loc := range reThemeSetting.FindAllStringSubmatchIndex(text[:topLevel], -1) {
+		commented := loc[2] != -1
+		if !found || !commented {
+			start, end, found = loc[0], loc[1], true
+		}
+		if !commented {
+			break
+		}
+	}
+	if !found {
+		start, end = topLevel, topLevel
+		setting += "\n"
+		if topLevel != 0 && text[topLevel-1] != '\n' {
+			setting = "\n" + setting
+		}
+	}
+
+	text = text[:start] + setting + text[end:]
+	return fsys.WriteFile(path, []byte(text), 0o644)
 }
 
 func (c config) applyKeys(km *readerKeyMap) {
 	// Map snake_case config names to binding pointers.
 	nameToBinding := map[string]*key.Binding{
 		// View keys
-		"up":              &km.Up,
-		"down":            &km.Down,
-		"page_up":         &km.PageUp,
-		"page_down":       &km.PageDown,
-		"goto_top":        &km.GotoTop,
-		"goto_end":        &km.GotoEnd,
-		"home":            &km.Home,
-		"end":             &km.End,
-		"left":            &km.Left,
-		"right":           &km.Right,
-		"next_link":       &km.NextLink,
-		"prev_link":       &km.PrevLink,
-		"next_code_block": &km.NextCodeBlock,
-		"prev_code_block": &km.PrevCodeBlock,
-		"next_heading":    &km.NextHeading,
-		"prev_heading":    &km.PrevHeading,
-		"decrease_width":  &km.DecreaseWidth,
-		"increase_width":  &km.IncreaseWidth,
-		"follow_link":     &km.FollowLink,
-		"go_back":         &km.GoBack,
-		"copy_selection":  &km.CopySelection,
-		"search":          &km.Search,
-		"next_match":      &km.NextMatch,
-		"prev_match":      &km.PrevMatch,
-		"clear_search":    &km.ClearSearch,
+		"up":                &km.Up,
+		"down":              &km.Down,
+		"page_up":           &km.PageUp,
+		"page_down":         &km.PageDown,
+		"goto_top":          &km.GotoTop,
+		"goto_end":          &km.GotoEnd,
+		"home":              &km.Home,
+		"end":               &km.End,
+		"left":              &km.Left,
+		"right":             &km.Right,
+		"next_link":         &km.NextLink,
+		"prev_link":         &km.PrevLink,
+		"next_code_block":   &km.NextCodeBlock,
+		"prev_code_block":   &km.PrevCodeBlock,
+		"next_heading":      &km.NextHeading,
+		"prev_heading":      &km.PrevHeading,
+		"decrease_width":    &km.DecreaseWidth,
+		"increase_width":    &km.IncreaseWidth,
+		"follow_link":       &km.FollowLink,
+		"go_back":           &km.GoBack,
+		"copy_selection":    &km.CopySelection,
+		"copy_as":           &km.CopyAs,
+		"search":            &km.Search,
+		"next_match":        &km.NextMatch,
+		"prev_match":        &km.PrevMatch,
+		"clear_search":      &km.ClearSearch,
+		"toggle_toc":        &km.ToggleTOC,
+		"focus_toc":         &km.FocusTOC,
+		"toc_select":        &km.TOCSelect,
+		"toc_expand":        &km.TOCExpand,
+		"toc_collapse":      &km.TOCCollapse,
+		"toggle_fold":       &km.ToggleFold,
+		"fold":              &km.Fold,
+		"unfold":            &km.Unfold,
+		"toggle_all_folds":  &km.ToggleAllFolds,
+		"fold_level":        &km.FoldLevel,
+		"set_mark":          &km.SetMark,
+		"jump_to_mark":      &km.JumpToMark,
+		"hint":              &km.Hint,
+		"annotate":          &km.Annotate,
+		"next_annotation":   &km.NextAnnotation,
+		"prev_annotation":   &km.PrevAnnotation,
+		"delete_annotation": &km.DeleteAnnotation,
 		// Reader keys
-		"toggle_source":     &km.ToggleSource,
-		"toggle_raw":        &km.ToggleSource, // backwards compat
-		"open_url":          &km.OpenURL,
-		"open_browser":      &km.OpenBrowser,
-		"open_file_new_tab": &km.OpenFileNewTab,
-		"next_tab":          &km.NextTab,
-		"prev_tab":          &km.PrevTab,
-		"close_tab":         &km.CloseTab,
-		"close_all_tabs":    &km.CloseAllTabs,
-		"new_tab":           &km.NewTab,
-		"reload":            &km.Reload,
-		"history":           &km.History,
-		"search_documents":  &km.SearchDocuments,
-		"find_similar":      &km.FindSimilar,
-		"user_guide":        &km.UserGuide,
-		"bug_report":        &km.BugReport,
-		"export_gist":       &km.ExportGist,
-		"help":              &km.Help,
-		"quit":              &km.Quit,
+		"toggle_source":      &km.ToggleSource,
+		"toggle_raw":         &km.ToggleSource, // backwards compat
+		"open_url":           &km.OpenURL,
+		"open_browser":       &km.OpenBrowser,
+		"open_file_new_tab":  &km.OpenFileNewTab,
+		"next_tab":           &km.NextTab,
+		"prev_tab":           &km.PrevTab,
+		"close_tab":          &km.CloseTab,
+		"close_all_tabs":     &km.CloseAllTabs,
+		"new_tab":            &km.NewTab,
+		"peek":               &km.Peek,
+		"check_links":        &km.CheckLinks,
+		"reload":             &km.Reload,
+		"goto_heading":       &km.GotoHeading,
+		"search_tabs":        &km.SearchTabs,
+		"split_vertical":     &km.SplitVertical,
+		"split_horizontal":   &km.SplitHorizontal,
+		"source_sync":        &km.SourceSync,
+		"watch":              &km.Watch,
+		"present":            &km.Present,
+		"run_code":           &km.RunCode,
+		"kill_code":          &km.KillCode,
+		"save_code":          &km.SaveCode,
+		"switch_pane":        &km.SwitchPane,
+		"history":            &km.History,
+		"search_documents":   &km.SearchDocuments,
+		"find_similar":       &km.FindSimilar,
+		"user_guide":         &km.UserGuide,
+		"bug_report":         &km.BugReport,
+		"export_gist":        &km.ExportGist,
+		"export_annotations": &km.ExportAnnotations,
+		"themes":             &km.Themes,
+		"bookmarks":          &km.Bookmarks,
+		"help":               &km.Help,
+		"quit":               &km.Quit,
 	}
 
 	for name, val := range c.Keys {
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/styles"
 )
 
@@ -91,7 +94,10 @@ func TestConfig_Theme(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := config{Theme: tt.theme}
-			got := cfg.theme()
+			got, err := cfg.theme(newMemFS())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if got.Name != tt.wantStyle {
 				t.Errorf("theme().Name = %q, want %q", got.Name, tt.wantStyle)
 			}
@@ -99,6 +105,152 @@ func TestConfig_Theme(t *testing.T) {
 	}
 }
 
+func TestConfig_ThemeFile(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/config/md/themes/custom.toml"] = []byte(`name = "config-test-custom"
+
+[styles]
+GenericHeading = "#ff0000 bold"
+`)
+	fs.files["/config/md/bad.toml"] = []byte(`[styles]
+Text = "#ffffff"
+Nope = "bold"
+`)
+
+	t.Run("relative_to_config", func(t *testing.T) {
+		cfg := config{Theme: "themes/custom.toml", dir: "/config/md"}
+		got, err := cfg.theme(fs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Name != "config-test-custom" {
+			t.Errorf("theme().Name = %q, want %q", got.Name, "config-test-custom")
+		}
+	})
+
+	t.Run("absolute", func(t *testing.T) {
+		cfg := config{Theme: "/config/md/themes/custom.toml", dir: "/elsewhere"}
+		if _, err := cfg.theme(fs); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		cfg := config{Theme: "missing.yaml", dir: "/config/md"}
+		if _, err := cfg.theme(fs); err == nil {
+			t.Fatal("expected error for missing theme file")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cfg := config{Theme: "bad.toml", dir: "/config/md"}
+		_, err := cfg.theme(fs)
+		if err == nil {
+			t.Fatal("expected error for invalid theme file")
+		}
+		if !strings.Contains(err.Error(), "/config/md/bad.toml:3:") {
+			t.Errorf("error = %q, want line number", err)
+		}
+	})
+}
+
+func TestLoadConfig_SetsDir(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/config/md/config.toml"] = []byte(`theme = "custom.toml"`)
+	cfg, err := loadConfig("/config/md/config.toml", fs, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.dir != "/config/md" {
+		t.Errorf("dir = %q, want %q", cfg.dir, "/config/md")
+	}
+}
+
+func TestResolvePreviewTheme(t *testing.T) {
+	if _, err := resolvePreviewTheme("monokai", newMemFS()); err != nil {
+		t.Errorf("unexpected error for built-in theme: %v", err)
+	}
+	if _, err := resolvePreviewTheme("nonexistent-theme-xyz", newMemFS()); err == nil {
+		t.Error("expected error for unknown theme name")
+	}
+}
+
+func TestRenderThemePreview(t *testing.T) {
+	var buf strings.Builder
+	if err := renderThemePreview(&buf, styles.Pulumi, 80); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := ansi.Strip(buf.String())
+	for _, want := range []string{"Heading 1", "A bullet list", "TableHeader", "fmt.Printf"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("preview does not contain %q", want)
+		}
+	}
+}
+
+func TestSaveThemeSetting(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		want   string
+	}{
+		{
+			name:   "replaces_setting",
+			before: "# comment\ntheme = \"monokai\"\n\n[converter]\ncommand = \"x\"\n",
+			want:   "# comment\ntheme = \"pulumi\"\n\n[converter]\ncommand = \"x\"\n",
+		},
+		{
+			name:   "replaces_commented_setting",
+			before: "# theme = \"\"\n# strip_data_uris = true\n",
+			want:   "theme = \"pulumi\"\n# strip_data_uris = true\n",
+		},
+		{
+			name:   "prefers_active_setting",
+			before: "# theme = \"\"\ntheme = \"monokai\"\n",
+			want:   "# theme = \"\"\ntheme = \"pulumi\"\n",
+		},
+		{
+			name:   "inserts_before_tables",
+			before: "strip_data_uris = false\n[search]\ntheme = \"not-top-level\"\n",
+			want:   "strip_data_uris = false\ntheme = \"pulumi\"\n[search]\ntheme = \"not-top-level\"\n",
+		},
+		{
+			name:   "appends",
+			before: "strip_data_uris = false",
+			want:   "strip_data_uris = false\ntheme = \"pulumi\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newMemFS()
+			fs.files["/config.toml"] = []byte(tt.before)
+			if err := saveThemeSetting("/config.toml", "pulumi", fs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := string(fs.files["/config.toml"]); got != tt.want {
+				t.Errorf("config = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveThemeSetting_CreatesDefault(t *testing.T) {
+	fs := newMemFS()
+	if err := saveThemeSetting("/config/md/config.toml", "themes/mine.toml", fs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := loadConfig("/config/md/config.toml", fs, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "themes/mine.toml" {
+		t.Errorf("Theme = %q, want %q", cfg.Theme, "themes/mine.toml")
+	}
+	if !strings.Contains(string(fs.files["/config/md/config.toml"]), "# md configuration file") {
+		t.Error("expected the default config to be preserved")
+	}
+}
+
 func TestConfig_ApplyKeys(t *testing.T) {
 	t.Run("override", func(t *testing.T) {
 		km := defaultReaderKeyMap()
@@ -209,3 +361,45 @@ func TestLoadConfig_PartialConfig(t *testing.T) {
 		t.Errorf("Converter.Command = %q, want empty", cfg.Converter.Command)
 	}
 }
+
+func TestConfig_ResumePosition(t *testing.T) {
+	var cfg config
+	if !cfg.resumePosition() {
+		t.Error("expected reading positions to be resumed by default")
+	}
+
+	fs := newMemFS()
+	fs.files["/config.toml"] = []byte(`resume_position = false`)
+	cfg, err := loadConfig("/config.toml", fs, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.resumePosition() {
+		t.Error("expected resume_position = false to disable reading positions")
+	}
+}
+
+func TestConfig_Markdown(t *testing.T) {
+	source := []byte("---\ntitle: Notes\n---\n\n# Heading {#custom}\n")
+
+	var cfg config
+	doc := cfg.Markdown.newParser().Parse(source)
+	if markdown.GetFrontMatter(doc) != nil {
+		t.Error("expected front matter to be disabled by default")
+	}
+
+	fs := newMemFS()
+	fs.files["/config.toml"] = []byte("[markdown]\nfront_matter = true\nattributes = true\n")
+	cfg, err := loadConfig("/config.toml", fs, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc = cfg.Markdown.newParser().Parse(source)
+	fm := markdown.GetFrontMatter(doc)
+	if fm == nil {
+		t.Fatal("expected front_matter = true to parse front matter")
+	}
+	if _, ok := fm.NextSibling().AttributeString("id"); !ok {
+		t.Error("expected attributes = true to parse heading attributes")
+	}
+}
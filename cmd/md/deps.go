@@ -14,6 +14,7 @@ type fileSystem interface {
 	Getwd() (string, error)
 	ReadDir(name string) ([]os.DirEntry, error)
 	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
 	WriteFile(name string, data []byte, perm fs.FileMode) error
 	MkdirAll(path string, perm fs.FileMode) error
 }
@@ -35,6 +36,7 @@ type osFileSystem struct{}
 func (osFileSystem) Getwd() (string, error)                     { return os.Getwd() }
 func (osFileSystem) ReadDir(name string) ([]os.DirEntry, error) { return os.ReadDir(name) }
 func (osFileSystem) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (osFileSystem) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
 func (osFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
 	return os.WriteFile(name, data, perm)
 }
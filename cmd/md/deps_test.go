@@ -35,6 +35,14 @@ func (m *memFS) ReadFile(name string) ([]byte, error) {
 	return append([]byte(nil), data...), nil
 }
 
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return &memFileInfo{name: name, size: int64(len(data))}, nil
+}
+
 func (m *memFS) WriteFile(name string, data []byte, _ fs.FileMode) error {
 	m.files[name] = append([]byte(nil), data...)
 	return nil
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+)
+
+// headingPreviewLines is the number of lines of section text shown below the
+// heading list.
+const headingPreviewLines = 3
+
+// headingEntry is a heading or HTML anchor listed in the heading picker.
+type headingEntry struct {
+	title   string
+	level   int              // heading level, or 0 for an HTML anchor
+	anchor  string           // heading or HTML anchor ID
+	section *indexer.Section // nil for an HTML anchor
+	tab     int              // index of the tab that shows the document
+	tabName string
+	preview []string // the first lines of text after the heading
+}
+
+// headingPicker is a list selector with fuzzy filtering for jumping to a
+// heading. ctrl+a toggles between the active tab's headings and the headings
+// of every open tab.
+type headingPicker struct {
+	input     textinput.Model
+	all       []headingEntry // full list, in tab and document order
+	filtered  []headingEntry // after tab and text filter
+	activeTab int
+	allTabs   bool
+	cursor    int
+	minIdx    int
+	maxIdx    int
+	height    int
+	width     int
+	selected  bool
+	dismissed bool
+}
+
+// Style constants for the heading picker.
+var (
+	hdAnchorStyle  = lipgloss.NewStyle().Foreground(colorMuted)
+	hdPreviewStyle = lipgloss.NewStyle().Foreground(colorMuted).Italic(true)
+)
+
+// headingEntries lists the headings and HTML anchors of a tab's document in
+// document order.
+func headingEntries(t *tab, tabIdx int) []headingEntry {
+	index := t.view.Index()
+	if index == nil {
+		return nil
+	}
+	source := t.view.GetMarkdown()
+	tabName := t.displayName()
+
+	type located struct {
+		entry  headingEntry
+		offset int
+	}
+	var found []located
+
+	var walk func(s *indexer.Section)
+	walk = func(s *indexer.Section) {
+		for _, sub := range s.Subsections {
+			if h, ok := sub.Start.(*ast.Heading); ok {
+				offset := -1
+				if h.Lines().Len() > 0 {
+					offset = h.Lines().At(0).Start
+				}
+				found = append(found, located{
+					entry: headingEntry{
+						title:   string(h.Text(source)),
+						level:   sub.Level,
+						anchor:  sub.Anchor,
+						section: sub,
+						tab:     tabIdx,
+						tabName: tabName,
+						preview: sourcePreview(source, offset),
+					},
+					offset: offset,
+				})
+			}
+			walk(sub)
+		}
+	}
+	walk(index.TableOfContents())
+
+	for _, id := range index.AnchorIDs() {
+		nodes, _ := index.LookupNode(id)
+		offset := -1
+		if raw, ok := nodes[0].(*ast.RawHTML); ok && raw.Segments.Len() > 0 {
+			offset = raw.Segments.At(0).Start
+		}
+		found = append(found, located{
+			entry: headingEntry{
+				title:   "#" + id,
+				anchor:  id,
+				tab:     tabIdx,
+				tabName: tabName,
+				preview: sourcePreview(source, offset),
+			},
+			offset: offset,
+		})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].offset < found[j].offset })
+	entries := make([]headingEntry, len(found))
+	for i, f := range found {
+		entries[i] = f.entry
+	}
+	return entries
+}
+
+// sourcePreview returns the first lines of text in source that follow the
+// line containing offset, stopping at the next heading. Blank lines and lines
+// of raw HTML, such as anchors, are skipped.
+func sourcePreview(source []byte, offset int) []string {
+	if offset < 0 || offset >= len(source) {
+		return nil
+	}
+	rest := string(source[offset:])
+	if nl := strings.IndexByte(rest, '\n'); nl >= 0 {
+		rest = rest[nl+1:]
+	} else {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(rest, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || len(lines) == headingPreviewLines {
+			break
+		}
+		if line != "" && !(strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">")) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// newHeadingPicker creates a heading picker. Entries from tabs other than the
+// active tab are shown once the picker is switched to all tabs.
+func newHeadingPicker(entries []headingEntry, activeTab, height, width int) headingPicker {
+	ti := textinput.New()
+	ti.Prompt = "  Filter: "
+	ti.Placeholder = "type to filter, ctrl+a for all tabs..."
+	innerW := width - 4 // account for border + padding
+	ti.SetWidth(innerW - lipgloss.Width(ti.Prompt) - 1)
+
+	listHeight := height - 1 - (headingPreviewLines + 1) // subtract input and preview
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	hp := headingPicker{
+		input:     ti,
+		all:       entries,
+		activeTab: activeTab,
+		height:    listHeight,
+		width:     width,
+	}
+	hp.filter()
+	return hp
+}
+
+func (hp headingPicker) Update(msg tea.Msg) (headingPicker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			hp.dismissed = true
+			return hp, nil
+
+		case "enter":
+			if len(hp.filtered) == 0 || hp.cursor < 0 {
+				return hp, nil
+			}
+			hp.selected = true
+			return hp, nil
+
+		case "ctrl+a":
+			hp.allTabs = !hp.allTabs
+			hp.cursor = 0
+			hp.filter()
+			return hp, nil
+
+		case "up", "ctrl+p":
+			hp.cursor--
+			if hp.cursor < 0 {
+				hp.cursor = 0
+			}
+			if hp.cursor < hp.minIdx {
+				hp.minIdx = hp.cursor
+				hp.maxIdx = hp.minIdx + hp.height - 1
+			}
+			return hp, nil
+
+		case "down", "ctrl+n":
+			hp.cursor++
+			if hp.cursor >= len(hp.filtered) {
+				hp.cursor = len(hp.filtered) - 1
+			}
+			if hp.cursor < 0 {
+				hp.cursor = 0
+			}
+			if hp.cursor > hp.maxIdx {
+				hp.maxIdx = hp.cursor
+				hp.minIdx = hp.maxIdx - hp.height + 1
+			}
+			return hp, nil
+
+		default:
+			prevValue := hp.input.Value()
+			var cmd tea.Cmd
+			hp.input, cmd = hp.input.Update(msg)
+			if hp.input.Value() != prevValue {
+				hp.cursor = 0
+				hp.filter()
+			}
+			return hp, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	hp.input, cmd = hp.input.Update(msg)
+	return hp, cmd
+}
+
+// filter applies case-insensitive fuzzy matching on entry titles. Without a
+// query, entries are listed in document order; with one, the best matches are
+// listed first.
+func (hp *headingPicker) filter() {
+	query := strings.ToLower(hp.input.Value())
+
+	type scored struct {
+		entry headingEntry
+		score int
+	}
+	var matches []scored
+	for _, e := range hp.all {
+		if !hp.allTabs && e.tab != hp.activeTab {
+			continue
+		}
+		score, ok := subsequenceScore(strings.ToLower(e.title), query)
+		if ok {
+			matches = append(matches, scored{entry: e, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	hp.filtered = make([]headingEntry, len(matches))
+	for i, m := range matches {
+		hp.filtered[i] = m.entry
+	}
+
+	if hp.cursor >= len(hp.filtered) {
+		hp.cursor = max(0, len(hp.filtered)-1)
+	}
+	hp.minIdx = 0
+	hp.maxIdx = hp.height - 1
+	if hp.cursor > hp.maxIdx {
+		hp.minIdx = hp.cursor - hp.height + 1
+		hp.maxIdx = hp.cursor
+	}
+}
+
+func (hp headingPicker) View() string {
+	var s strings.Builder
+
+	s.WriteString(hp.input.View())
+	s.WriteRune('\n')
+
+	if len(hp.filtered) == 0 {
+		s.WriteString(hpEmptyStyle.Render("  No matching headings."))
+		s.WriteRune('\n')
+	} else {
+		for i, e := range hp.filtered {
+			if i < hp.minIdx || i > hp.maxIdx {
+				continue
+			}
+
+			indent := strings.Repeat("  ", max(e.level-1, 0))
+			title := e.title
+			if title == "" {
+				title = "(untitled)"
+			}
+
+			// Show the document name when listing every tab.
+			var tabName string
+			if hp.allTabs {
+				tabName = e.tabName
+				maxW := hp.width - ansi.StringWidth(indent+title) - 6 // cursor + spaces + padding
+				if maxW > 3 {
+					tabName = ansi.Truncate(tabName, maxW, "...")
+				}
+			}
+
+			if i == hp.cursor {
+				line := " " + indent + title
+				if tabName != "" {
+					line += "  " + tabName
+				}
+				s.WriteString(hpCursorStyle.Render(">") + hpSelectedStyle.Render(line))
+			} else {
+				s.WriteString(hpCursorStyle.Render(" "))
+				if e.section == nil {
+					s.WriteString(" " + indent + hdAnchorStyle.Render(title))
+				} else {
+					s.WriteString(" " + indent + hpNameStyle.Render(title))
+				}
+				if tabName != "" {
+					s.WriteString("  " + hpSourceStyle.Render(tabName))
+				}
+			}
+			s.WriteRune('\n')
+		}
+	}
+
+	// Pad remaining list height.
+	rendered := lipgloss.Height(s.String())
+	for i := rendered; i <= hp.height+1; i++ {
+		s.WriteRune('\n')
+	}
+
+	// Preview the section under the cursor.
+	if hp.cursor >= 0 && hp.cursor < len(hp.filtered) {
+		for _, line := range hp.filtered[hp.cursor].preview {
+			s.WriteString("  " + hdPreviewStyle.Render(ansi.Truncate(line, hp.width-6, "...")))
+			s.WriteRune('\n')
+		}
+	}
+
+	return s.String()
+}
+
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (hp *headingPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 1, hp.minIdx, hp.maxIdx, len(hp.filtered))
+	if ok {
+		hp.cursor = i
+	}
+	return ok
+}
+
+// DidSelect returns whether a heading was chosen and, if so, its entry.
+func (hp headingPicker) DidSelect() (bool, headingEntry) {
+	if !hp.selected || hp.cursor < 0 || hp.cursor >= len(hp.filtered) {
+		return false, headingEntry{}
+	}
+	return true, hp.filtered[hp.cursor]
+}
+
+// gotoHeading switches to the tab of a heading picker entry and selects its
+// heading or anchor.
+func (r *markdownReader) gotoHeading(e headingEntry) {
+	if e.tab < 0 || e.tab >= len(r.tabs) {
+		return
+	}
+	r.selectTab(e.tab)
+	at := r.active()
+	if e.section != nil {
+		at.view.SelectSection(e.section)
+	} else {
+		at.view.SelectAnchor(e.anchor)
+	}
+}
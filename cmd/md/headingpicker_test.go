@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const headingDoc = "# Getting Started\n\nWelcome to the guide.\n\n## Install\n\nRun the installer.\n\n<a id=\"legacy-setup\"></a>\n\n## Settings\n\nEdit the config.\n"
+
+func headingTitles(entries []headingEntry) []string {
+	titles := make([]string, len(entries))
+	for i, e := range entries {
+		titles[i] = e.title
+	}
+	return titles
+}
+
+func typeFilter(hp headingPicker, query string) headingPicker {
+	for _, r := range query {
+		hp, _ = hp.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return hp
+}
+
+func TestSubsequenceScore(t *testing.T) {
+	if _, ok := subsequenceScore("settings", "xs"); ok {
+		t.Error("expected no match")
+	}
+	gs, _ := subsequenceScore("getting started", "gs")
+	settings, _ := subsequenceScore("settings", "gs")
+	if gs <= settings {
+		t.Errorf("word starts should score higher: %d <= %d", gs, settings)
+	}
+}
+
+func TestHeadingEntries(t *testing.T) {
+	r := testReader("", headingDoc, "/docs/guide.md")
+	entries := headingEntries(r.active(), 0)
+
+	want := []string{"Getting Started", "Install", "#legacy-setup", "Settings"}
+	if got := headingTitles(entries); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("titles = %q, want %q", got, want)
+	}
+	if entries[1].level != 2 || entries[2].level != 0 {
+		t.Errorf("unexpected levels: %d, %d", entries[1].level, entries[2].level)
+	}
+	if len(entries[1].preview) != 1 || entries[1].preview[0] != "Run the installer." {
+		t.Errorf("preview = %q", entries[1].preview)
+	}
+}
+
+func TestHeadingPicker_Filter(t *testing.T) {
+	r := testReader("", headingDoc, "/docs/guide.md")
+	hp := newHeadingPicker(headingEntries(r.active(), 0), 0, 20, 60)
+	hp.input.Focus()
+
+	// Headings whose words start with the query rank first.
+	hp = typeFilter(hp, "gs")
+	want := []string{"Getting Started", "#legacy-setup", "Settings"}
+	if got := headingTitles(hp.filtered); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("filtered = %q, want %q", got, want)
+	}
+	if !strings.Contains(hp.View(), "Welcome to the guide.") {
+		t.Error("expected a preview of the highlighted section")
+	}
+}
+
+func TestHeadingPicker_AllTabs(t *testing.T) {
+	r := testReader("", headingDoc, "/docs/guide.md")
+	r.openNewTab("", "# Other\n\n## Settings\n", "/docs/other.md")
+	entries := append(headingEntries(&r.tabs[0], 0), headingEntries(&r.tabs[1], 1)...)
+
+	hp := newHeadingPicker(entries, 0, 20, 60)
+	if len(hp.filtered) != 4 {
+		t.Fatalf("expected the active tab's 4 entries, got %q", headingTitles(hp.filtered))
+	}
+	hp, _ = hp.Update(tea.KeyPressMsg{Code: 'a', Mod: tea.ModCtrl})
+	if len(hp.filtered) != 6 {
+		t.Fatalf("expected 6 entries across tabs, got %q", headingTitles(hp.filtered))
+	}
+	if !strings.Contains(hp.View(), "Other") {
+		t.Error("expected tab names when listing every tab")
+	}
+}
+
+func TestUpdate_GotoHeading(t *testing.T) {
+	r := testReader("", markDoc("First"), "/docs/a.md")
+	r.openNewTab("", markDoc("Second"), "/docs/b.md")
+	r.activeTab = 0
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	model, _ = model.Update(keyMsg("O"))
+	r = model.(markdownReader)
+	if !r.showHeadings {
+		t.Fatal("expected the heading picker to be shown")
+	}
+	r.headingPicker.input.Focus()
+
+	// Jump to the second tab's last section.
+	model, _ = r.Update(tea.KeyPressMsg{Code: 'a', Mod: tea.ModCtrl})
+	for _, k := range []string{"down", "down", "down", "down", "down", "enter"} {
+		model, _ = model.Update(keyMsg(k))
+	}
+	r = model.(markdownReader)
+	if r.showHeadings {
+		t.Error("expected the picker to close")
+	}
+	if r.activeTab != 1 {
+		t.Fatalf("activeTab = %d, want 1", r.activeTab)
+	}
+	if s := r.active().view.CurrentSection(); s == nil || s.Anchor != "end" {
+		t.Errorf("expected the End section at the top, got %+v", s)
+	}
+}
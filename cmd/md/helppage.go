@@ -52,10 +52,29 @@ func renderHelpPage(km readerKeyMap) string {
 		"FollowLink":    fmtKey(km.FollowLink),
 		"GoBack":        fmtKey(km.GoBack),
 		"CopySelection": fmtKey(km.CopySelection),
+		"CopyAs":        fmtKey(km.CopyAs),
+		"VisualMode":    fmtKey(km.VisualMode),
 		"Search":        fmtKey(km.Search),
 		"NextMatch":     fmtKey(km.NextMatch),
 		"PrevMatch":     fmtKey(km.PrevMatch),
 		"ClearSearch":   fmtKey(km.ClearSearch),
+		"ToggleTOC":     fmtKey(km.ToggleTOC),
+		"FocusTOC":      fmtKey(km.FocusTOC),
+		"TOCSelect":     fmtKey(km.TOCSelect),
+		"TOCExpand":     fmtKey(km.TOCExpand),
+		"TOCCollapse":   fmtKey(km.TOCCollapse),
+		"ToggleFold":    fmtKey(km.ToggleFold),
+		"Fold":          fmtKey(km.Fold),
+		"Unfold":        fmtKey(km.Unfold),
+		"ToggleAllFolds": fmtKey(km.ToggleAllFolds),
+		"FoldLevel":     fmtKey(km.FoldLevel),
+		"SetMark":       fmtKey(km.SetMark),
+		"JumpToMark":    fmtKey(km.JumpToMark),
+		"Hint":          fmtKey(km.Hint),
+		"Annotate":         fmtKey(km.Annotate),
+		"NextAnnotation":   fmtKey(km.NextAnnotation),
+		"PrevAnnotation":   fmtKey(km.PrevAnnotation),
+		"DeleteAnnotation": fmtKey(km.DeleteAnnotation),
 		// Reader keys
 		"ToggleSource":       fmtKey(km.ToggleSource),
 		"OpenFile":        fmtKey(km.OpenFile),
@@ -67,6 +86,8 @@ func renderHelpPage(km readerKeyMap) string {
 		"CloseTab":        fmtKey(km.CloseTab),
 		"CloseAllTabs":    fmtKey(km.CloseAllTabs),
 		"NewTab":          fmtKey(km.NewTab),
+		"Peek":            fmtKey(km.Peek),
+		"CheckLinks":      fmtKey(km.CheckLinks),
 		"Reload":          fmtKey(km.Reload),
 		"History":         fmtKey(km.History),
 		"SearchDocuments": fmtKey(km.SearchDocuments),
@@ -74,6 +95,20 @@ func renderHelpPage(km readerKeyMap) string {
 		"UserGuide":       fmtKey(km.UserGuide),
 		"BugReport":       fmtKey(km.BugReport),
 		"ExportGist":      fmtKey(km.ExportGist),
+		"ExportAnnotations": fmtKey(km.ExportAnnotations),
+		"Themes":          fmtKey(km.Themes),
+		"Bookmarks":       fmtKey(km.Bookmarks),
+		"GotoHeading":     fmtKey(km.GotoHeading),
+		"SearchTabs":      fmtKey(km.SearchTabs),
+		"SplitVertical":   fmtKey(km.SplitVertical),
+		"SplitHorizontal": fmtKey(km.SplitHorizontal),
+		"SourceSync":      fmtKey(km.SourceSync),
+		"Watch":           fmtKey(km.Watch),
+		"Present":         fmtKey(km.Present),
+		"RunCode":         fmtKey(km.RunCode),
+		"KillCode":        fmtKey(km.KillCode),
+		"SaveCode":        fmtKey(km.SaveCode),
+		"SwitchPane":      fmtKey(km.SwitchPane),
 		"Help":            fmtKey(km.Help),
 		"Quit":            fmtKey(km.Quit),
 	}
@@ -255,6 +255,16 @@ func (hp historyPicker) View() string {
 	return s.String()
 }
 
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (hp *historyPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 1, hp.minIdx, hp.maxIdx, len(hp.filtered))
+	if ok {
+		hp.cursor = i
+	}
+	return ok
+}
+
 // DidSelect returns whether an entry was selected and its pageStack index.
 // The index is -1 for the current page, or 0..N-1 for a stack entry.
 func (hp historyPicker) DidSelect() (bool, int) {
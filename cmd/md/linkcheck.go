@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+	"github.com/pgavlin/markdown-kit/markdown"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// linkCheckConcurrency is the number of HTTP links checked at once.
+const linkCheckConcurrency = 8
+
+// linkCheckTimeout bounds the time spent checking a single HTTP link.
+const linkCheckTimeout = 10 * time.Second
+
+// linkStatus is the outcome of checking a link.
+type linkStatus int
+
+const (
+	linkOK linkStatus = iota
+	linkBroken
+	linkUnchecked // e.g. mailto: links, or HTTP links when HTTP checks are off
+)
+
+// linkResult is the result of checking one link of a document.
+type linkResult struct {
+	index   int // index of the link in the view's Links
+	link    mdk.Link
+	status  linkStatus
+	problem string
+}
+
+// linkCheckMsg is sent when the links of a document have been checked.
+type linkCheckMsg struct {
+	source   string
+	markdown string
+	results  []linkResult
+}
+
+// linkChecker checks the links of a document. Anchors in the document itself
+// are looked up in its index; other local Markdown files are read and indexed
+// once per check.
+type linkChecker struct {
+	source    string
+	index     *indexer.DocumentIndex
+	checkHTTP bool
+	parser    *markdown.Parser
+	fsys      fileSystem
+	client    httpClient
+	logger    *slog.Logger
+
+	files map[string]*indexer.DocumentIndex
+}
+
+// checkLinks returns a command that checks the given links of the document at
+// source and reports the results in a linkCheckMsg.
+func checkLinks(c *linkChecker, markdown string, links []mdk.Link) tea.Cmd {
+	return func() tea.Msg {
+		results := make([]linkResult, len(links))
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, linkCheckConcurrency)
+		for i, link := range links {
+			results[i] = linkResult{index: i, link: link}
+			target, ok := c.httpTarget(link.Destination)
+			if !ok {
+				results[i].status, results[i].problem = c.check(link.Destination)
+				continue
+			}
+			if !c.checkHTTP {
+				results[i].status = linkUnchecked
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[i].status, results[i].problem = c.checkURL(target)
+			}()
+		}
+		wg.Wait()
+		return linkCheckMsg{source: c.source, markdown: markdown, results: results}
+	}
+}
+
+// httpTarget returns the URL that a link refers to if it is an HTTP link,
+// either absolute or relative to a web page.
+func (c *linkChecker) httpTarget(dest string) (string, bool) {
+	if dest == "" || strings.HasPrefix(dest, "#") {
+		return "", false
+	}
+	resolved := resolveLink(dest, c.source)
+	if !strings.HasPrefix(resolved, "http://") && !strings.HasPrefix(resolved, "https://") {
+		return "", false
+	}
+	target, _ := splitFragment(resolved)
+	return target, true
+}
+
+// check checks an in-document anchor or a local file link.
+func (c *linkChecker) check(dest string) (linkStatus, string) {
+	if dest == "" {
+		return linkBroken, "empty link"
+	}
+	if anchor, ok := strings.CutPrefix(dest, "#"); ok {
+		return checkAnchor(c.index, anchor, "")
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil {
+		return linkBroken, "invalid link"
+	}
+	if u.Scheme != "" {
+		return linkUnchecked, ""
+	}
+
+	path, fragment := splitFragment(resolveLink(dest, c.source))
+	if path == c.source {
+		return checkAnchor(c.index, fragment, "")
+	}
+	if _, err := c.fsys.Stat(path); err != nil {
+		c.logger.Info("link_check_missing", "path", path, "error", err)
+		return linkBroken, "file not found"
+	}
+	if fragment == "" || !isMarkdownFile(path) {
+		return linkOK, ""
+	}
+	index, err := c.fileIndex(path)
+	if err != nil {
+		return linkBroken, err.Error()
+	}
+	return checkAnchor(index, fragment, path)
+}
+
+// checkAnchor reports whether an anchor resolves to a section or an anchor
+// node of the given index. The empty anchor refers to the top of the
+// document.
+func checkAnchor(index *indexer.DocumentIndex, anchor, path string) (linkStatus, string) {
+	if anchor == "" {
+		return linkOK, ""
+	}
+	if index != nil {
+		if _, ok := index.Lookup(anchor); ok {
+			return linkOK, ""
+		}
+		if _, ok := index.LookupNode(anchor); ok {
+			return linkOK, ""
+		}
+	}
+	if path != "" {
+		return linkBroken, fmt.Sprintf("no anchor #%s in %s", anchor, path)
+	}
+	return linkBroken, fmt.Sprintf("no anchor #%s", anchor)
+}
+
+// fileIndex reads and indexes a local Markdown file.
+func (c *linkChecker) fileIndex(path string) (*indexer.DocumentIndex, error) {
+	if index, ok := c.files[path]; ok {
+		return index, nil
+	}
+	data, err := c.fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var index *indexer.DocumentIndex
+	parser := c.parser
+	if parser == nil {
+		parser = markdown.NewParser()
+	}
+	if doc, ok := parser.Parse(data).(*ast.Document); ok {
+		index = indexer.Index(doc, data)
+	}
+	if c.files == nil {
+		c.files = map[string]*indexer.DocumentIndex{}
+	}
+	c.files[path] = index
+	return index, nil
+}
+
+// checkURL checks an HTTP link with a HEAD request, falling back to GET for
+// servers that do not support HEAD.
+func (c *linkChecker) checkURL(target string) (linkStatus, string) {
+	status, err := c.request(http.MethodHead, target)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.request(http.MethodGet, target)
+	}
+	if err != nil {
+		c.logger.Info("link_check_error", "url", target, "error", err)
+		return linkBroken, err.Error()
+	}
+	if status >= 400 {
+		return linkBroken, fmt.Sprintf("%d %s", status, http.StatusText(status))
+	}
+	return linkOK, ""
+}
+
+// request sends a request for target and returns the response status.
+func (c *linkChecker) request(method, target string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), linkCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "markdown-kit/md")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// startLinkCheck checks the links of the active tab's document in the
+// background.
+func (r *markdownReader) startLinkCheck() tea.Cmd {
+	at := r.active()
+	links := at.view.Links()
+	if len(links) == 0 {
+		return r.flashStatus("No links to check")
+	}
+	r.checkingLinks = true
+	c := &linkChecker{
+		source:    at.currentSource,
+		index:     at.view.Index(),
+		checkHTTP: r.checkHTTPLinks,
+		parser:    r.parser,
+		fsys:      r.fsys,
+		client:    r.client,
+		logger:    r.logger,
+	}
+	at.view.SetStatusMessage(fmt.Sprintf("Checking %d links...", len(links)))
+	return checkLinks(c, string(at.view.GetMarkdown()), links)
+}
+
+// showLinkCheck marks the broken links in the tabs that show the checked
+// document and lists the results.
+func (r *markdownReader) showLinkCheck(msg linkCheckMsg) tea.Cmd {
+	r.checkingLinks = false
+
+	var broken []int
+	var unchecked int
+	for _, res := range msg.results {
+		switch res.status {
+		case linkBroken:
+			broken = append(broken, res.index)
+		case linkUnchecked:
+			unchecked++
+		}
+	}
+
+	tabIdx := -1
+	for i := range r.tabs {
+		t := &r.tabs[i]
+		if t.currentSource == msg.source && !t.showSource && string(t.view.GetMarkdown()) == msg.markdown {
+			t.view.SetBrokenLinks(broken)
+			if tabIdx < 0 || i == r.activeTab {
+				tabIdx = i
+			}
+		}
+	}
+	if tabIdx < 0 {
+		// The document has changed since the check started.
+		return nil
+	}
+
+	if len(broken) == 0 {
+		text := fmt.Sprintf("All %d links OK", len(msg.results)-unchecked)
+		if unchecked > 0 {
+			text += fmt.Sprintf(" (%d not checked)", unchecked)
+		}
+		return r.flashStatus(text)
+	}
+	r.active().view.SetStatusMessage("")
+	r.showLinks = true
+	r.linkPicker = newLinkPicker(msg.results, tabIdx, min(r.height*3/4, 24), r.width*3/4)
+	return r.linkPicker.input.Focus()
+}
+
+// gotoLink selects a link listed in the link check results.
+func (r *markdownReader) gotoLink(res linkResult) {
+	if r.linkPicker.tab < 0 || r.linkPicker.tab >= len(r.tabs) {
+		return
+	}
+	r.selectTab(r.linkPicker.tab)
+	r.active().view.SelectLink(res.index)
+	r.syncPanes()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+const linkCheckDoc = `# Guide
+
+## Setup
+
+Links: [setup](#setup), [missing](#missing), [api](api.md),
+[errors](api.md#errors), [legacy](api.md#legacy), [nope](api.md#nope),
+[gone](gone.md), [self](guide.md#setup), [mail](mailto:me@example.com),
+[web](https://example.com/docs).
+`
+
+const linkCheckAPIDoc = "# API\n\n<a id=\"legacy\"></a>\n\n## Errors\n\nErrors are values.\n"
+
+// linkCheckReader returns a sized reader for a document with working and
+// broken links.
+func linkCheckReader(t *testing.T) markdownReader {
+	t.Helper()
+	r := testReader("", linkCheckDoc, "/docs/guide.md")
+	r.fsys.(*memFS).files["/docs/api.md"] = []byte(linkCheckAPIDoc)
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return model.(markdownReader)
+}
+
+// linkStatuses returns the status of each link result by link text.
+func linkStatuses(results []linkResult) map[string]linkStatus {
+	statuses := map[string]linkStatus{}
+	for _, res := range results {
+		statuses[res.link.Text] = res.status
+	}
+	return statuses
+}
+
+func TestCheckLinks_Local(t *testing.T) {
+	r := linkCheckReader(t)
+	at := r.active()
+	c := &linkChecker{source: at.currentSource, index: at.view.Index(), fsys: r.fsys, client: r.client, logger: r.logger}
+	msg := checkLinks(c, string(at.view.GetMarkdown()), at.view.Links())().(linkCheckMsg)
+
+	want := map[string]linkStatus{
+		"setup":   linkOK,
+		"missing": linkBroken,
+		"api":     linkOK,
+		"errors":  linkOK,
+		"legacy":  linkOK,
+		"nope":    linkBroken,
+		"gone":    linkBroken,
+		"self":    linkOK,
+		"mail":    linkUnchecked,
+		"web":     linkUnchecked,
+	}
+	got := linkStatuses(msg.results)
+	for text, status := range want {
+		if got[text] != status {
+			t.Errorf("%s: status = %v, want %v", text, got[text], status)
+		}
+	}
+	for _, res := range msg.results {
+		if res.link.Text == "nope" && res.problem != "no anchor #nope in /docs/api.md" {
+			t.Errorf("problem = %q", res.problem)
+		}
+	}
+}
+
+func TestCheckLinks_HTTP(t *testing.T) {
+	var mu sync.Mutex
+	var methods []string
+	client := &fakeHTTPClient{
+		handler: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			methods = append(methods, req.Method+" "+req.URL.Path)
+			mu.Unlock()
+			status := http.StatusOK
+			switch {
+			case req.URL.Path == "/missing":
+				status = http.StatusNotFound
+			case req.URL.Path == "/nohead" && req.Method == http.MethodHead:
+				status = http.StatusMethodNotAllowed
+			}
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		},
+	}
+	c := &linkChecker{source: "https://example.com/docs/", checkHTTP: true, fsys: newMemFS(), client: client, logger: discardLogger()}
+	links := []mdk.Link{
+		{Destination: "https://example.com/ok#top", Text: "ok"},
+		{Destination: "/missing", Text: "missing"},
+		{Destination: "/nohead", Text: "nohead"},
+	}
+	msg := checkLinks(c, "", links)().(linkCheckMsg)
+
+	got := linkStatuses(msg.results)
+	if got["ok"] != linkOK || got["missing"] != linkBroken || got["nohead"] != linkOK {
+		t.Errorf("statuses = %v", got)
+	}
+	if msg.results[1].problem != "404 Not Found" {
+		t.Errorf("problem = %q", msg.results[1].problem)
+	}
+	if !strings.Contains(strings.Join(methods, ","), "GET /nohead") {
+		t.Errorf("expected a GET fallback, got %v", methods)
+	}
+}
+
+func TestUpdate_CheckLinks(t *testing.T) {
+	r := linkCheckReader(t)
+	r, _ = pressMark(t, r, "L")
+	if !r.showLinks {
+		t.Fatal("expected the link check results")
+	}
+	if r.linkPicker.broken != 3 || r.linkPicker.unchecked != 2 {
+		t.Errorf("broken = %d, unchecked = %d", r.linkPicker.broken, r.linkPicker.unchecked)
+	}
+	if first := r.linkPicker.filtered[0]; first.link.Text != "missing" {
+		t.Errorf("first result = %q, want the first broken link", first.link.Text)
+	}
+
+	// Broken links are marked in the document.
+	if view := r.active().view.View(); strings.Count(view, "\033[31;9m") != 3 {
+		t.Errorf("expected 3 marked links:\n%s", view)
+	}
+
+	r = press(t, r, "down", "enter")
+	if r.showLinks {
+		t.Error("expected enter to close the results")
+	}
+	if got := r.active().view.FocusedLinkDestination(); got != "api.md#nope" {
+		t.Errorf("selected link = %q, want the chosen broken link", got)
+	}
+}
+
+func TestUpdate_CheckLinksAllOK(t *testing.T) {
+	r := testReader("", "# Guide\n\nSee [the guide](#guide).\n", "/docs/guide.md")
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	r, _ = pressMark(t, model.(markdownReader), "L")
+	if r.showLinks {
+		t.Error("expected no results list when every link works")
+	}
+	if !strings.Contains(r.active().view.View(), "All 1 links OK") {
+		t.Error("expected a status message")
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// linkPicker lists the results of a link check, broken links first, and
+// jumps to the chosen link.
+type linkPicker struct {
+	input     textinput.Model
+	all       []linkResult // full list, broken links first
+	filtered  []linkResult // after text filter
+	tab       int          // index of the tab that shows the checked document
+	broken    int
+	unchecked int
+	cursor    int
+	minIdx    int
+	maxIdx    int
+	height    int
+	width     int
+	selected  bool
+	dismissed bool
+}
+
+// Style constants for the link picker.
+var (
+	lpBrokenStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	lpOKStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	lpUncheckedStyle = lipgloss.NewStyle().Foreground(colorMuted)
+)
+
+// newLinkPicker creates a picker listing the given link check results of the
+// document shown in the given tab.
+func newLinkPicker(results []linkResult, tab, height, width int) linkPicker {
+	ti := textinput.New()
+	ti.Prompt = "  Filter: "
+	ti.Placeholder = "type to filter..."
+	innerW := width - 4 // account for border + padding
+	ti.SetWidth(innerW - lipgloss.Width(ti.Prompt) - 1)
+
+	listHeight := height - 2 // subtract input line + summary
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	all := append([]linkResult(nil), results...)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].status == linkBroken && all[j].status != linkBroken
+	})
+	lp := linkPicker{
+		input:  ti,
+		all:    all,
+		tab:    tab,
+		maxIdx: listHeight - 1,
+		height: listHeight,
+		width:  width,
+	}
+	for _, res := range all {
+		switch res.status {
+		case linkBroken:
+			lp.broken++
+		case linkUnchecked:
+			lp.unchecked++
+		}
+	}
+	lp.filter()
+	return lp
+}
+
+func (lp linkPicker) Update(msg tea.Msg) (linkPicker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			lp.dismissed = true
+			return lp, nil
+
+		case "enter":
+			if len(lp.filtered) == 0 || lp.cursor < 0 {
+				return lp, nil
+			}
+			lp.selected = true
+			return lp, nil
+
+		case "up", "ctrl+p":
+			lp.cursor--
+			if lp.cursor < 0 {
+				lp.cursor = 0
+			}
+			if lp.cursor < lp.minIdx {
+				lp.minIdx = lp.cursor
+				lp.maxIdx = lp.minIdx + lp.height - 1
+			}
+			return lp, nil
+
+		case "down", "ctrl+n":
+			lp.cursor++
+			if lp.cursor >= len(lp.filtered) {
+				lp.cursor = len(lp.filtered) - 1
+			}
+			if lp.cursor < 0 {
+				lp.cursor = 0
+			}
+			if lp.cursor > lp.maxIdx {
+				lp.maxIdx = lp.cursor
+				lp.minIdx = lp.maxIdx - lp.height + 1
+			}
+			return lp, nil
+
+		default:
+			prevValue := lp.input.Value()
+			var cmd tea.Cmd
+			lp.input, cmd = lp.input.Update(msg)
+			if lp.input.Value() != prevValue {
+				lp.filter()
+			}
+			return lp, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	lp.input, cmd = lp.input.Update(msg)
+	return lp, cmd
+}
+
+// filter applies case-insensitive subsequence matching on link text,
+// destination, and problem.
+func (lp *linkPicker) filter() {
+	query := strings.ToLower(lp.input.Value())
+	lp.filtered = nil
+	for _, res := range lp.all {
+		if query == "" || subsequenceMatch(strings.ToLower(res.link.Text), query) || subsequenceMatch(strings.ToLower(res.link.Destination), query) || subsequenceMatch(strings.ToLower(res.problem), query) {
+			lp.filtered = append(lp.filtered, res)
+		}
+	}
+
+	if lp.cursor >= len(lp.filtered) {
+		lp.cursor = max(0, len(lp.filtered)-1)
+	}
+	lp.minIdx = 0
+	lp.maxIdx = lp.height - 1
+	if lp.cursor > lp.maxIdx {
+		lp.minIdx = lp.cursor - lp.height + 1
+		lp.maxIdx = lp.cursor
+	}
+}
+
+func (lp linkPicker) View() string {
+	var s strings.Builder
+
+	s.WriteString(lp.input.View())
+	s.WriteRune('\n')
+
+	summary := fmt.Sprintf("  %d broken, %d ok", lp.broken, len(lp.all)-lp.broken-lp.unchecked)
+	if lp.unchecked > 0 {
+		summary += fmt.Sprintf(", %d not checked", lp.unchecked)
+	}
+	s.WriteString(hpSourceStyle.Render(summary))
+	s.WriteRune('\n')
+
+	if len(lp.filtered) == 0 {
+		s.WriteString(hpEmptyStyle.Render("  No matching links."))
+		s.WriteRune('\n')
+	} else {
+		for i, res := range lp.filtered {
+			if i < lp.minIdx || i > lp.maxIdx {
+				continue
+			}
+
+			var mark string
+			switch res.status {
+			case linkBroken:
+				mark = lpBrokenStyle.Render("✗")
+			case linkOK:
+				mark = lpOKStyle.Render("✓")
+			default:
+				mark = lpUncheckedStyle.Render("-")
+			}
+			text := res.link.Text
+			if text == "" {
+				text = "(no text)"
+			}
+			detail := res.link.Destination
+			if res.problem != "" {
+				detail += " — " + res.problem
+			}
+
+			// Truncate the destination and problem to fit.
+			detailMaxW := lp.width - ansi.StringWidth(text) - 10 // cursor + mark + spaces + padding
+			if detailMaxW > 3 {
+				detail = ansi.Truncate(detail, detailMaxW, "...")
+			}
+
+			if i == lp.cursor {
+				s.WriteString(hpCursorStyle.Render(">") + " " + mark + hpSelectedStyle.Render(" "+text+"  "+detail))
+			} else {
+				s.WriteString(hpCursorStyle.Render(" ") + " " + mark)
+				s.WriteString(" " + hpNameStyle.Render(text))
+				s.WriteString("  " + hpSourceStyle.Render(detail))
+			}
+			s.WriteRune('\n')
+		}
+	}
+
+	// Pad remaining height.
+	rendered := lipgloss.Height(s.String())
+	for i := rendered; i <= lp.height+2; i++ {
+		s.WriteRune('\n')
+	}
+
+	return s.String()
+}
+
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (lp *linkPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 2, lp.minIdx, lp.maxIdx, len(lp.filtered))
+	if ok {
+		lp.cursor = i
+	}
+	return ok
+}
+
+// DidSelect returns whether a link was chosen and, if so, its result.
+func (lp linkPicker) DidSelect() (bool, linkResult) {
+	if !lp.selected || lp.cursor < 0 || lp.cursor >= len(lp.filtered) {
+		return false, linkResult{}
+	}
+	return true, lp.filtered[lp.cursor]
+}
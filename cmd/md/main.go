@@ -14,6 +14,7 @@ import (
 	"github.com/pgavlin/markdown-kit/docsearch"
 	mdk "github.com/pgavlin/markdown-kit/view"
 	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -47,6 +48,32 @@ func main() {
 					return toml.NewEncoder(os.Stdout).Encode(cfg)
 				},
 			},
+			{
+				Name:  "theme",
+				Usage: "work with color themes",
+				Commands: []*cli.Command{
+					{
+						Name:      "preview",
+						Usage:     "render a sample document with a theme",
+						ArgsUsage: "<theme file or name>",
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							if cmd.Args().Len() != 1 {
+								return fmt.Errorf("expected exactly one theme file or name")
+							}
+							theme, err := resolvePreviewTheme(cmd.Args().First(), osFileSystem{})
+							if err != nil {
+								return err
+							}
+
+							width := 80
+							if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+								width = w
+							}
+							return renderThemePreview(os.Stdout, theme, width)
+						},
+					},
+				},
+			},
 			{
 				Name:  "system",
 				Usage: "manage system data (cache, index)",
@@ -108,17 +135,35 @@ func main() {
 				}
 			}
 
-			theme := cfg.theme()
+			theme, err := cfg.theme(fsys)
+			if err != nil {
+				return fmt.Errorf("error in config: %w", err)
+			}
 			conv := cfg.Converter.newConverter()
 			registry := newConverterRegistry(cfg.Converters, osShellRunner{})
 			cache := openCache()
 			httpCl := http.DefaultClient
 
+			parser := cfg.Markdown.newParser()
+
 			var viewOpts []mdk.Option
-			viewOpts = append(viewOpts, mdk.WithDiagramRenderer(diagram.MermaidRenderer()))
+			viewOpts = append(viewOpts, mdk.WithParser(parser), mdk.WithDiagramRenderer(diagram.MermaidRenderer()))
 			if cfg.stripDataURIs() {
 				viewOpts = append(viewOpts, mdk.WithDocumentTransformer(mdk.StripDataURIs))
 			}
+			clipboardBackend, err := parseClipboardBackend(cfg.Clipboard)
+			if err != nil {
+				return fmt.Errorf("error in config: %w", err)
+			}
+			slideBreak, err := parseSlideBreak(cfg.Slides.Split)
+			if err != nil {
+				return fmt.Errorf("error in config: %w", err)
+			}
+			copyText := newClipboardWriter(clipboardBackend, logger)
+			viewOpts = append(viewOpts, mdk.WithClipboard(copyText))
+			if cfg.Mouse {
+				viewOpts = append(viewOpts, mdk.WithMouse(true))
+			}
 
 			// Open the document search index.
 			var searchIndex *docsearch.Index
@@ -126,7 +171,7 @@ func main() {
 				if err := os.MkdirAll(dd, 0o755); err == nil {
 					dbPath := filepath.Join(dd, "index.db")
 					embedder := cfg.Search.newEmbedder()
-					if idx, err := docsearch.Open(dbPath, embedder); err == nil {
+					if idx, err := docsearch.Open(dbPath, embedder, docsearch.WithParser(parser)); err == nil {
 						searchIndex = idx
 						defer idx.Close()
 					} else {
@@ -135,6 +180,28 @@ func main() {
 				}
 			}
 
+			// Load bookmarks. A bookmarks file that fails to load is left
+			// untouched rather than overwritten.
+			var bookmarks *bookmarkStore
+			if dd, err := dataDir(); err == nil {
+				path := filepath.Join(dd, bookmarksFileName)
+				if b, err := loadBookmarks(path, fsys); err == nil {
+					bookmarks = b
+				} else {
+					logger.Error("bookmarks_load_error", "path", path, "error", err)
+				}
+			}
+
+			// Load annotations. Sidecar files work without a data directory.
+			var annotationsPath string
+			if dd, err := dataDir(); err == nil {
+				annotationsPath = filepath.Join(dd, annotationsFileName)
+			}
+			annotations, err := loadAnnotations(annotationsPath, fsys, cfg.Annotations.sidecars())
+			if err != nil {
+				logger.Error("annotations_load_error", "path", annotationsPath, "error", err)
+			}
+
 			var model markdownReader
 			if cmd.Args().Len() == 0 {
 				// No args — start with file picker.
@@ -203,6 +270,24 @@ func main() {
 				}
 			}
 
+			model.configPath = cfgPath
+			model.themeSetting = cfg.Theme
+			model.bookmarks = bookmarks
+			model.annotations = annotations
+			model.resumePositions = cfg.resumePosition()
+			model.mouse = cfg.Mouse
+			model.checkHTTPLinks = cfg.CheckHTTPLinks
+			model.parser = parser
+			model.watch.enabled = cfg.Watch
+			model.slideBreak = slideBreak
+			model.incrementalLists = cfg.Slides.Incremental
+			model.slideNotes = cfg.Slides.Notes
+			model.codeRuns.dirs = cfg.Run.allowedDirs(cfg.dir)
+			model.codeRuns.interpreters = cfg.Run.interpreters()
+			model.clipboard = copyText
+			model.restoreMarks(model.active())
+			model.restoreAnnotations(model.active())
+			model.restorePosition(model.active())
 			cfg.applyKeys(&model.keys)
 			model.active().view.KeyMap = model.keys.KeyMap
 
@@ -242,6 +327,7 @@ func main() {
 					}
 					model.openNewTab("", string(source), absPath)
 				}
+				model.restorePosition(model.active())
 			}
 
 			// Activate the first tab when multiple were opened.
@@ -251,7 +337,11 @@ func main() {
 
 			p := tea.NewProgram(model)
 
-			if _, err := p.Run(); err != nil {
+			final, err := p.Run()
+			if r, ok := final.(markdownReader); ok {
+				r.codeRuns.cancelAll()
+			}
+			if err != nil {
 				return fmt.Errorf("error running app: %w", err)
 			}
 
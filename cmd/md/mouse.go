@@ -0,0 +1,217 @@
+package main
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// handleMouse handles a mouse event. Events over a picker move or choose its
+// entries, clicks on the tab bar switch tabs, and other events go to the view
+// of the pane under the pointer.
+func (r markdownReader) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	mouse := msg.Mouse()
+
+	var view string
+	var click func(line int) bool
+	switch {
+	case r.present.active:
+		// The wheel scrolls a slide that does not fit on the screen.
+		if _, ok := msg.(tea.MouseWheelMsg); ok {
+			switch mouse.Button {
+			case tea.MouseWheelUp:
+				r.present.view.ScrollUp(3)
+			case tea.MouseWheelDown:
+				r.present.view.ScrollDown(3)
+			}
+		}
+		return r, nil
+	case r.showSearch:
+		view, click = r.searchPicker.View(), r.searchPicker.click
+	case r.showSimilar:
+		view, click = r.similarPicker.View(), r.similarPicker.click
+	case r.showHistory:
+		view, click = r.historyPicker.View(), r.historyPicker.click
+	case r.showThemes:
+		view, click = r.themePicker.View(), r.themePicker.click
+	case r.showBookmarks:
+		view, click = r.bookmarkPicker.View(), r.bookmarkPicker.click
+	case r.showHeadings:
+		view, click = r.headingPicker.View(), r.headingPicker.click
+	case r.showTabSearch:
+		view, click = r.tabSearchPicker.View(), r.tabSearchPicker.click
+	case r.showLinks:
+		view, click = r.linkPicker.View(), r.linkPicker.click
+	case r.peek.active:
+		// The wheel scrolls the peeked document.
+		if _, ok := msg.(tea.MouseWheelMsg); ok {
+			switch mouse.Button {
+			case tea.MouseWheelUp:
+				r.peek.view.ScrollUp(3)
+			case tea.MouseWheelDown:
+				r.peek.view.ScrollDown(3)
+			}
+		}
+		return r, nil
+	case r.showURLInput, r.showBugReport, r.codeRuns.confirm != nil, r.codeRuns.saving, r.loading, r.showHelp, r.showError:
+		return r, nil
+	}
+	if click != nil {
+		switch msg.(type) {
+		case tea.MouseWheelMsg:
+			switch mouse.Button {
+			case tea.MouseWheelUp:
+				return r.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+			case tea.MouseWheelDown:
+				return r.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+			}
+		case tea.MouseClickMsg:
+			if line, ok := r.overlayLineAt(view, mouse.X, mouse.Y); ok && mouse.Button == tea.MouseLeft && click(line) {
+				return r.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+			}
+		}
+		return r, nil
+	}
+
+	if mouse.Y < r.tabBarHeight() {
+		if _, ok := msg.(tea.MouseClickMsg); ok && mouse.Button == tea.MouseLeft {
+			if idx, ok := r.tabAt(mouse.X); ok {
+				r.selectTab(idx)
+				r.syncPanes()
+			}
+		}
+		return r, nil
+	}
+
+	// Drags and releases belong to the pane that was pressed, which is
+	// focused by the press.
+	second := r.split.second
+	switch msg.(type) {
+	case tea.MouseClickMsg, tea.MouseWheelMsg:
+		var ok bool
+		if second, ok = r.paneAt(mouse.X, mouse.Y); !ok {
+			return r, nil
+		}
+	}
+
+	idx := r.activeTab
+	if second != r.split.second {
+		// Scrolling the unfocused pane leaves focus where it is, unless the
+		// panes scroll together.
+		if _, ok := msg.(tea.MouseWheelMsg); ok && !r.split.sync {
+			idx = r.split.other
+		} else {
+			r.switchPane()
+			idx = r.activeTab
+		}
+	}
+
+	x, y := r.paneOrigin(second)
+	t := &r.tabs[idx]
+	var cmd tea.Cmd
+	t.view, cmd = t.view.Update(translateMouse(msg, x, y))
+	r.syncPanes()
+	return r, cmd
+}
+
+// translateMouse returns a copy of a mouse event with its position made
+// relative to the given origin.
+func translateMouse(msg tea.MouseMsg, x, y int) tea.MouseMsg {
+	mouse := msg.Mouse()
+	mouse.X -= x
+	mouse.Y -= y
+	switch msg.(type) {
+	case tea.MouseClickMsg:
+		return tea.MouseClickMsg(mouse)
+	case tea.MouseReleaseMsg:
+		return tea.MouseReleaseMsg(mouse)
+	case tea.MouseWheelMsg:
+		return tea.MouseWheelMsg(mouse)
+	case tea.MouseMotionMsg:
+		return tea.MouseMotionMsg(mouse)
+	}
+	return msg
+}
+
+// paneAt returns whether the given screen position is in the second (right or
+// bottom) pane. Returns false if the position is on the tab bar or on the
+// separator between panes.
+func (r *markdownReader) paneAt(x, y int) (second, ok bool) {
+	y -= r.tabBarHeight()
+	if y < 0 {
+		return false, false
+	}
+	width, height := r.paneSize(false)
+	switch r.split.layout {
+	case splitVertical:
+		return x > width, x != width
+	case splitHorizontal:
+		return y > height, y != height
+	}
+	return false, true
+}
+
+// paneOrigin returns the screen position of the top-left corner of the first
+// or second pane.
+func (r *markdownReader) paneOrigin(second bool) (x, y int) {
+	y = r.tabBarHeight()
+	if !second {
+		return 0, y
+	}
+	width, height := r.paneSize(false)
+	switch r.split.layout {
+	case splitVertical:
+		return width + 1, y
+	case splitHorizontal:
+		return 0, y + height + 1
+	}
+	return 0, y
+}
+
+// tabAt returns the index of the tab whose name is shown at the given column
+// of the tab bar.
+func (r *markdownReader) tabAt(x int) (int, bool) {
+	start := 0
+	for i := range r.tabs {
+		end := start + ansi.StringWidth(r.tabLabel(i)) + 2 // padding
+		if x >= start && x < end {
+			return i, true
+		}
+		start = end + 1 // separator
+	}
+	return 0, false
+}
+
+// overlayLineAt returns the line of a picker's view shown at the given screen
+// position when the picker is drawn in a fixed overlay below a header line.
+// Returns false if the position is outside the picker's view.
+func (r *markdownReader) overlayLineAt(view string, x, y int) (int, bool) {
+	fixedW := r.width * 3 / 4
+	if fixedW < 40 {
+		fixedW = min(r.width-4, 40)
+	}
+	maxH := r.height * 3 / 4
+
+	// The dialog's content is the header, a blank line, and the view, within
+	// a border.
+	lines := min(strings.Count(view, "\n")+3, maxH-2)
+	top := max((r.height-lines-2)/2, 0)
+	left := max((r.width-fixedW)/2, 0)
+	if x <= left || x >= left+fixedW-1 || y <= top || y > top+lines {
+		return 0, false
+	}
+	line := y - top - 3
+	return line, line >= 0
+}
+
+// listEntryAt returns the index of the entry shown on the given line of a
+// picker's view, where the list starts at line first and shows the entries
+// from minIdx through maxIdx of count entries.
+func listEntryAt(line, first, minIdx, maxIdx, count int) (int, bool) {
+	i := minIdx + line - first
+	if line < first || i > maxIdx || i >= count {
+		return 0, false
+	}
+	return i, true
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/styles"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// mouseReader returns a sized reader with mouse support enabled.
+func mouseReader(t *testing.T, markdown string) markdownReader {
+	t.Helper()
+	r := newMarkdownReader(
+		"", markdown, "/docs/a.md",
+		styles.GlamourDark,
+		[]mdk.Option{mdk.WithMouse(true)},
+		&fakeConverter{},
+		nil,
+		nil,
+		&fakeHTTPClient{},
+		newMemFS(),
+		nil,
+		discardLogger(),
+	)
+	r.mouse = true
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 81, Height: 20})
+	return model.(markdownReader)
+}
+
+func sendMouse(r markdownReader, msgs ...tea.Msg) markdownReader {
+	var model tea.Model = r
+	for _, msg := range msgs {
+		model, _ = model.Update(msg)
+	}
+	return model.(markdownReader)
+}
+
+func TestMouse_Disabled(t *testing.T) {
+	r := splitReader(t)
+	if mode := r.View().MouseMode; mode != tea.MouseModeNone {
+		t.Errorf("mouse mode = %v, want none", mode)
+	}
+	r = sendMouse(r, tea.MouseWheelMsg{Button: tea.MouseWheelDown, Y: 5})
+	if off := r.active().view.LineOffset(); off != 0 {
+		t.Errorf("line offset = %d, want 0", off)
+	}
+}
+
+func TestMouse_WheelScrolls(t *testing.T) {
+	r := mouseReader(t, markDoc("First"))
+	if mode := r.View().MouseMode; mode != tea.MouseModeCellMotion {
+		t.Errorf("mouse mode = %v, want cell motion", mode)
+	}
+	r = sendMouse(r, tea.MouseWheelMsg{Button: tea.MouseWheelDown, Y: 5})
+	if off := r.active().view.LineOffset(); off != 3 {
+		t.Errorf("line offset = %d, want 3", off)
+	}
+}
+
+func TestMouse_ClickTab(t *testing.T) {
+	r := mouseReader(t, markDoc("First"))
+	r.openNewTab("", markDoc("Second"), "/docs/b.md")
+	r.activeTab = 0
+
+	// The second tab's name follows the first one's.
+	x := strings.Index(ansi.Strip(r.renderTabBar()), "Second")
+	r = sendMouse(r, tea.MouseClickMsg{Button: tea.MouseLeft, X: x})
+	if r.activeTab != 1 {
+		t.Errorf("activeTab = %d, want 1", r.activeTab)
+	}
+}
+
+func TestMouse_ClickPickerEntry(t *testing.T) {
+	r := mouseReader(t, markDoc("First"))
+	r = press(t, r, "O")
+	if !r.showHeadings {
+		t.Fatal("expected the heading picker to be shown")
+	}
+
+	var x, y int
+	for i, line := range strings.Split(ansi.Strip(r.View().Content), "\n") {
+		if j := strings.Index(line, "End"); j >= 0 {
+			x, y = j, i
+		}
+	}
+	r = sendMouse(r, tea.MouseClickMsg{Button: tea.MouseLeft, X: x, Y: y})
+	if r.showHeadings {
+		t.Error("expected the picker to close")
+	}
+	if s := r.active().view.CurrentSection(); s == nil || s.Anchor != "end" {
+		t.Errorf("expected the End section at the top, got %+v", s)
+	}
+}
+
+func TestMouse_SplitPanes(t *testing.T) {
+	r := press(t, mouseReader(t, markDoc("First")), "|")
+
+	// Scrolling the unfocused pane leaves focus where it is.
+	r = sendMouse(r, tea.MouseWheelMsg{Button: tea.MouseWheelDown, X: 60, Y: 5})
+	if r.split.second {
+		t.Error("expected focus to stay on the first pane")
+	}
+	if off := r.tabs[r.split.other].view.LineOffset(); off != 3 {
+		t.Errorf("other pane line offset = %d, want 3", off)
+	}
+
+	// Clicking the separator does nothing; clicking a pane focuses it.
+	r = sendMouse(r, tea.MouseClickMsg{Button: tea.MouseLeft, X: 40, Y: 5})
+	if r.split.second {
+		t.Error("expected a click on the separator to be ignored")
+	}
+	r = sendMouse(r, tea.MouseClickMsg{Button: tea.MouseLeft, X: 60, Y: 5})
+	if !r.split.second || r.activeTab != 1 {
+		t.Errorf("second = %v, activeTab = %d, want the second pane focused", r.split.second, r.activeTab)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// peekState holds the link peek overlay, which shows the target of the focused
+// link without navigating to it.
+type peekState struct {
+	active bool
+	id     int    // distinguishes the loads of successive peeks
+	link   string // the peeked link, as written in the document
+	anchor string // the anchor of a link into the current document
+
+	// The loaded page, or the error that prevented it from loading.
+	loaded bool
+	page   pageLoadedMsg
+	err    error
+
+	view mdk.Model
+}
+
+// peekLoadedMsg is sent when the target of a peeked link has loaded.
+type peekLoadedMsg struct {
+	id   int
+	page pageLoadedMsg
+}
+
+// peekLoadErrorMsg is sent when the target of a peeked link fails to load.
+type peekLoadErrorMsg struct {
+	id  int
+	err error
+}
+
+// peekPage wraps a page loader so that its result is delivered to the peek
+// overlay with the given id rather than to the active tab.
+func peekPage(id int, load tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		switch msg := load().(type) {
+		case pageLoadedMsg:
+			return peekLoadedMsg{id: id, page: msg}
+		case pageLoadErrorMsg:
+			return peekLoadErrorMsg{id: id, err: msg.err}
+		default:
+			return msg
+		}
+	}
+}
+
+// peekSize returns the size of the peek overlay.
+func (r *markdownReader) peekSize() (width, height int) {
+	width = r.width * 3 / 4
+	if width < 40 {
+		width = min(r.width-4, 40)
+	}
+	return width, r.height * 3 / 4
+}
+
+// resizePeek sizes the peek view to fit inside the overlay, below its header.
+func (r *markdownReader) resizePeek() {
+	if !r.peek.active {
+		return
+	}
+	width, height := r.peekSize()
+	r.peek.view.SetSize(max(width-4, 1), max(height-3, 1)) // border, padding and header
+}
+
+// startPeek opens the peek overlay on the target of the focused link. Links
+// into the current document show the target section; other documents are
+// loaded in the background and shown at the link's fragment.
+func (r *markdownReader) startPeek() tea.Cmd {
+	at := r.active()
+	link := at.view.FocusedLinkDestination()
+	if link == "" {
+		return r.flashStatus("No link selected")
+	}
+
+	resolved, fragment := splitFragment(resolveLink(link, at.currentSource))
+	var anchor string
+	if strings.HasPrefix(link, "#") || (fragment != "" && at.currentSource != "" && resolved == at.currentSource) {
+		anchor = fragment
+	}
+	isAnchor := anchor != ""
+
+	var load tea.Cmd
+	if !isAnchor {
+		if load = r.pageLoader(resolved, fragment, false); load == nil {
+			return r.flashStatus("Cannot preview " + link)
+		}
+	}
+
+	view := mdk.NewModel(append([]mdk.Option{
+		mdk.WithTheme(r.theme),
+		mdk.WithGutter(true),
+	}, r.viewOpts...)...)
+	view.KeyMap = r.keys.KeyMap
+	r.peek = peekState{
+		active: true,
+		id:     r.peek.id + 1,
+		link:   link,
+		anchor: anchor,
+		view:   view,
+	}
+	r.resizePeek()
+
+	if isAnchor {
+		sections, ok := at.view.Index().Lookup(anchor)
+		if !ok {
+			r.closePeek()
+			return r.flashStatus("No section for #" + anchor)
+		}
+		r.peek.view.SetText(at.view.GetName(), at.view.SectionMarkdown(sections[0]))
+		r.peek.loaded = true
+		return nil
+	}
+	return peekPage(r.peek.id, load)
+}
+
+// closePeek closes the peek overlay.
+func (r *markdownReader) closePeek() {
+	r.peek = peekState{id: r.peek.id}
+}
+
+// handlePeekKey handles a key press while the peek overlay is open. Enter
+// follows the link, t opens it in a new tab, and scrolling keys scroll the
+// overlay.
+func (r markdownReader) handlePeekKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		r.closePeek()
+		return r, nil
+	case "enter":
+		return r.promotePeek(false)
+	case "t":
+		return r.promotePeek(true)
+	}
+	if key.Matches(msg, r.keys.Peek) {
+		r.closePeek()
+		return r, nil
+	}
+
+	km := r.peek.view.KeyMap
+	if key.Matches(msg, km.Up, km.Down, km.PageUp, km.PageDown, km.GotoTop, km.GotoEnd, km.Left, km.Right, km.NextHeading, km.PrevHeading) {
+		var cmd tea.Cmd
+		r.peek.view, cmd = r.peek.view.Update(msg)
+		return r, cmd
+	}
+	return r, nil
+}
+
+// promotePeek closes the peek overlay and navigates to the peeked link, in
+// the active tab or in a new one. A page that has already loaded is shown
+// without loading it again.
+func (r markdownReader) promotePeek(newTab bool) (tea.Model, tea.Cmd) {
+	p := r.peek
+	r.closePeek()
+
+	at := r.active()
+	switch {
+	case p.anchor != "" && newTab:
+		return r.Update(pageLoadedMsg{
+			name:     at.view.GetName(),
+			markdown: string(at.view.GetMarkdown()),
+			source:   at.currentSource,
+			newTab:   true,
+			fragment: p.anchor,
+		})
+	case p.anchor != "":
+		at.view.FollowLink()
+		r.syncPanes()
+		return r, nil
+	case p.loaded:
+		page := p.page
+		page.newTab = newTab
+		return r.Update(page)
+	default:
+		return r, r.handleLinkNavigation(p.link, newTab)
+	}
+}
+
+// renderPeek renders the peek overlay over base.
+func (r markdownReader) renderPeek(base string) string {
+	width, height := r.peekSize()
+	innerW := width - 4 // border + padding
+
+	header := lipgloss.NewStyle().Bold(true).Render("Peek") + " " + lipgloss.NewStyle().Foreground(colorMuted).Render(r.peek.link)
+	hint := lipgloss.NewStyle().Foreground(colorMuted).Render("enter open · t new tab · esc close")
+	if gap := innerW - ansi.StringWidth(header) - ansi.StringWidth(hint); gap >= 2 {
+		header += strings.Repeat(" ", gap) + hint
+	} else {
+		header = ansi.Truncate(header, innerW, "…")
+	}
+
+	var body string
+	switch {
+	case r.peek.err != nil:
+		body = wordWrap(fmt.Sprintf("Error loading %s: %v", r.peek.link, r.peek.err), innerW)
+	case !r.peek.loaded:
+		body = fmt.Sprintf("Loading %s...", r.peek.link)
+	default:
+		body = r.peek.view.View()
+	}
+	return r.renderOverlay(base, header+"\n"+body, width, height)
+}
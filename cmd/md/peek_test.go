@@ -0,0 +1,123 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var peekDoc = "# Guide\n\nSee [setup](#setup) and [the API](api.md#errors).\n\n" + strings.Repeat("Background.\n\n", 20) + "## Setup\n\nInstall the tool.\n\n## Usage\n\nRun the tool.\n"
+
+var peekAPIDoc = "# API\n\nOverview.\n\n## Calls\n\n" + strings.Repeat("Details.\n\n", 20) + "## Errors\n\nErrors are returned as values.\n"
+
+// peekReader returns a sized reader for a document that links to a section
+// of itself and to another file.
+func peekReader(t *testing.T) markdownReader {
+	t.Helper()
+	r := testReader("", peekDoc, "/docs/guide.md")
+	fs := r.fsys.(*memFS)
+	fs.files["/docs/api.md"] = []byte(peekAPIDoc)
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return model.(markdownReader)
+}
+
+func TestUpdate_PeekAnchor(t *testing.T) {
+	r := peekReader(t)
+	r = press(t, r, "]", "p")
+	if !r.peek.active {
+		t.Fatal("expected the peek overlay to open")
+	}
+	if got := string(r.peek.view.GetMarkdown()); got != "## Setup\n\nInstall the tool." {
+		t.Errorf("peeked markdown = %q", got)
+	}
+	view := ansi.Strip(r.View().Content)
+	if !strings.Contains(view, "Peek #setup") || !strings.Contains(view, "Install the tool.") {
+		t.Errorf("expected the peeked section in the view:\n%s", view)
+	}
+
+	r = press(t, r, "esc")
+	if r.peek.active {
+		t.Error("expected esc to close the peek overlay")
+	}
+	if r.active().view.LineOffset() != 0 {
+		t.Error("expected peeking not to scroll the document")
+	}
+}
+
+func TestUpdate_PeekFile(t *testing.T) {
+	r := peekReader(t)
+	r, _ = pressMark(t, r, "]", "]", "p")
+	if !r.peek.loaded {
+		t.Fatal("expected the peeked file to load")
+	}
+	if r.active().currentSource != "/docs/guide.md" {
+		t.Errorf("active source = %q, want the document to stay open", r.active().currentSource)
+	}
+	if got := ansi.Strip(r.peek.view.View()); !strings.Contains(got, "Errors are returned as values.") || strings.Contains(got, "Overview.") {
+		t.Errorf("expected the peek to show the fragment:\n%s", got)
+	}
+
+	// Scrolling keys reach the peeked document.
+	offset := r.peek.view.LineOffset()
+	r = press(t, r, "k")
+	if r.peek.view.LineOffset() != offset-1 {
+		t.Errorf("line offset = %d, want %d", r.peek.view.LineOffset(), offset-1)
+	}
+
+	r = press(t, r, "enter")
+	if r.peek.active {
+		t.Error("expected enter to close the peek overlay")
+	}
+	at := r.active()
+	if at.currentSource != "/docs/api.md" || len(at.pageStack) != 1 {
+		t.Errorf("source = %q, stack = %d; want the peeked file pushed", at.currentSource, len(at.pageStack))
+	}
+}
+
+func TestUpdate_PeekNewTab(t *testing.T) {
+	r := peekReader(t)
+	r, _ = pressMark(t, r, "]", "]", "p")
+	r = press(t, r, "t")
+	if len(r.tabs) != 2 || r.activeTab != 1 {
+		t.Fatalf("tabs = %d, active = %d; want the peeked file in a new tab", len(r.tabs), r.activeTab)
+	}
+	if r.active().currentSource != "/docs/api.md" {
+		t.Errorf("new tab source = %q", r.active().currentSource)
+	}
+
+	r = press(t, r, "tab", "[", "p", "t")
+	if len(r.tabs) != 3 || r.active().currentSource != "/docs/guide.md" {
+		t.Fatalf("tabs = %d, source = %q; want the document in a new tab", len(r.tabs), r.active().currentSource)
+	}
+	if r.active().view.LineOffset() == 0 {
+		t.Error("expected the new tab to show the peeked section")
+	}
+}
+
+func TestUpdate_PeekErrors(t *testing.T) {
+	r := peekReader(t)
+	delete(r.fsys.(*memFS).files, "/docs/api.md")
+	r, _ = pressMark(t, r, "]", "]", "p")
+	if r.peek.err == nil {
+		t.Fatal("expected a load error")
+	}
+	if view := ansi.Strip(r.View().Content); !strings.Contains(view, "Error loading api.md#errors") {
+		t.Errorf("expected the error in the overlay:\n%s", view)
+	}
+
+	// Results of an earlier peek are ignored.
+	model, _ := r.Update(peekLoadedMsg{id: r.peek.id - 1, page: pageLoadedMsg{name: "Stale"}})
+	if model.(markdownReader).peek.loaded {
+		t.Error("expected a stale result to be ignored")
+	}
+
+	r = press(t, r, "p")
+	if r.peek.active {
+		t.Error("expected the peek key to close the overlay")
+	}
+	if r = press(t, peekReader(t), "p"); r.peek.active {
+		t.Error("expected no peek without a selected link")
+	}
+}
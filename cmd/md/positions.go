@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/markdown-kit/docsearch"
+)
+
+// prunePositionsMsg is sent when stale reading positions have been pruned.
+type prunePositionsMsg struct {
+	removed int
+	err     error
+}
+
+// savePosition records the reading position of a tab's document so that it
+// can be restored the next time the document is opened.
+func (r *markdownReader) savePosition(t *tab) {
+	if !r.resumePositions || r.searchIndex == nil || t.currentSource == "" || t.showSource {
+		return
+	}
+	anchor, offset := t.view.ReadingPosition()
+	pos := docsearch.Position{Anchor: anchor, Offset: offset}
+	if err := r.searchIndex.SavePosition(context.Background(), t.currentSource, pos); err != nil {
+		r.logger.Error("position_save_error", "source", t.currentSource, "error", err)
+	}
+}
+
+// saveAllPositions records the reading positions of every open tab.
+func (r *markdownReader) saveAllPositions() {
+	for i := range r.tabs {
+		r.savePosition(&r.tabs[i])
+	}
+}
+
+// restorePosition scrolls a tab's document to its saved reading position.
+func (r *markdownReader) restorePosition(t *tab) {
+	if !r.resumePositions || r.searchIndex == nil || t.currentSource == "" {
+		return
+	}
+	pos, ok, err := r.searchIndex.Position(context.Background(), t.currentSource)
+	if err != nil {
+		r.logger.Error("position_load_error", "source", t.currentSource, "error", err)
+		return
+	}
+	if ok {
+		t.view.SetReadingPosition(pos.Anchor, pos.Offset)
+	}
+}
+
+// prunePositions returns a tea.Cmd that removes the saved reading positions
+// of local files that no longer exist.
+func prunePositions(index *docsearch.Index, fsys fileSystem) tea.Cmd {
+	return func() tea.Msg {
+		removed, err := index.PrunePositions(context.Background(), func(path string) bool {
+			if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+				return true
+			}
+			_, err := fsys.Stat(path)
+			return !errors.Is(err, fs.ErrNotExist)
+		})
+		return prunePositionsMsg{removed: removed, err: err}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/markdown-kit/docsearch"
+)
+
+// positionReader returns a sized reader with a fresh document index and
+// reading positions enabled.
+func positionReader(t *testing.T, source string) (markdownReader, *docsearch.Index) {
+	t.Helper()
+	idx, err := docsearch.Open(filepath.Join(t.TempDir(), "index.db"), nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+
+	r := testReader("", markDoc("First"), source)
+	r.fsys = newMemFS()
+	r.searchIndex = idx
+	r.resumePositions = true
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	return model.(markdownReader), idx
+}
+
+func TestUpdate_ReadingPositionSavedAndRestored(t *testing.T) {
+	r, idx := positionReader(t, "/docs/a.md")
+	r.active().view.GotoSourceOffset(strings.Index(markDoc("First"), "End"))
+	wantAnchor, wantOffset := r.active().view.ReadingPosition()
+
+	// Following a link saves the position of the page being left.
+	model, _ := r.Update(pageLoadedMsg{markdown: markDoc("Second"), source: "/docs/b.md"})
+	r = model.(markdownReader)
+	pos, ok, err := idx.Position(context.Background(), "/docs/a.md")
+	if err != nil || !ok {
+		t.Fatalf("Position: ok=%v, err=%v", ok, err)
+	}
+	if pos.Anchor != wantAnchor || pos.Offset != wantOffset {
+		t.Errorf("saved position = (%q, %d), want (%q, %d)", pos.Anchor, pos.Offset, wantAnchor, wantOffset)
+	}
+
+	// Opening the page again restores it.
+	model, _ = r.Update(pageLoadedMsg{markdown: markDoc("First"), source: "/docs/a.md"})
+	r = model.(markdownReader)
+	anchor, offset := r.active().view.ReadingPosition()
+	if anchor != wantAnchor || offset != wantOffset {
+		t.Errorf("restored position = (%q, %d), want (%q, %d)", anchor, offset, wantAnchor, wantOffset)
+	}
+}
+
+func TestUpdate_ReadingPositionFragmentWins(t *testing.T) {
+	r, idx := positionReader(t, "/docs/a.md")
+	doc := markDoc("Second")
+	if err := idx.SavePosition(context.Background(), "/docs/b.md", docsearch.Position{Anchor: "end"}); err != nil {
+		t.Fatalf("SavePosition: %v", err)
+	}
+
+	model, _ := r.Update(pageLoadedMsg{markdown: doc, source: "/docs/b.md", fragment: "middle"})
+	r = model.(markdownReader)
+	if anchor, _ := r.active().view.ReadingPosition(); anchor == "end" {
+		t.Error("expected the fragment to take precedence over the saved position")
+	}
+}
+
+func TestUpdate_ReadingPositionDisabled(t *testing.T) {
+	r, idx := positionReader(t, "/docs/a.md")
+	r.resumePositions = false
+	r.active().view.GotoSourceOffset(strings.Index(markDoc("First"), "End"))
+
+	model, _ := r.Update(pageLoadedMsg{markdown: markDoc("Second"), source: "/docs/b.md"})
+	r = model.(markdownReader)
+	if _, ok, _ := idx.Position(context.Background(), "/docs/a.md"); ok {
+		t.Error("expected no saved position")
+	}
+
+	if err := idx.SavePosition(context.Background(), "/docs/c.md", docsearch.Position{Anchor: "end"}); err != nil {
+		t.Fatalf("SavePosition: %v", err)
+	}
+	model, _ = r.Update(pageLoadedMsg{markdown: markDoc("Third"), source: "/docs/c.md"})
+	r = model.(markdownReader)
+	if anchor, _ := r.active().view.ReadingPosition(); anchor == "end" {
+		t.Error("expected the position not to be restored")
+	}
+}
+
+func TestPrunePositions(t *testing.T) {
+	_, idx := positionReader(t, "")
+	ctx := context.Background()
+	for _, path := range []string{"/docs/kept.md", "/docs/gone.md", "https://example.com/doc.md"} {
+		if err := idx.SavePosition(ctx, path, docsearch.Position{}); err != nil {
+			t.Fatalf("SavePosition: %v", err)
+		}
+	}
+	fs := newMemFS()
+	fs.files["/docs/kept.md"] = []byte("# Kept\n")
+
+	msg := prunePositions(idx, fs)().(prunePositionsMsg)
+	if msg.err != nil {
+		t.Fatalf("prunePositions: %v", msg.err)
+	}
+	if msg.removed != 1 {
+		t.Errorf("removed = %d, want 1", msg.removed)
+	}
+	for path, want := range map[string]bool{"/docs/kept.md": true, "/docs/gone.md": false, "https://example.com/doc.md": true} {
+		if _, ok, _ := idx.Position(ctx, path); ok != want {
+			t.Errorf("position for %s present = %v, want %v", path, ok, want)
+		}
+	}
+}
@@ -17,9 +17,9 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/alecthomas/chroma"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/pgavlin/markdown-kit/docsearch"
+	"github.com/pgavlin/markdown-kit/markdown"
 	mdk "github.com/pgavlin/markdown-kit/view"
 	"github.com/pgavlin/picky"
 	"github.com/skratchdot/open-golang/open"
@@ -40,6 +40,8 @@ type readerKeyMap struct {
 	CloseTab              key.Binding
 	CloseAllTabs          key.Binding
 	NewTab                key.Binding
+	Peek                  key.Binding
+	CheckLinks            key.Binding
 	Reload                key.Binding
 	History               key.Binding
 	SearchDocuments       key.Binding
@@ -47,6 +49,20 @@ type readerKeyMap struct {
 	UserGuide             key.Binding
 	BugReport             key.Binding
 	ExportGist            key.Binding
+	ExportAnnotations     key.Binding
+	Themes                key.Binding
+	Bookmarks             key.Binding
+	GotoHeading           key.Binding
+	SearchTabs            key.Binding
+	SplitVertical         key.Binding
+	SplitHorizontal       key.Binding
+	SourceSync            key.Binding
+	Watch                 key.Binding
+	Present               key.Binding
+	RunCode               key.Binding
+	KillCode              key.Binding
+	SaveCode              key.Binding
+	SwitchPane            key.Binding
 	Help                  key.Binding
 	Quit                  key.Binding
 }
@@ -99,6 +115,14 @@ func defaultReaderKeyMap() readerKeyMap {
 			key.WithKeys("T"),
 			key.WithHelp("T", "open link in new tab"),
 		),
+		Peek: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "peek at link"),
+		),
+		CheckLinks: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "check links"),
+		),
 		Reload: key.NewBinding(
 			key.WithKeys("ctrl+r"),
 			key.WithHelp("ctrl+r", "reload"),
@@ -127,6 +151,62 @@ func defaultReaderKeyMap() readerKeyMap {
 			key.WithKeys("ctrl+g"),
 			key.WithHelp("ctrl+g", "export gist"),
 		),
+		ExportAnnotations: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "export annotations"),
+		),
+		Themes: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "choose theme"),
+		),
+		Bookmarks: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "bookmarks"),
+		),
+		GotoHeading: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "go to heading"),
+		),
+		SearchTabs: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "search open tabs"),
+		),
+		SplitVertical: key.NewBinding(
+			key.WithKeys("|"),
+			key.WithHelp("|", "split vertically"),
+		),
+		SplitHorizontal: key.NewBinding(
+			key.WithKeys("_"),
+			key.WithHelp("_", "split horizontally"),
+		),
+		SourceSync: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "source beside document"),
+		),
+		Watch: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "watch for changes"),
+		),
+		Present: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "present as slides"),
+		),
+		RunCode: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "run code block"),
+		),
+		KillCode: key.NewBinding(
+			key.WithKeys("ctrl+k"),
+			key.WithHelp("ctrl+k", "kill code block run"),
+		),
+		SaveCode: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+			key.WithHelp("ctrl+s", "save code block"),
+		),
+		SwitchPane: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "switch pane"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "toggle help"),
@@ -145,19 +225,23 @@ func (km readerKeyMap) ShortHelp() []key.Binding {
 
 // FullHelp returns the full set of key bindings for the expanded help view.
 // We build the layout from scratch (rather than appending to the view's
-// FullHelp) so that all 37 bindings fit into 5 balanced columns.
+// FullHelp) so that all 78 bindings fit into 7 balanced columns.
 func (km readerKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		// Movement
-		{km.Up, km.Down, km.PageUp, km.PageDown, km.GotoTop, km.GotoEnd, km.Left, km.Right},
+		{km.Up, km.Down, km.PageUp, km.PageDown, km.GotoTop, km.GotoEnd, km.Left, km.Right, km.Home, km.End, km.SetMark, km.JumpToMark},
 		// Navigation
-		{km.Home, km.End, km.NextLink, km.PrevLink, km.NextHeading, km.PrevHeading, km.NextCodeBlock, km.PrevCodeBlock},
+		{km.NextLink, km.PrevLink, km.NextHeading, km.PrevHeading, km.NextCodeBlock, km.PrevCodeBlock, km.Hint, km.ToggleFold, km.Fold, km.Unfold, km.ToggleAllFolds, km.FoldLevel},
 		// Actions
-		{km.FollowLink, km.GoBack, km.History, km.SearchDocuments, km.FindSimilar, km.Reload, km.CopySelection, km.OpenFile, km.OpenURL, km.OpenBrowser, km.DecreaseWidth, km.IncreaseWidth},
+		{km.FollowLink, km.GoBack, km.History, km.SearchDocuments, km.FindSimilar, km.Reload, km.CopySelection, km.CopyAs, km.OpenFile, km.OpenURL, km.OpenBrowser},
 		// Search & View
-		{km.Search, km.NextMatch, km.PrevMatch, km.ClearSearch, km.ToggleSource},
-		// Tabs & General
-		{km.NextTab, km.PrevTab, km.CloseTab, km.CloseAllTabs, km.NewTab, km.OpenFileNewTab, km.UserGuide, km.BugReport, km.ExportGist, km.Help, km.Quit},
+		{km.Search, km.NextMatch, km.PrevMatch, km.ClearSearch, km.ToggleTOC, km.FocusTOC, km.TOCSelect, km.TOCExpand, km.TOCCollapse, km.GotoHeading, km.ToggleSource},
+		// Documents & Annotations
+		{km.Bookmarks, km.Annotate, km.NextAnnotation, km.PrevAnnotation, km.DeleteAnnotation, km.ExportAnnotations, km.ExportGist, km.Peek, km.CheckLinks, km.Watch, km.Present},
+		// Tabs & Panes
+		{km.NextTab, km.PrevTab, km.CloseTab, km.CloseAllTabs, km.NewTab, km.OpenFileNewTab, km.SearchTabs, km.SplitVertical, km.SplitHorizontal, km.SourceSync, km.SwitchPane},
+		// Code & General
+		{km.RunCode, km.KillCode, km.SaveCode, km.DecreaseWidth, km.IncreaseWidth, km.Themes, km.UserGuide, km.BugReport, km.Help, km.Quit},
 	}
 }
 
@@ -169,20 +253,12 @@ func openInBrowser(url string, logger *slog.Logger) error {
 	return open.Run(url)
 }
 
-func sendToClipboard(value string, logger *slog.Logger) {
-	if !clipboard.Unsupported {
-		logger.Info("clipboard_write", "length", len(value))
-		clipboard.WriteAll(value)
-	}
-}
-
 // page stores the state of a viewed page for the back stack.
 type page struct {
-	name         string
-	markdown     string
-	source       string
-	lineOffset   int
-	columnOffset int
+	name     string
+	markdown string
+	source   string
+	state    mdk.Snapshot // the view's scroll position, selection, search, folds, etc.
 }
 
 // tab holds all per-document state for a single tab.
@@ -193,6 +269,7 @@ type tab struct {
 	showSource         bool
 	sourceOrigName     string
 	sourceOrigMarkdown string
+	syncSource         bool // the tab is the source pane of a source sync split
 }
 
 // displayName returns the tab's display name: the document heading if available,
@@ -212,9 +289,18 @@ type markdownReader struct {
 	tabs      []tab
 	activeTab int
 
+	// Split pane state.
+	split splitState
+
 	// Theme needed to create new tab views.
 	theme *chroma.Style
 
+	// Config file path and its theme setting, and the theme files loaded by
+	// the theme picker.
+	configPath   string
+	themeSetting string
+	themeFiles   themeFiles
+
 	// Options for creating new view models.
 	viewOpts []mdk.Option
 
@@ -270,9 +356,60 @@ type markdownReader struct {
 	showHistory   bool
 	historyPicker historyPicker
 
+	// Theme picker state. themeBefore is restored if the picker is dismissed.
+	showThemes  bool
+	themePicker themePicker
+	themeBefore *chroma.Style
+
+	// Bookmarks and per-document marks. nil if the data directory is
+	// unavailable.
+	bookmarks *bookmarkStore
+
+	// Annotations of documents. nil if annotations are not saved.
+	annotations *annotationStore
+
+	// Bookmark picker state.
+	showBookmarks  bool
+	bookmarkPicker bookmarkPicker
+
+	// Heading picker state.
+	showHeadings  bool
+	headingPicker headingPicker
+
+	// Open tab search state.
+	showTabSearch   bool
+	tabSearchPicker tabSearchPicker
+
+	// Link check state. If checkHTTPLinks is true, HTTP links are checked
+	// as well as local ones. Linked local files are parsed with parser.
+	checkingLinks  bool
+	checkHTTPLinks bool
+	parser         *markdown.Parser
+	showLinks      bool
+	linkPicker     linkPicker
+
+	// Watch mode state.
+	watch watchState
+
+	// Slide presentation state, and the settings of new presentations.
+	present          presentation
+	slideBreak       mdk.SlideBreak
+	incrementalLists bool
+	slideNotes       bool
+
+	// Code block runs and the save-to-file prompt.
+	codeRuns codeRunState
+
+	// Bookmark to jump to once its document has loaded.
+	pendingJump *bookmark
+
 	// Search index for document search.
 	searchIndex *docsearch.Index
 
+	// If true, reading positions are saved in the search index and restored
+	// when documents are reopened.
+	resumePositions bool
+
 	// Search picker state.
 	showSearch   bool
 	searchPicker searchPicker
@@ -281,11 +418,21 @@ type markdownReader struct {
 	showSimilar   bool
 	similarPicker searchPicker
 
+	// Link peek overlay state.
+	peek peekState
+
 	// Bug report state.
 	showBugReport    bool
 	bugReportInput   textinput.Model
 	bugReportCapture bugReportData
 
+	// Writes copied text to the clipboard. If nil, tea.SetClipboard is used.
+	clipboard func(text string) tea.Cmd
+
+	// If true, the reader captures the mouse. The views must be created with
+	// mouse support as well.
+	mouse bool
+
 	// Gist export state.
 	exportingGist bool
 
@@ -359,6 +506,18 @@ func newMarkdownReader(name, markdown, source string, theme *chroma.Style, viewO
 		spinner:     spinner.New(spinner.WithSpinner(spinner.Dot)),
 		picker:      fp,
 		searchIndex: searchIndex,
+		themeFiles:  themeFiles{},
+	}
+}
+
+// setTheme changes the theme of the reader and re-renders every tab with it.
+func (r *markdownReader) setTheme(theme *chroma.Style) {
+	if theme == nil || theme == r.theme {
+		return
+	}
+	r.theme = theme
+	for i := range r.tabs {
+		r.tabs[i].view.SetTheme(theme)
 	}
 }
 
@@ -377,30 +536,59 @@ func (r *markdownReader) viewHeight() int {
 
 // resizeAllViews updates the size of all tab views.
 func (r *markdownReader) resizeAllViews() {
-	vh := r.viewHeight()
 	for i := range r.tabs {
-		r.tabs[i].view.SetSize(r.width, vh)
+		r.tabs[i].view.SetSize(r.tabSize(i))
 	}
+	r.resizePeek()
+	r.resizePresentation()
 }
 
-// nextTab switches to the next tab (wrapping around).
+// nextTab switches to the next tab (wrapping around), skipping the tab shown
+// in the unfocused pane.
 func (r *markdownReader) nextTab() {
 	if len(r.tabs) <= 1 {
 		return
 	}
+	r.focusDocumentPane()
 	r.activeTab = (r.activeTab + 1) % len(r.tabs)
+	if r.split.layout != splitNone && r.activeTab == r.split.other {
+		r.activeTab = (r.activeTab + 1) % len(r.tabs)
+	}
 }
 
-// prevTab switches to the previous tab (wrapping around).
+// prevTab switches to the previous tab (wrapping around), skipping the tab
+// shown in the unfocused pane.
 func (r *markdownReader) prevTab() {
 	if len(r.tabs) <= 1 {
 		return
 	}
+	r.focusDocumentPane()
 	r.activeTab = (r.activeTab - 1 + len(r.tabs)) % len(r.tabs)
+	if r.split.layout != splitNone && r.activeTab == r.split.other {
+		r.activeTab = (r.activeTab - 1 + len(r.tabs)) % len(r.tabs)
+	}
 }
 
-// closeTab closes the tab at the given index.
+// closeTab closes the tab at the given index. Closing a tab shown in a pane
+// closes the split; closing the source pane of a source sync split only closes
+// the split.
 func (r *markdownReader) closeTab(idx int) {
+	if r.split.layout != splitNone && (idx == r.activeTab || idx == r.split.other) {
+		if r.tabs[idx].syncSource {
+			r.unsplit()
+			return
+		}
+		if r.split.sync {
+			// The source tab is removed with the split, which leaves the
+			// document's tab active.
+			r.unsplit()
+			idx = r.activeTab
+		} else {
+			r.unsplit()
+		}
+	}
+
+	r.savePosition(&r.tabs[idx])
 	if len(r.tabs) <= 1 {
 		// Last tab — reset to a blank tab and show the file picker.
 		r.tabs[0] = r.newTab()
@@ -410,17 +598,14 @@ func (r *markdownReader) closeTab(idx int) {
 		r.pickerURLMode = false
 		return
 	}
-	r.tabs = append(r.tabs[:idx], r.tabs[idx+1:]...)
-	if r.activeTab >= len(r.tabs) {
-		r.activeTab = len(r.tabs) - 1
-	} else if r.activeTab > idx {
-		r.activeTab--
-	}
+	r.removeTab(idx)
 	r.resizeAllViews()
 }
 
 // closeAllTabs closes all tabs and shows the file picker.
 func (r *markdownReader) closeAllTabs() {
+	r.saveAllPositions()
+	r.split = splitState{}
 	r.tabs = []tab{r.newTab()}
 	r.activeTab = 0
 	r.showPicker = true
@@ -431,10 +616,13 @@ func (r *markdownReader) closeAllTabs() {
 
 // openNewTab creates a new tab with the given content and makes it active.
 func (r *markdownReader) openNewTab(name, markdown, source string) {
+	r.focusDocumentPane()
 	hadOneTab := len(r.tabs) == 1
 	t := r.newTab()
 	t.view.SetText(name, markdown)
 	t.currentSource = source
+	r.restoreMarks(&t)
+	r.restoreAnnotations(&t)
 	r.tabs = append(r.tabs, t)
 	r.activeTab = len(r.tabs) - 1
 	if hadOneTab {
@@ -443,6 +631,19 @@ func (r *markdownReader) openNewTab(name, markdown, source string) {
 	}
 }
 
+// tabLabel returns the name shown in the tab bar for the tab at the given index.
+func (r *markdownReader) tabLabel(i int) string {
+	name := r.tabs[i].displayName()
+	if name == "" {
+		name = fmt.Sprintf("Tab %d", i+1)
+	}
+	// Truncate long names.
+	if ansi.StringWidth(name) > 20 {
+		name = ansi.Truncate(name, 17, "...")
+	}
+	return name
+}
+
 // renderTabBar renders the tab bar when multiple tabs are open.
 func (r *markdownReader) renderTabBar() string {
 	if len(r.tabs) <= 1 {
@@ -451,19 +652,15 @@ func (r *markdownReader) renderTabBar() string {
 
 	activeStyle := lipgloss.NewStyle().Bold(true).Foreground(colorTabActiveFg).Background(colorTabActiveBg).Padding(0, 1)
 	inactiveStyle := lipgloss.NewStyle().Faint(true).Padding(0, 1)
+	paneStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
 
 	var parts []string
 	for i := range r.tabs {
-		name := r.tabs[i].displayName()
-		if name == "" {
-			name = fmt.Sprintf("Tab %d", i+1)
-		}
-		// Truncate long names.
-		if ansi.StringWidth(name) > 20 {
-			name = ansi.Truncate(name, 17, "...")
-		}
+		name := r.tabLabel(i)
 		if i == r.activeTab {
 			parts = append(parts, activeStyle.Render(name))
+		} else if r.split.layout != splitNone && i == r.split.other {
+			parts = append(parts, paneStyle.Render(name))
 		} else {
 			parts = append(parts, inactiveStyle.Render(name))
 		}
@@ -487,6 +684,12 @@ func (r markdownReader) Init() tea.Cmd {
 		cmds = append(cmds, indexDocument(r.searchIndex, pi.path, pi.title, pi.markdown))
 		r.pendingIndex = nil
 	}
+	if r.resumePositions && r.searchIndex != nil {
+		cmds = append(cmds, prunePositions(r.searchIndex, r.fsys))
+	}
+	if r.watch.enabled {
+		cmds = append(cmds, watchTick(r.watch.gen))
+	}
 	return tea.Batch(cmds...)
 }
 
@@ -601,6 +804,18 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, cmd
 	}
 
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		if !r.mouse {
+			return r, nil
+		}
+		return r.handleMouse(mouse)
+	}
+
+	// Code block output keeps streaming while modals are open.
+	if run, ok := msg.(codeRunMsg); ok {
+		return r, r.updateCodeRun(run)
+	}
+
 	// Handle URL input modal.
 	if r.showURLInput {
 		if km, ok := msg.(tea.KeyPressMsg); ok {
@@ -634,7 +849,7 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				desc := r.bugReportInput.Value()
 				report := r.bugReportCapture.formatBugReport(desc)
 				r.showBugReport = false
-				return r, tea.SetClipboard(report)
+				return r, r.copyText(report)
 			}
 		}
 		var cmd tea.Cmd
@@ -642,6 +857,34 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, cmd
 	}
 
+	// Handle code block run confirmation.
+	if r.codeRuns.confirm != nil {
+		if km, ok := msg.(tea.KeyPressMsg); ok {
+			return r.handleRunConfirmKey(km)
+		}
+	}
+
+	// Handle save code block input modal.
+	if r.codeRuns.saving {
+		if km, ok := msg.(tea.KeyPressMsg); ok {
+			switch km.String() {
+			case "esc":
+				r.codeRuns.saving = false
+				return r, nil
+			case "enter":
+				name := r.codeRuns.saveInput.Value()
+				r.codeRuns.saving = false
+				if name != "" {
+					return r, r.saveCode(name)
+				}
+				return r, nil
+			}
+		}
+		var cmd tea.Cmd
+		r.codeRuns.saveInput, cmd = r.codeRuns.saveInput.Update(msg)
+		return r, cmd
+	}
+
 	// Handle history picker modal.
 	if r.showHistory {
 		var cmd tea.Cmd
@@ -655,16 +898,111 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if idx != -1 {
 				// Navigate to the selected stack entry.
 				at := r.active()
+				r.savePosition(at)
 				at.showSource = false
 				prev := at.pageStack[idx]
 				at.pageStack = at.pageStack[:idx]
-				at.view.SetText(prev.name, prev.markdown)
-				at.currentSource = prev.source
-				at.view.SetLineOffset(prev.lineOffset)
-				at.view.SetColumnOffset(prev.columnOffset)
+				r.restorePage(at, prev)
+			}
+			return r, nil
+		}
+		return r, cmd
+	}
+
+	// Handle theme picker modal.
+	if r.showThemes {
+		var cmd tea.Cmd
+		r.themePicker, cmd = r.themePicker.Update(msg)
+		if r.themePicker.dismissed {
+			r.showThemes = false
+			r.setTheme(r.themeBefore)
+			return r, nil
+		}
+		if didSelect, entry := r.themePicker.DidSelect(); didSelect {
+			r.showThemes = false
+			r.setTheme(entry.style)
+			r.themeSetting = entry.setting
+			if r.configPath != "" {
+				if err := saveThemeSetting(r.configPath, entry.setting, r.fsys); err != nil {
+					r.logger.Error("config_write_error", "path", r.configPath, "error", err)
+					r.showError = true
+					r.errorText = fmt.Sprintf("Error saving theme: %v", err)
+				}
 			}
 			return r, nil
 		}
+		// Preview the highlighted theme.
+		if entry, ok := r.themePicker.Highlighted(); ok {
+			r.setTheme(entry.style)
+		}
+		return r, cmd
+	}
+
+	// Handle bookmark picker modal.
+	if r.showBookmarks {
+		var cmd tea.Cmd
+		r.bookmarkPicker, cmd = r.bookmarkPicker.Update(msg)
+		if name := r.bookmarkPicker.deleted; name != "" {
+			r.bookmarkPicker.deleted = ""
+			r.bookmarks.remove(name)
+			r.saveBookmarks()
+		}
+		if r.bookmarkPicker.dismissed {
+			r.showBookmarks = false
+			return r, nil
+		}
+		if didSelect, b := r.bookmarkPicker.DidSelect(); didSelect {
+			r.showBookmarks = false
+			return r, r.jumpToBookmark(b)
+		}
+		return r, cmd
+	}
+
+	// Handle heading picker modal.
+	if r.showHeadings {
+		var cmd tea.Cmd
+		r.headingPicker, cmd = r.headingPicker.Update(msg)
+		if r.headingPicker.dismissed {
+			r.showHeadings = false
+			return r, nil
+		}
+		if didSelect, e := r.headingPicker.DidSelect(); didSelect {
+			r.showHeadings = false
+			r.gotoHeading(e)
+			return r, nil
+		}
+		return r, cmd
+	}
+
+	// Handle open tab search modal.
+	if r.showTabSearch {
+		var cmd tea.Cmd
+		r.tabSearchPicker, cmd = r.tabSearchPicker.Update(msg)
+		if r.tabSearchPicker.dismissed {
+			r.showTabSearch = false
+			return r, nil
+		}
+		if didSelect, row := r.tabSearchPicker.DidSelect(); didSelect {
+			r.showTabSearch = false
+			r.gotoTabSearchResult(r.tabSearchPicker.input.Value(), row)
+			return r, nil
+		}
+		return r, cmd
+	}
+
+	// Handle link check results modal.
+	if r.showLinks {
+		var cmd tea.Cmd
+		r.linkPicker, cmd = r.linkPicker.Update(msg)
+		if r.linkPicker.dismissed {
+			r.showLinks = false
+			return r, nil
+		}
+		if didSelect, res := r.linkPicker.DidSelect(); didSelect {
+			r.showLinks = false
+			r.gotoLink(res)
+			return r, nil
+		}
 		return r, cmd
 	}
 
@@ -714,35 +1052,89 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, cmd
 	}
 
+	// Handle presentation keys.
+	if r.present.active {
+		if km, ok := msg.(tea.KeyPressMsg); ok {
+			return r.handlePresentationKey(km)
+		}
+	}
+
+	// Handle link peek overlay keys.
+	if r.peek.active {
+		if km, ok := msg.(tea.KeyPressMsg); ok {
+			return r.handlePeekKey(km)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case mdk.OpenLinkMsg:
-		return r, r.handleLinkNavigation(msg.URL, false)
+		return r, r.handleLinkNavigation(msg.URL, msg.NewTab)
 
 	case mdk.GoBackMsg:
+		if r.active().syncSource {
+			return r, nil
+		}
 		r.active().showSource = false
 		r.popPage()
+		r.syncPanes()
 		return r, nil
 
+	case mdk.MarkSetMsg:
+		return r, r.setMark(msg)
+
+	case mdk.AnnotationsChangedMsg:
+		return r, r.saveAnnotations()
+
+	case mdk.JumpToMarkMsg:
+		return r, r.jumpToMark(msg.Name)
+
 	case pageLoadedMsg:
 		if msg.newTab {
 			r.openNewTab(msg.name, msg.markdown, msg.source)
 		} else {
+			if r.active().syncSource {
+				// Pages load into the document beside the source.
+				r.switchPane()
+			}
 			at := r.active()
-			at.showSource = false
+			if !msg.reload {
+				r.savePosition(at)
+			}
 			// Don't push to the stack on reload or when the page is empty.
 			if !msg.reload && (at.view.GetName() != "" || len(at.view.GetMarkdown()) > 0) {
 				r.pushCurrentPage()
 			}
-			at.view.SetText(msg.name, msg.markdown)
+			if msg.reload && !at.showSource {
+				// Keep the reader on the same content.
+				at.view.ReplaceText(msg.name, msg.markdown)
+				r.refreshPresentation()
+			} else {
+				at.view.SetText(msg.name, msg.markdown)
+			}
+			at.showSource = false
 			at.currentSource = msg.source
+			r.restoreMarks(at)
+			r.restoreAnnotations(at)
 		}
 		r.loading = false
 		r.loadingURL = ""
 
-		// Navigate to the fragment anchor if present.
+		// Navigate to the fragment anchor if present, or else resume reading
+		// where the document was left.
 		if msg.fragment != "" {
 			r.active().view.SelectAnchor(msg.fragment)
+		} else if !msg.reload && r.pendingJump == nil {
+			r.restorePosition(r.active())
+		}
+
+		// Jump to the bookmark that requested this page.
+		if jump := r.pendingJump; jump != nil {
+			r.pendingJump = nil
+			if jump.Source == msg.source {
+				r.active().view.GotoSourceOffset(jump.Offset)
+			}
 		}
+		r.syncPanes()
 
 		// Index the document in the background.
 		// Use the view's resolved name (extracted from the first heading)
@@ -757,6 +1149,33 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return r, nil
 
+	case peekLoadedMsg:
+		if r.peek.active && msg.id == r.peek.id {
+			r.peek.loaded = true
+			r.peek.page = msg.page
+			r.peek.view.SetText(msg.page.name, msg.page.markdown)
+			if msg.page.fragment != "" {
+				r.peek.view.SelectAnchor(msg.page.fragment)
+			}
+		}
+		return r, nil
+
+	case peekLoadErrorMsg:
+		if r.peek.active && msg.id == r.peek.id {
+			r.logger.Error("peek_load_error", "link", r.peek.link, "error", msg.err)
+			r.peek.err = msg.err
+		}
+		return r, nil
+
+	case linkCheckMsg:
+		return r, r.showLinkCheck(msg)
+
+	case watchTickMsg:
+		return r, r.checkWatched(msg)
+
+	case watchStatMsg:
+		return r, r.updateWatched(msg)
+
 	case findSimilarResultsMsg:
 		r.showSimilar = true
 		r.similarPicker = newSimilarPicker(
@@ -773,6 +1192,14 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		r.logger.Error("index_error", "error", msg.err)
 		return r, nil
 
+	case prunePositionsMsg:
+		if msg.err != nil {
+			r.logger.Error("position_prune_error", "error", msg.err)
+		} else if msg.removed > 0 {
+			r.logger.Info("positions_pruned", "count", msg.removed)
+		}
+		return r, nil
+
 	case gistExportMsg:
 		r.exportingGist = false
 		if msg.err != nil {
@@ -782,7 +1209,7 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		r.active().view.SetStatusMessage("Gist URL copied to clipboard")
 		return r, tea.Batch(
-			tea.SetClipboard(msg.url),
+			r.copyText(msg.url),
 			tea.Tick(3*time.Second, func(time.Time) tea.Msg { return clearStatusMsg{} }),
 		)
 
@@ -793,6 +1220,7 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case pageLoadErrorMsg:
 		r.loading = false
 		r.loadingURL = ""
+		r.pendingJump = nil
 		r.showError = true
 		r.errorURL = msg.url
 		r.errorText = fmt.Sprintf("Error loading %s: %v\n\nPress 'o' to open in browser", msg.url, msg.err)
@@ -810,6 +1238,7 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		r.width = msg.Width
 		r.height = msg.Height
 		r.resizeAllViews()
+		r.syncPanes()
 		r.helpModel.SetWidth(msg.Width)
 		r.picker.SetHeight(min(msg.Height-2, 20))
 		r.picker.SetWidth(msg.Width)
@@ -848,20 +1277,28 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		at := r.active()
 
-		// Defer to view during search input.
-		if at.view.Searching() {
+		// Defer to view during search input, while it shows link hints, the
+		// copy menu or a note prompt, or while it waits for a mark name.
+		if at.view.Searching() || at.view.Hinting() || at.view.MarkPending() || at.view.CopyMenuActive() || at.view.AnnotationInputActive() {
 			var cmd tea.Cmd
 			at.view, cmd = at.view.Update(msg)
+			r.syncPanes()
 			return r, cmd
 		}
 
 		switch msg.String() {
 		case "q", "ctrl+c":
+			r.saveAllPositions()
 			return r, tea.Quit
 		case "ctrl+u":
+			if at.syncSource {
+				return r, nil
+			}
 			if at.showSource {
 				at.view.SetText(at.sourceOrigName, at.sourceOrigMarkdown)
 				at.showSource = false
+				r.restoreMarks(at)
+				r.restoreAnnotations(at)
 			} else {
 				r.saveSourceState()
 				at.view.SetText(at.sourceOrigName, fenceSource(at.sourceOrigMarkdown))
@@ -980,6 +1417,58 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return r, r.bugReportInput.Focus()
 		}
 
+		if key.Matches(msg, r.keys.Themes) {
+			var configDir string
+			if r.configPath != "" {
+				configDir = filepath.Dir(r.configPath)
+			}
+			r.showThemes = true
+			r.themeBefore = r.theme
+			r.themePicker = newThemePicker(
+				themeEntries(configDir, r.themeSetting, r.themeFiles, r.fsys, r.logger),
+				r.themeSetting, r.theme,
+				min(r.height*3/4, 20), r.width*3/4,
+			)
+			return r, r.themePicker.input.Focus()
+		}
+
+		if key.Matches(msg, r.keys.Bookmarks) && r.bookmarks != nil {
+			r.showBookmarks = true
+			r.bookmarkPicker = newBookmarkPicker(
+				r.bookmarks.Bookmarks,
+				min(r.height*3/4, 20), r.width*3/4,
+			)
+			return r, r.bookmarkPicker.input.Focus()
+		}
+
+		if key.Matches(msg, r.keys.GotoHeading) {
+			var entries []headingEntry
+			for i := range r.tabs {
+				if !r.tabs[i].showSource {
+					entries = append(entries, headingEntries(&r.tabs[i], i)...)
+				}
+			}
+			r.showHeadings = true
+			r.headingPicker = newHeadingPicker(
+				entries, r.activeTab,
+				min(r.height*3/4, 24), r.width*3/4,
+			)
+			return r, r.headingPicker.input.Focus()
+		}
+
+		if key.Matches(msg, r.keys.SearchTabs) {
+			tabs := make([]*tab, len(r.tabs))
+			for i := range r.tabs {
+				tabs[i] = &r.tabs[i]
+			}
+			r.showTabSearch = true
+			r.tabSearchPicker = newTabSearchPicker(
+				tabs,
+				min(r.height*3/4, 24), r.width*3/4,
+			)
+			return r, r.tabSearchPicker.input.Focus()
+		}
+
 		if key.Matches(msg, r.keys.ExportGist) && !r.exportingGist {
 			r.exportingGist = true
 			at := r.active()
@@ -992,9 +1481,52 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return r, exportGist(markdown, filename)
 		}
 
+		if key.Matches(msg, r.keys.Peek) {
+			return r, r.startPeek()
+		}
+		if key.Matches(msg, r.keys.Present) {
+			return r, r.startPresentation()
+		}
+		if key.Matches(msg, r.keys.RunCode) {
+			return r, r.confirmCodeRun()
+		}
+		if key.Matches(msg, r.keys.KillCode) {
+			return r, r.killCodeRun()
+		}
+		if key.Matches(msg, r.keys.SaveCode) {
+			return r, r.startSaveCode()
+		}
+		if key.Matches(msg, r.keys.CheckLinks) && !r.checkingLinks {
+			return r, r.startLinkCheck()
+		}
+		if key.Matches(msg, r.keys.ExportAnnotations) {
+			return r, r.exportAnnotations()
+		}
+
+		if key.Matches(msg, r.keys.SplitVertical) {
+			r.toggleSplit(splitVertical)
+			return r, nil
+		}
+		if key.Matches(msg, r.keys.SplitHorizontal) {
+			r.toggleSplit(splitHorizontal)
+			return r, nil
+		}
+		if key.Matches(msg, r.keys.SourceSync) {
+			r.toggleSourceSync()
+			return r, nil
+		}
+		if key.Matches(msg, r.keys.Watch) {
+			return r, r.toggleWatch()
+		}
+		if key.Matches(msg, r.keys.SwitchPane) {
+			r.switchPane()
+			return r, nil
+		}
+
 		// Pass other keys to the view.
 		var cmd tea.Cmd
 		at.view, cmd = at.view.Update(msg)
+		r.syncPanes()
 		return r, cmd
 	}
 
@@ -1004,28 +1536,36 @@ func (r markdownReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // reloadCurrentPage re-fetches and re-converts the current tab's source.
 func (r *markdownReader) reloadCurrentPage() tea.Cmd {
 	source := r.active().currentSource
-	if source == "" {
+	reload := r.pageReloader(source)
+	if reload == nil {
 		return nil
 	}
 
 	r.loading = true
 	r.loadingURL = source
+	return tea.Batch(reload, r.spinner.Tick)
+}
+
+// pageReloader returns a command that reloads the page at source, or nil if
+// the page cannot be reloaded.
+func (r *markdownReader) pageReloader(source string) tea.Cmd {
+	if source == "" {
+		return nil
+	}
 
 	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
 		r.cache.evictHTTP(source, r.logger)
-		return tea.Batch(reloadURLPage(source, r.converter, r.registry, r.cache, r.client, r.logger), r.spinner.Tick)
+		return reloadURLPage(source, r.converter, r.registry, r.cache, r.client, r.logger)
 	}
 
 	if isMarkdownFile(source) {
-		return tea.Batch(reloadFilePage(source, r.fsys, r.logger), r.spinner.Tick)
+		return reloadFilePage(source, r.fsys, r.logger)
 	}
 
 	if isConvertibleFile(source, r.registry) {
-		return tea.Batch(reloadConvertFilePage(source, r.registry, r.cache, r.fsys, r.logger), r.spinner.Tick)
+		return reloadConvertFilePage(source, r.registry, r.cache, r.fsys, r.logger)
 	}
 
-	r.loading = false
-	r.loadingURL = ""
 	return nil
 }
 
@@ -1037,41 +1577,48 @@ func (r *markdownReader) handleLinkNavigation(rawURL string, newTab bool) tea.Cm
 	var fragment string
 	resolved, fragment = splitFragment(resolved)
 
-	// HTTP/HTTPS URLs: fetch and convert.
-	if strings.HasPrefix(resolved, "http://") || strings.HasPrefix(resolved, "https://") {
-		r.loading = true
-		r.loadingURL = resolved
-		return tea.Batch(fetchURLPage(resolved, fragment, newTab, r.converter, r.registry, r.cache, r.client, r.logger), r.spinner.Tick)
-	}
+	return r.loadPage(resolved, fragment, newTab)
+}
 
-	// Local markdown files.
-	if isMarkdownFile(resolved) {
-		r.loading = true
-		r.loadingURL = resolved
-		return tea.Batch(loadFilePage(resolved, fragment, newTab, r.fsys, r.logger), r.spinner.Tick)
+// loadPage loads the page at the given resolved path or URL.
+func (r *markdownReader) loadPage(resolved, fragment string, newTab bool) tea.Cmd {
+	load := r.pageLoader(resolved, fragment, newTab)
+	if load == nil {
+		// Non-markdown files, mailto:, etc. — open in browser.
+		openInBrowser(resolved, r.logger)
+		return nil
 	}
+	r.loading = true
+	r.loadingURL = resolved
+	return tea.Batch(load, r.spinner.Tick)
+}
 
-	// Local files with a registered converter.
-	if isConvertibleFile(resolved, r.registry) {
-		r.loading = true
-		r.loadingURL = resolved
-		return tea.Batch(loadConvertFilePage(resolved, fragment, newTab, r.registry, r.cache, r.fsys, r.logger), r.spinner.Tick)
+// pageLoader returns a command that loads the page at a resolved link, or nil
+// if the link does not name a page that can be shown.
+func (r *markdownReader) pageLoader(resolved, fragment string, newTab bool) tea.Cmd {
+	switch {
+	case strings.HasPrefix(resolved, "http://") || strings.HasPrefix(resolved, "https://"):
+		// HTTP/HTTPS URLs: fetch and convert.
+		return fetchURLPage(resolved, fragment, newTab, r.converter, r.registry, r.cache, r.client, r.logger)
+	case isMarkdownFile(resolved):
+		// Local markdown files.
+		return loadFilePage(resolved, fragment, newTab, r.fsys, r.logger)
+	case isConvertibleFile(resolved, r.registry):
+		// Local files with a registered converter.
+		return loadConvertFilePage(resolved, fragment, newTab, r.registry, r.cache, r.fsys, r.logger)
+	default:
+		return nil
 	}
-
-	// Non-markdown files, mailto:, etc. — open in browser.
-	openInBrowser(resolved, r.logger)
-	return nil
 }
 
 // pushCurrentPage saves the current page state onto the back stack.
 func (r *markdownReader) pushCurrentPage() {
 	at := r.active()
 	at.pageStack = append(at.pageStack, page{
-		name:         at.view.GetName(),
-		markdown:     string(at.view.GetMarkdown()),
-		source:       at.currentSource,
-		lineOffset:   at.view.LineOffset(),
-		columnOffset: at.view.ColumnOffset(),
+		name:     at.view.GetName(),
+		markdown: string(at.view.GetMarkdown()),
+		source:   at.currentSource,
+		state:    at.view.Snapshot(),
 	})
 }
 
@@ -1081,12 +1628,20 @@ func (r *markdownReader) popPage() {
 	if len(at.pageStack) == 0 {
 		return
 	}
+	r.savePosition(at)
 	prev := at.pageStack[len(at.pageStack)-1]
 	at.pageStack = at.pageStack[:len(at.pageStack)-1]
+	r.restorePage(at, prev)
+}
+
+// restorePage shows a page from the back stack in the given tab, in the state
+// it was left in.
+func (r *markdownReader) restorePage(at *tab, prev page) {
 	at.view.SetText(prev.name, prev.markdown)
 	at.currentSource = prev.source
-	at.view.SetLineOffset(prev.lineOffset)
-	at.view.SetColumnOffset(prev.columnOffset)
+	r.restoreMarks(at)
+	r.restoreAnnotations(at)
+	at.view.Restore(prev.state)
 }
 
 func (r markdownReader) View() tea.View {
@@ -1094,7 +1649,22 @@ func (r markdownReader) View() tea.View {
 		return tea.View{}
 	}
 
-	base := r.active().view.View()
+	if r.present.active {
+		v := tea.NewView(r.renderPresentation())
+		v.AltScreen = true
+		if r.mouse {
+			v.MouseMode = tea.MouseModeCellMotion
+		}
+		v.WindowTitle = r.present.name
+		return v
+	}
+
+	var base string
+	if r.split.layout != splitNone {
+		base = r.renderPanes()
+	} else {
+		base = r.active().view.View()
+	}
 
 	// Prepend tab bar when multiple tabs are open.
 	tabBar := r.renderTabBar()
@@ -1144,6 +1714,51 @@ func (r markdownReader) View() tea.View {
 		}
 		maxH := r.height * 3 / 4
 		result = r.renderFixedOverlay(base, historyView, fixedW, maxH)
+	} else if r.showThemes {
+		header := lipgloss.NewStyle().Bold(true).Render("Theme")
+		themeView := header + "\n\n" + r.themePicker.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		maxH := r.height * 3 / 4
+		result = r.renderFixedOverlay(base, themeView, fixedW, maxH)
+	} else if r.showBookmarks {
+		header := lipgloss.NewStyle().Bold(true).Render("Bookmarks")
+		bookmarkView := header + "\n\n" + r.bookmarkPicker.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		maxH := r.height * 3 / 4
+		result = r.renderFixedOverlay(base, bookmarkView, fixedW, maxH)
+	} else if r.showHeadings {
+		header := lipgloss.NewStyle().Bold(true).Render("Go to Heading")
+		headingView := header + "\n\n" + r.headingPicker.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		maxH := r.height * 3 / 4
+		result = r.renderFixedOverlay(base, headingView, fixedW, maxH)
+	} else if r.showTabSearch {
+		header := lipgloss.NewStyle().Bold(true).Render("Search Open Tabs")
+		tabSearchView := header + "\n\n" + r.tabSearchPicker.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		maxH := r.height * 3 / 4
+		result = r.renderFixedOverlay(base, tabSearchView, fixedW, maxH)
+	} else if r.showLinks {
+		header := lipgloss.NewStyle().Bold(true).Render("Link Check")
+		linkView := header + "\n\n" + r.linkPicker.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		maxH := r.height * 3 / 4
+		result = r.renderFixedOverlay(base, linkView, fixedW, maxH)
 	} else if r.showURLInput {
 		header := lipgloss.NewStyle().Bold(true).Render("Open URL")
 		inputView := header + "\n\n" + r.urlInput.View()
@@ -1160,6 +1775,18 @@ func (r markdownReader) View() tea.View {
 			fixedW = min(r.width-4, 40)
 		}
 		result = r.renderFixedOverlay(base, inputView, fixedW, 5)
+	} else if r.codeRuns.confirm != nil {
+		result = r.renderRunConfirm(base)
+	} else if r.codeRuns.saving {
+		header := lipgloss.NewStyle().Bold(true).Render("Save Code Block")
+		inputView := header + "\n\n" + r.codeRuns.saveInput.View()
+		fixedW := r.width * 3 / 4
+		if fixedW < 40 {
+			fixedW = min(r.width-4, 40)
+		}
+		result = r.renderFixedOverlay(base, inputView, fixedW, 5)
+	} else if r.peek.active {
+		result = r.renderPeek(base)
 	} else if r.loading {
 		loadingText := r.spinner.View() + " Loading..."
 		if r.loadingURL != "" {
@@ -1184,6 +1811,9 @@ func (r markdownReader) View() tea.View {
 
 	v := tea.NewView(result)
 	v.AltScreen = true
+	if r.mouse {
+		v.MouseMode = tea.MouseModeCellMotion
+	}
 	v.WindowTitle = r.active().displayName()
 	return v
 }
@@ -54,6 +54,8 @@ func keyMsg(s string) tea.KeyPressMsg {
 		return tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl}
 	case "ctrl+w":
 		return tea.KeyPressMsg{Code: 'w', Mod: tea.ModCtrl}
+	case "ctrl+x":
+		return tea.KeyPressMsg{Code: 'x', Mod: tea.ModCtrl}
 	case "tab":
 		return tea.KeyPressMsg{Code: tea.KeyTab}
 	case "shift+tab":
@@ -164,8 +166,8 @@ func TestShortHelp(t *testing.T) {
 func TestFullHelp(t *testing.T) {
 	km := defaultReaderKeyMap()
 	groups := km.FullHelp()
-	if len(groups) != 5 {
-		t.Fatalf("expected 5 help groups, got %d", len(groups))
+	if len(groups) != 7 {
+		t.Fatalf("expected 7 help groups, got %d", len(groups))
 	}
 	// Columns range from 5-12 items each.
 	for i, g := range groups {
@@ -529,6 +531,39 @@ func TestUpdate_OpenLinkMsg(t *testing.T) {
 	}
 }
 
+func TestUpdate_LinkHints(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/docs/other.md"] = []byte("# Other")
+
+	r := testReader("test", "# Hello\n\nSee [other](other.md).\n", "/docs/test.md")
+	r.fsys = fs
+	m, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m, _ = m.Update(keyMsg("f"))
+	reader := m.(markdownReader)
+	if !reader.active().view.Hinting() {
+		t.Fatal("expected link hints to be shown")
+	}
+
+	// Hint labels go to the view, even where they are reader keys; typing a
+	// label in uppercase opens its link in a new tab.
+	m, cmd := m.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModShift, Text: "S"})
+	if m.(markdownReader).showSearch {
+		t.Fatal("expected the hint label not to open document search")
+	}
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	msg, ok := cmd().(mdk.OpenLinkMsg)
+	if !ok || msg.URL != "other.md" || !msg.NewTab {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+
+	m, _ = m.Update(msg)
+	if !m.(markdownReader).loading {
+		t.Error("expected the link to be loading")
+	}
+}
+
 func TestUpdate_UnknownMsg(t *testing.T) {
 	r := testReader("test", "# Hello", "")
 	type unknownMsg struct{}
@@ -685,6 +720,31 @@ func TestPushPopPage(t *testing.T) {
 	}
 }
 
+func TestPopPage_RestoresViewState(t *testing.T) {
+	doc := "# Page 1\n\nSee [a link](https://example.com).\n\n" + strings.Repeat("Some text.\n\n", 60)
+	r := testReader("page1", doc, "/page1.md")
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	r = press(t, model.(markdownReader), "]", "/", "t", "e", "x", "t", "enter")
+	r.active().view.ScrollDown(10)
+	top := r.active().view.SourceOffset()
+
+	r.pushCurrentPage()
+	r.active().view.SetText("page2", "# Page 2")
+	r.active().currentSource = "/page2.md"
+	r.popPage()
+
+	v := &r.active().view
+	if got := v.SourceOffset(); got != top {
+		t.Errorf("top = %d, want %d", got, top)
+	}
+	if v.FocusedLinkDestination() != "https://example.com" {
+		t.Error("expected the link to be selected again")
+	}
+	if _, total := v.SearchPosition(); total == 0 {
+		t.Error("expected the search to be restored")
+	}
+}
+
 func TestPopPage_EmptyStack(t *testing.T) {
 	r := testReader("test", "# Hello", "/test.md")
 	// Should not panic on empty stack.
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// defaultInterpreters maps code block languages to the commands that run
+// them. The code is passed to the command in a file named by its last
+// argument.
+var defaultInterpreters = map[string][]string{
+	"sh":     {"sh"},
+	"shell":  {"sh"},
+	"bash":   {"bash"},
+	"zsh":    {"zsh"},
+	"python": {"python3"},
+	"py":     {"python3"},
+}
+
+const (
+	// codeRunWaitDelay is how long a run waits for the output of processes
+	// that its command started after the command itself has exited or been
+	// killed.
+	codeRunWaitDelay = time.Second
+	// maxCodeOutput is the number of bytes at the end of a run's output that
+	// are shown; earlier output is dropped.
+	maxCodeOutput = 64 << 10
+	// codeOutputInterval is how long output is collected before it is shown,
+	// so that a command that writes often does not re-render its output for
+	// every write.
+	codeOutputInterval = 100 * time.Millisecond
+)
+
+// codeExtensions maps code block languages to the extensions of the files
+// they are saved to.
+var codeExtensions = map[string]string{
+	"sh":         ".sh",
+	"shell":      ".sh",
+	"bash":       ".sh",
+	"zsh":        ".sh",
+	"python":     ".py",
+	"py":         ".py",
+	"go":         ".go",
+	"javascript": ".js",
+	"js":         ".js",
+	"typescript": ".ts",
+	"ts":         ".ts",
+	"ruby":       ".rb",
+	"rust":       ".rs",
+}
+
+// codeRunState holds the runs of code blocks and the save-to-file prompt.
+type codeRunState struct {
+	// Code blocks of documents below dirs may run with interpreters.
+	dirs         []string
+	interpreters map[string][]string
+
+	confirm *codeRun         // the run awaiting confirmation
+	running map[int]*codeRun // runs by ID
+	nextID  int
+
+	saving    bool
+	saveInput textinput.Model
+	saveBlock mdk.CodeBlock
+	saveDir   string
+}
+
+// codeRun is a run of a code block.
+type codeRun struct {
+	id       int
+	source   string // the document containing the code block
+	markdown string // the text of the document when the run started
+	block    mdk.CodeBlock
+	command  []string
+	dir      string
+
+	output codeRunOutput
+	events chan codeRunMsg
+
+	// cancel kills the run's command, and done is closed once the command
+	// has exited.
+	cancel context.CancelFunc
+	done   chan struct{}
+	killed bool
+}
+
+// codeRunMsg reports output of a code block run, or its end. If truncated is
+// true, output before text was dropped.
+type codeRunMsg struct {
+	id        int
+	text      string
+	truncated bool
+	done      bool
+	err       error
+}
+
+// codeRunOutput holds the end of a run's output.
+type codeRunOutput struct {
+	text      []byte
+	truncated bool
+}
+
+// WriteString appends s to the output. Output before the last maxCodeOutput
+// bytes is dropped once the output grows to twice that size.
+func (o *codeRunOutput) WriteString(s string) {
+	o.text = append(o.text, s...)
+	if len(o.text) > 2*maxCodeOutput {
+		o.text = append(o.text[:0], o.text[len(o.text)-maxCodeOutput:]...)
+		o.truncated = true
+	}
+}
+
+// String returns the last maxCodeOutput bytes of the output, starting at a
+// line, after a note if earlier output was dropped.
+func (o *codeRunOutput) String() string {
+	text, truncated := o.text, o.truncated
+	if len(text) > maxCodeOutput {
+		text, truncated = text[len(text)-maxCodeOutput:], true
+	}
+	if !truncated {
+		return string(text)
+	}
+	if i := bytes.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	return "[earlier output truncated]\n" + string(text)
+}
+
+// codeRunWriter sends the output written to it as codeRunMsgs until its run
+// is cancelled.
+type codeRunWriter struct {
+	ctx    context.Context
+	id     int
+	events chan<- codeRunMsg
+}
+
+func (w codeRunWriter) Write(p []byte) (int, error) {
+	select {
+	case w.events <- codeRunMsg{id: w.id, text: string(p)}:
+		return len(p), nil
+	case <-w.ctx.Done():
+		return 0, w.ctx.Err()
+	}
+}
+
+// runAllowed reports whether documents in dir may run their code blocks.
+func (s *codeRunState) runAllowed(dir string) bool {
+	for _, allowed := range s.dirs {
+		rel, err := filepath.Rel(allowed, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// runningBlock returns the run of the given code block of the given
+// document, if it is running.
+func (s *codeRunState) runningBlock(source string, index int) (*codeRun, bool) {
+	for _, run := range s.running {
+		if run.source == source && run.block.Index == index {
+			return run, true
+		}
+	}
+	return nil, false
+}
+
+// killCodeRun kills the run of the focused code block of the active tab.
+func (r *markdownReader) killCodeRun() tea.Cmd {
+	at := r.active()
+	block, ok := at.view.FocusedCodeBlock()
+	if !ok || at.showSource || !isLocalSource(at.currentSource) {
+		return r.flashStatus("No code block selected")
+	}
+	source, err := filepath.Abs(at.currentSource)
+	if err != nil {
+		return r.flashStatus("The code block is not running")
+	}
+	run, ok := r.codeRuns.runningBlock(source, block.Index)
+	if !ok {
+		return r.flashStatus("The code block is not running")
+	}
+	run.killed = true
+	run.cancel()
+	r.logger.Info("kill_code_block", "source", run.source, "index", run.block.Index)
+	return nil
+}
+
+// cancelAll kills every run and waits up to codeRunWaitDelay for their
+// commands to exit. It is called when md quits, so that no command outlives
+// it.
+func (s *codeRunState) cancelAll() {
+	for _, run := range s.running {
+		run.cancel()
+	}
+	timeout := time.After(codeRunWaitDelay)
+	for _, run := range s.running {
+		select {
+		case <-run.done:
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// confirmCodeRun asks to run the focused code block of the active tab, if
+// running it is allowed.
+func (r *markdownReader) confirmCodeRun() tea.Cmd {
+	at := r.active()
+	block, ok := at.view.FocusedCodeBlock()
+	if !ok || at.showSource {
+		return r.flashStatus("No code block selected")
+	}
+	if !isLocalSource(at.currentSource) {
+		return r.flashStatus("Only code blocks of local files can run")
+	}
+	source, err := filepath.Abs(at.currentSource)
+	if err != nil {
+		return r.flashStatus(fmt.Sprintf("Cannot run code block: %v", err))
+	}
+	dir := filepath.Dir(source)
+	if !r.codeRuns.runAllowed(dir) {
+		return r.flashStatus(fmt.Sprintf("Running code blocks is not allowed in %s", dir))
+	}
+	command, ok := r.codeRuns.interpreters[strings.ToLower(block.Language)]
+	if !ok {
+		if block.Language == "" {
+			return r.flashStatus("No interpreter for code blocks without a language")
+		}
+		return r.flashStatus(fmt.Sprintf("No interpreter for %s", block.Language))
+	}
+	if _, ok := r.codeRuns.runningBlock(source, block.Index); ok {
+		return r.flashStatus("The code block is already running")
+	}
+
+	r.codeRuns.confirm = &codeRun{
+		source:   source,
+		markdown: string(at.view.GetMarkdown()),
+		block:    block,
+		command:  command,
+		dir:      dir,
+	}
+	return nil
+}
+
+// handleRunConfirmKey handles a key press while a run awaits confirmation.
+func (r markdownReader) handleRunConfirmKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "enter":
+		run := r.codeRuns.confirm
+		r.codeRuns.confirm = nil
+		return r, r.startCodeRun(run)
+	case "n", "esc", "q":
+		r.codeRuns.confirm = nil
+	case "ctrl+c":
+		return r, tea.Quit
+	}
+	return r, nil
+}
+
+// startCodeRun starts a confirmed run.
+func (r *markdownReader) startCodeRun(run *codeRun) tea.Cmd {
+	r.codeRuns.nextID++
+	run.id = r.codeRuns.nextID
+	run.events = make(chan codeRunMsg, 64)
+	run.done = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	run.cancel = cancel
+	if r.codeRuns.running == nil {
+		r.codeRuns.running = map[int]*codeRun{}
+	}
+	r.codeRuns.running[run.id] = run
+	r.showCodeOutput(run, mdk.CodeOutput{Status: "running"})
+
+	r.logger.Info("run_code_block", "source", run.source, "index", run.block.Index, "command", run.command)
+	id, events, done, command, dir, code := run.id, run.events, run.done, run.command, run.dir, run.block.Code
+	return func() tea.Msg {
+		if err := execCodeBlock(ctx, id, events, done, command, dir, code); err != nil {
+			close(done)
+			return codeRunMsg{id: id, done: true, err: err}
+		}
+		return waitCodeRun(events)()
+	}
+}
+
+// execCodeBlock starts the given command on a temporary file holding code. Its
+// combined stdout and stderr are sent to events, followed by its end; done is
+// closed when it exits. Cancelling ctx kills the command and the processes it
+// started.
+func execCodeBlock(ctx context.Context, id int, events chan<- codeRunMsg, done chan<- struct{}, command []string, dir, code string) error {
+	f, err := os.CreateTemp("", "md-run-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(code); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+
+	args := append(append([]string{}, command[1:]...), f.Name())
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	cmd.Dir = dir
+	cmd.WaitDelay = codeRunWaitDelay
+	killProcessGroup(cmd)
+	w := codeRunWriter{ctx: ctx, id: id, events: events}
+	cmd.Stdout, cmd.Stderr = w, w
+	if err := cmd.Start(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	go func() {
+		err := cmd.Wait()
+		os.Remove(f.Name())
+		close(done)
+		events <- codeRunMsg{id: id, done: true, err: err}
+		close(events)
+	}()
+	return nil
+}
+
+// waitCodeRun returns a command that waits for the next output of a run. The
+// output written within codeOutputInterval of it is sent together.
+func waitCodeRun(events <-chan codeRunMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg := <-events
+		if msg.done {
+			return msg
+		}
+
+		out := codeRunOutput{text: []byte(msg.text)}
+		timer := time.NewTimer(codeOutputInterval)
+		defer timer.Stop()
+	collect:
+		for !msg.done {
+			select {
+			case next := <-events:
+				out.WriteString(next.text)
+				msg.done, msg.err = next.done, next.err
+			case <-timer.C:
+				break collect
+			}
+		}
+		msg.text, msg.truncated = string(out.text), out.truncated
+		return msg
+	}
+}
+
+// updateCodeRun shows the output of a run under its code block.
+func (r *markdownReader) updateCodeRun(msg codeRunMsg) tea.Cmd {
+	run, ok := r.codeRuns.running[msg.id]
+	if !ok {
+		return nil
+	}
+	run.output.WriteString(msg.text)
+	run.output.truncated = run.output.truncated || msg.truncated
+	out := mdk.CodeOutput{Text: run.output.String(), Status: "running"}
+	if !msg.done {
+		r.showCodeOutput(run, out)
+		return waitCodeRun(run.events)
+	}
+
+	delete(r.codeRuns.running, msg.id)
+	run.cancel()
+	out.Status, out.Failed = "exit status 0", msg.err != nil
+	var exitErr *exec.ExitError
+	switch {
+	case run.killed:
+		out.Status, out.Failed = "killed", true
+	case errors.As(msg.err, &exitErr):
+		out.Status = exitErr.Error()
+	case msg.err != nil:
+		out.Status = fmt.Sprintf("failed: %v", msg.err)
+	}
+	r.logger.Info("run_code_block_done", "source", run.source, "index", run.block.Index, "status", out.Status)
+	r.showCodeOutput(run, out)
+	return nil
+}
+
+// showCodeOutput shows output under the run's code block in every tab that
+// shows the document the run started from.
+func (r *markdownReader) showCodeOutput(run *codeRun, out mdk.CodeOutput) {
+	for i := range r.tabs {
+		t := &r.tabs[i]
+		if t.showSource || !isLocalSource(t.currentSource) {
+			continue
+		}
+		if source, err := filepath.Abs(t.currentSource); err != nil || source != run.source {
+			continue
+		}
+		if string(t.view.GetMarkdown()) == run.markdown {
+			t.view.SetCodeOutput(run.block.Index, out)
+		}
+	}
+}
+
+// renderRunConfirm renders the run confirmation prompt over base.
+func (r markdownReader) renderRunConfirm(base string) string {
+	run := r.codeRuns.confirm
+	header := lipgloss.NewStyle().Bold(true).Render("Run Code Block")
+	text := fmt.Sprintf("%s\n\nRun this %s code block with %s\nin %s?\n\ny: run • n: cancel",
+		header, run.block.Language, strings.Join(run.command, " "), run.dir)
+	return r.overlayDialog(base, "Run Code Block", text)
+}
+
+// codeFileName returns the default name of the file that the given code block
+// of the given document is saved to.
+func codeFileName(source string, block mdk.CodeBlock) string {
+	ext, ok := codeExtensions[strings.ToLower(block.Language)]
+	if !ok {
+		ext = ".txt"
+	}
+	stem := "snippet"
+	if source != "" {
+		base := filepath.Base(source)
+		stem = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return fmt.Sprintf("%s-%d%s", stem, block.Index+1, ext)
+}
+
+// startSaveCode prompts for the file that the focused code block of the
+// active tab is saved to.
+func (r *markdownReader) startSaveCode() tea.Cmd {
+	at := r.active()
+	block, ok := at.view.FocusedCodeBlock()
+	if !ok || at.showSource {
+		return r.flashStatus("No code block selected")
+	}
+
+	dir, err := r.fsys.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	source := ""
+	if isLocalSource(at.currentSource) {
+		source = at.currentSource
+		dir = filepath.Dir(source)
+	}
+
+	r.codeRuns.saving = true
+	r.codeRuns.saveBlock = block
+	r.codeRuns.saveDir = dir
+	r.codeRuns.saveInput = textinput.New()
+	r.codeRuns.saveInput.Prompt = "  File: "
+	r.codeRuns.saveInput.SetValue(codeFileName(source, block))
+	fixedW := r.width * 3 / 4
+	if fixedW < 40 {
+		fixedW = min(r.width-4, 40)
+	}
+	innerW := fixedW - 4 // account for border + padding
+	r.codeRuns.saveInput.SetWidth(innerW - lipgloss.Width(r.codeRuns.saveInput.Prompt) - 1)
+	return r.codeRuns.saveInput.Focus()
+}
+
+// saveCode saves the code block to the named file, relative to the directory
+// of its document. Existing files are not overwritten.
+func (r *markdownReader) saveCode(name string) tea.Cmd {
+	path := name
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.codeRuns.saveDir, path)
+	}
+	if _, err := r.fsys.Stat(path); err == nil {
+		return r.flashStatus(fmt.Sprintf("%s already exists", path))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error saving code block: %v", err)
+		return nil
+	}
+	if err := r.fsys.WriteFile(path, []byte(r.codeRuns.saveBlock.Code), 0o644); err != nil {
+		r.showError = true
+		r.errorText = fmt.Sprintf("Error saving code block: %v", err)
+		return nil
+	}
+	r.logger.Info("save_code_block", "path", path)
+	return r.flashStatus(fmt.Sprintf("Saved code block to %s", path))
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const runDoc = "# Run\n\nSome text.\n\n```sh\necho hello\necho oops >&2\nexit 3\n```\n"
+
+// runReader returns a sized reader for a local file with its code block
+// selected. Code blocks of documents below allow may run.
+func runReader(t *testing.T, dir string, allow ...string) markdownReader {
+	t.Helper()
+	path := filepath.Join(dir, "run.md")
+	r := testReader("", runDoc, path)
+	r.codeRuns.dirs = runConfig{Allow: allow}.allowedDirs("")
+	r.codeRuns.interpreters = runConfig{}.interpreters()
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	r = press(t, model.(markdownReader), "ctrl+]")
+	if _, ok := r.active().view.FocusedCodeBlock(); !ok {
+		t.Fatal("expected the code block to be selected")
+	}
+	return r
+}
+
+func TestRunConfig_Interpreters(t *testing.T) {
+	interpreters := runConfig{Interpreters: map[string][]string{
+		"Ruby":   {"ruby", "-w"},
+		"python": {"python3.12"},
+		"zsh":    {},
+	}}.interpreters()
+	if got := interpreters["ruby"]; !reflect.DeepEqual(got, []string{"ruby", "-w"}) {
+		t.Errorf("ruby = %v", got)
+	}
+	if got := interpreters["python"]; !reflect.DeepEqual(got, []string{"python3.12"}) {
+		t.Errorf("python = %v", got)
+	}
+	if _, ok := interpreters["zsh"]; ok {
+		t.Error("expected an empty command to remove zsh")
+	}
+	if got := interpreters["bash"]; !reflect.DeepEqual(got, []string{"bash"}) {
+		t.Errorf("bash = %v", got)
+	}
+}
+
+func TestRunCode_OffByDefault(t *testing.T) {
+	r := runReader(t, t.TempDir())
+	r = press(t, r, "ctrl+e")
+	if r.codeRuns.confirm != nil {
+		t.Fatal("expected running code blocks to be disabled")
+	}
+}
+
+func TestRunCode_AllowedBelowDirectory(t *testing.T) {
+	root := t.TempDir()
+	r := runReader(t, filepath.Join(root, "notes", "sub"), root)
+	r = press(t, r, "ctrl+e")
+	if r.codeRuns.confirm == nil {
+		t.Fatal("expected a confirmation prompt")
+	}
+	if !strings.Contains(ansi.Strip(r.View().Content), "Run this sh code block") {
+		t.Error("expected the prompt to be shown")
+	}
+	r = press(t, r, "n")
+	if r.codeRuns.confirm != nil || len(r.codeRuns.running) != 0 {
+		t.Error("expected the run to be cancelled")
+	}
+}
+
+func TestRunCode_StreamsOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	r := runReader(t, dir, dir)
+	r = press(t, r, "ctrl+e")
+	model, cmd := r.Update(keyMsg("y"))
+	if cmd == nil {
+		t.Fatal("expected the run to start")
+	}
+	for cmd != nil {
+		model, cmd = model.Update(cmd())
+	}
+	r = model.(markdownReader)
+
+	if len(r.codeRuns.running) != 0 {
+		t.Error("expected the run to be done")
+	}
+	text := ansi.Strip(r.active().view.View())
+	for _, want := range []string{"output (exit status 3)", "│ hello", "│ oops"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestSaveCode(t *testing.T) {
+	r := runReader(t, "/docs")
+	r = press(t, r, "ctrl+s")
+	if !r.codeRuns.saving {
+		t.Fatal("expected the save prompt")
+	}
+	if got := r.codeRuns.saveInput.Value(); got != "run-1.sh" {
+		t.Errorf("file name = %q", got)
+	}
+	r = press(t, r, "enter")
+	files := r.fsys.(*memFS).files
+	if got := string(files["/docs/run-1.sh"]); got != "echo hello\necho oops >&2\nexit 3\n" {
+		t.Fatalf("saved code = %q", got)
+	}
+
+	// Existing files are not overwritten.
+	files["/docs/run-1.sh"] = []byte("keep")
+	r = press(t, r, "ctrl+s", "enter")
+	if got := string(files["/docs/run-1.sh"]); got != "keep" {
+		t.Errorf("expected the existing file to be kept, got %q", got)
+	}
+}
+
+// startSleepRun starts running a code block that writes its first output and
+// then sleeps. It returns the reader once the first output has been shown, and
+// the command that waits for the rest of the run.
+func startSleepRun(t *testing.T) (markdownReader, tea.Cmd) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	r := testReader("", "# Run\n\n```sh\necho started\nsleep 60\n```\n", filepath.Join(dir, "run.md"))
+	r.codeRuns.dirs = runConfig{Allow: []string{dir}}.allowedDirs("")
+	r.codeRuns.interpreters = runConfig{}.interpreters()
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	r = press(t, model.(markdownReader), "ctrl+]", "ctrl+e")
+	model, cmd := r.Update(keyMsg("y"))
+	if cmd == nil {
+		t.Fatal("expected the run to start")
+	}
+	model, cmd = model.Update(cmd())
+	if len(model.(markdownReader).codeRuns.running) != 1 {
+		t.Fatal("expected the code block to be running")
+	}
+	return model.(markdownReader), cmd
+}
+
+func TestRunCode_Kill(t *testing.T) {
+	r, cmd := startSleepRun(t)
+
+	r = press(t, r, "ctrl+k")
+	start := time.Now()
+	var model tea.Model = r
+	for cmd != nil {
+		model, cmd = model.Update(cmd())
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("expected the command to be killed")
+	}
+	r = model.(markdownReader)
+	if len(r.codeRuns.running) != 0 {
+		t.Error("expected the run to be done")
+	}
+	if text := ansi.Strip(r.active().view.View()); !strings.Contains(text, "output (killed)") {
+		t.Errorf("expected the run to be reported as killed in:\n%s", text)
+	}
+}
+
+func TestRunCode_CancelAll(t *testing.T) {
+	r, _ := startSleepRun(t)
+
+	var done []chan struct{}
+	for _, run := range r.codeRuns.running {
+		done = append(done, run.done)
+	}
+	r.codeRuns.cancelAll()
+	for _, d := range done {
+		select {
+		case <-d:
+		default:
+			t.Error("expected cancelAll to kill the command")
+		}
+	}
+}
+
+func TestCodeRunOutput_Truncates(t *testing.T) {
+	var out codeRunOutput
+	line := strings.Repeat("x", 99) + "\n"
+	for i := 0; i < 3*maxCodeOutput/len(line); i++ {
+		out.WriteString(line)
+	}
+	if len(out.text) > 2*maxCodeOutput {
+		t.Errorf("expected at most %d bytes to be kept, got %d", 2*maxCodeOutput, len(out.text))
+	}
+	text := out.String()
+	rest, ok := strings.CutPrefix(text, "[earlier output truncated]\n")
+	if !ok {
+		t.Fatalf("expected a truncation note, got %q", text[:40])
+	}
+	if len(rest) > maxCodeOutput || !strings.HasPrefix(rest, line) {
+		t.Errorf("expected whole lines of at most %d bytes, got %d bytes", maxCodeOutput, len(rest))
+	}
+
+	var short codeRunOutput
+	short.WriteString("hello\n")
+	if got := short.String(); got != "hello\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestWaitCodeRun_Coalesces(t *testing.T) {
+	events := make(chan codeRunMsg, 4)
+	events <- codeRunMsg{id: 1, text: "one\n"}
+	events <- codeRunMsg{id: 1, text: "two\n"}
+	go func() {
+		time.Sleep(codeOutputInterval / 4)
+		events <- codeRunMsg{id: 1, text: "three\n"}
+	}()
+
+	msg := waitCodeRun(events)().(codeRunMsg)
+	if msg.text != "one\ntwo\nthree\n" || msg.done {
+		t.Errorf("msg = %+v", msg)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: cancelling it kills only its own
+// process.
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -282,6 +282,16 @@ func (sp searchPicker) View() string {
 	return s.String()
 }
 
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (sp *searchPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 2, sp.minIdx, sp.maxIdx, len(sp.results))
+	if ok {
+		sp.cursor = i
+	}
+	return ok
+}
+
 // DidSelect returns whether a document was selected and its path.
 func (sp searchPicker) DidSelect() (bool, string) {
 	if sp.selected != "" {
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// presentation holds the slide presentation of a document. Slides fill the
+// screen, centered; their speaker notes are shown in a pane below them.
+type presentation struct {
+	active bool
+	name   string
+	slides []mdk.Slide
+	slide  int // index of the current slide
+	step   int // index of the current step of the slide's list reveal
+
+	// incremental is true if lists are revealed one item at a time, and
+	// showNotes is true if the speaker notes pane is shown.
+	incremental bool
+	showNotes   bool
+
+	// height is the number of lines of the whole current slide, which
+	// centers the slide the same way at every step of its reveal.
+	height int
+
+	view  mdk.Model
+	notes mdk.Model
+}
+
+// Style constants for the presentation.
+var (
+	slStatusStyle = lipgloss.NewStyle().Foreground(colorMuted)
+	slRuleStyle   = lipgloss.NewStyle().Foreground(colorMuted)
+)
+
+// parseSlideBreak parses the slides.split setting.
+func parseSlideBreak(s string) (mdk.SlideBreak, error) {
+	switch s {
+	case "", "rule":
+		return mdk.BreakOnRule, nil
+	case "h1":
+		return mdk.BreakOnH1, nil
+	case "h2":
+		return mdk.BreakOnH2, nil
+	}
+	return 0, fmt.Errorf("slides.split: unknown value %q (want \"rule\", \"h1\", or \"h2\")", s)
+}
+
+// slideWidth returns the content width of slides on a screen of the given
+// width: most of the screen, within readable bounds.
+func slideWidth(width int) int {
+	return min(max(width*4/5, min(width, 40)), 100)
+}
+
+// presentationLayout returns the heights of the slide and notes panes. The
+// status line takes the last line of the screen, and a rule separates the
+// panes.
+func (r *markdownReader) presentationLayout() (slideHeight, notesHeight int) {
+	slideHeight = r.height - 1
+	if r.present.showNotes {
+		notesHeight = max(r.height/4, 3)
+		slideHeight -= notesHeight + 1
+	}
+	return max(slideHeight, 1), notesHeight
+}
+
+// resizePresentation sizes the slide and notes views to the screen.
+func (r *markdownReader) resizePresentation() {
+	if !r.present.active {
+		return
+	}
+	slideHeight, notesHeight := r.presentationLayout()
+	r.present.view.SetSize(r.width, slideHeight)
+	r.present.view.SetContentWidth(slideWidth(r.width))
+	r.present.notes.SetSize(r.width, max(notesHeight, 1))
+	r.showSlide()
+}
+
+// startPresentation presents the active tab's document as slides.
+func (r *markdownReader) startPresentation() tea.Cmd {
+	at := r.active()
+	if at.showSource {
+		return r.flashStatus("Cannot present the source view")
+	}
+	slides := at.view.Slides(r.slideBreak)
+	if len(slides) == 0 {
+		return r.flashStatus("No slides")
+	}
+
+	newView := func() mdk.Model {
+		view := mdk.NewModel(append([]mdk.Option{
+			mdk.WithTheme(r.theme),
+			mdk.WithGutter(false),
+		}, r.viewOpts...)...)
+		view.KeyMap = r.keys.KeyMap
+		return view
+	}
+	r.present = presentation{
+		active:      true,
+		name:        at.displayName(),
+		slides:      slides,
+		incremental: r.incrementalLists,
+		showNotes:   r.slideNotes,
+		view:        newView(),
+		notes:       newView(),
+	}
+	r.resizePresentation()
+	return nil
+}
+
+// closePresentation ends the presentation.
+func (r *markdownReader) closePresentation() {
+	r.present = presentation{}
+}
+
+// refreshPresentation splits the active tab's document into slides again
+// after it has been reloaded, staying on the current slide.
+func (r *markdownReader) refreshPresentation() {
+	if !r.present.active {
+		return
+	}
+	slides := r.active().view.Slides(r.slideBreak)
+	if len(slides) == 0 {
+		r.closePresentation()
+		return
+	}
+	r.present.slides = slides
+	r.gotoSlide(r.present.slide, r.present.step)
+}
+
+// steps returns the number of steps of the current slide.
+func (p *presentation) steps() int {
+	if !p.reveal() {
+		return 1
+	}
+	return len(p.slides[p.slide].Reveal) + 1
+}
+
+// reveal reports whether the current slide reveals its lists one item at a
+// time.
+func (p *presentation) reveal() bool {
+	return p.incremental && len(p.slides[p.slide].Reveal) > 0
+}
+
+// showSlide shows the current step of the current slide and its notes.
+func (r *markdownReader) showSlide() {
+	p := &r.present
+	s := p.slides[p.slide]
+
+	// Measure the whole slide so that it stays in place as it is revealed.
+	p.view.SetText(p.name, s.Markdown)
+	p.height = p.view.TotalLineCount()
+	if p.reveal() && p.step < len(s.Reveal) {
+		p.view.SetText(p.name, s.Markdown[:s.Reveal[p.step]])
+	}
+
+	notes := s.Notes
+	if notes == "" {
+		notes = "*No notes for this slide.*"
+	}
+	p.notes.SetText(p.name, notes)
+}
+
+// gotoSlide shows the given step of the given slide. A negative step shows
+// the slide's last step.
+func (r *markdownReader) gotoSlide(slide, step int) {
+	p := &r.present
+	p.slide = min(max(slide, 0), len(p.slides)-1)
+	if step < 0 {
+		step = p.steps() - 1
+	}
+	p.step = min(step, p.steps()-1)
+	r.showSlide()
+}
+
+// nextSlide reveals the next list item of the current slide, or moves to the
+// next slide.
+func (r *markdownReader) nextSlide() {
+	p := &r.present
+	switch {
+	case p.step+1 < p.steps():
+		r.gotoSlide(p.slide, p.step+1)
+	case p.slide+1 < len(p.slides):
+		r.gotoSlide(p.slide+1, 0)
+	}
+}
+
+// prevSlide hides the last revealed list item of the current slide, or moves
+// to the end of the previous slide.
+func (r *markdownReader) prevSlide() {
+	p := &r.present
+	switch {
+	case p.step > 0:
+		r.gotoSlide(p.slide, p.step-1)
+	case p.slide > 0:
+		r.gotoSlide(p.slide-1, -1)
+	}
+}
+
+// handlePresentationKey handles a key press during a presentation.
+func (r markdownReader) handlePresentationKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	p := &r.present
+	switch msg.String() {
+	case "esc", "q":
+		r.closePresentation()
+	case "right", "l", "n", "space", "enter", "pgdown":
+		r.nextSlide()
+	case "left", "h", "p", "backspace", "pgup":
+		r.prevSlide()
+	case "home", "g":
+		r.gotoSlide(0, 0)
+	case "end", "G":
+		r.gotoSlide(len(p.slides)-1, -1)
+	case "down", "j":
+		p.view.ScrollDown(1)
+	case "up", "k":
+		p.view.ScrollUp(1)
+	case "s":
+		p.showNotes = !p.showNotes
+		r.resizePresentation()
+	case "ctrl+c":
+		return r, tea.Quit
+	}
+	return r, nil
+}
+
+// renderPresentation renders the current slide, centered on the screen, with
+// the notes pane and the status line below it.
+func (r markdownReader) renderPresentation() string {
+	p := &r.present
+	slideHeight, notesHeight := r.presentationLayout()
+
+	// Move padding lines from the bottom of the slide to the top to center
+	// it vertically.
+	rows := strings.Split(p.view.View(), "\n")
+	if top := (slideHeight - p.height) / 2; top > 0 && len(rows) == slideHeight {
+		pad := rows[len(rows)-1]
+		centered := make([]string, 0, slideHeight)
+		for range top {
+			centered = append(centered, pad)
+		}
+		rows = append(centered, rows[:slideHeight-top]...)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Join(rows, "\n"))
+	if p.showNotes {
+		b.WriteString("\n")
+		b.WriteString(slRuleStyle.Render(strings.Repeat("─", r.width)))
+		b.WriteString("\n")
+		notes := strings.Split(p.notes.View(), "\n")
+		b.WriteString(strings.Join(notes[:min(len(notes), notesHeight)], "\n"))
+	}
+
+	counter := fmt.Sprintf("%d / %d", p.slide+1, len(p.slides))
+	if steps := p.steps(); steps > 1 {
+		counter += fmt.Sprintf(" · %d / %d", p.step+1, steps)
+	}
+	status := " " + p.name
+	gap := r.width - ansi.StringWidth(status) - ansi.StringWidth(counter) - 1
+	if gap < 1 {
+		status = ansi.Truncate(status, max(r.width-ansi.StringWidth(counter)-2, 0), "…")
+		gap = 1
+	}
+	b.WriteString("\n")
+	b.WriteString(slStatusStyle.Render(status + strings.Repeat(" ", gap) + counter))
+	return b.String()
+}
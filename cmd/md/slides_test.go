@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+const slidesDoc = "# Talk\n\n---\n\n## Plan\n\n- One\n- Two\n- Three\n\n<!-- Mention the schedule. -->\n\n---\n\n## Questions\n"
+
+// slidesReader returns a sized reader for a three-slide document.
+func slidesReader(t *testing.T) markdownReader {
+	t.Helper()
+	r := testReader("", slidesDoc, "/docs/talk.md")
+	r.fsys.(*memFS).files["/docs/talk.md"] = []byte(slidesDoc)
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return model.(markdownReader)
+}
+
+func TestParseSlideBreak(t *testing.T) {
+	for s, want := range map[string]mdk.SlideBreak{"": mdk.BreakOnRule, "rule": mdk.BreakOnRule, "h1": mdk.BreakOnH1, "h2": mdk.BreakOnH2} {
+		if got, err := parseSlideBreak(s); err != nil || got != want {
+			t.Errorf("parseSlideBreak(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	if _, err := parseSlideBreak("h3"); err == nil {
+		t.Error("expected an error for an unknown value")
+	}
+}
+
+func TestUpdate_Present(t *testing.T) {
+	r := press(t, slidesReader(t), "P")
+	if !r.present.active || len(r.present.slides) != 3 {
+		t.Fatalf("active = %v, slides = %d", r.present.active, len(r.present.slides))
+	}
+
+	lines := strings.Split(ansi.Strip(r.View().Content), "\n")
+	if len(lines) != 24 {
+		t.Fatalf("got %d lines, want the full screen", len(lines))
+	}
+	// The slide is centered vertically, with the counter on the last line.
+	title := -1
+	for i, l := range lines {
+		if strings.Contains(l, "Talk") {
+			title = i
+			break
+		}
+	}
+	if title < 8 || title > 14 {
+		t.Errorf("title on line %d, want the middle of the screen", title)
+	}
+	if !strings.HasSuffix(strings.TrimRight(lines[23], " "), "1 / 3") {
+		t.Errorf("status line = %q", lines[23])
+	}
+
+	r = press(t, r, "right", "right")
+	if r.present.slide != 2 {
+		t.Errorf("slide = %d, want 2", r.present.slide)
+	}
+	r = press(t, r, "right", "left")
+	if r.present.slide != 1 {
+		t.Errorf("slide = %d, want 1", r.present.slide)
+	}
+
+	r = press(t, r, "esc")
+	if r.present.active {
+		t.Error("expected esc to end the presentation")
+	}
+}
+
+func TestUpdate_PresentNotes(t *testing.T) {
+	r := press(t, slidesReader(t), "P", "right")
+	view := ansi.Strip(r.View().Content)
+	if strings.Contains(view, "Mention the schedule.") {
+		t.Error("expected the notes to be hidden")
+	}
+
+	r = press(t, r, "s")
+	if view = ansi.Strip(r.View().Content); !strings.Contains(view, "Mention the schedule.") {
+		t.Errorf("expected the notes pane:\n%s", view)
+	}
+	if lines := strings.Split(view, "\n"); len(lines) != 24 {
+		t.Errorf("got %d lines, want the full screen", len(lines))
+	}
+}
+
+func TestUpdate_PresentIncremental(t *testing.T) {
+	r := slidesReader(t)
+	r.incrementalLists = true
+	r = press(t, r, "P", "right")
+
+	view := ansi.Strip(r.View().Content)
+	if !strings.Contains(view, "One") || strings.Contains(view, "Two") {
+		t.Errorf("expected only the first item:\n%s", view)
+	}
+	r = press(t, r, "right", "right")
+	if view = ansi.Strip(r.View().Content); !strings.Contains(view, "Three") || r.present.slide != 1 {
+		t.Errorf("expected every item on slide 1:\n%s", view)
+	}
+
+	r = press(t, r, "right", "left")
+	if r.present.slide != 1 || r.present.step != 2 {
+		t.Errorf("slide = %d, step = %d; want the end of slide 1", r.present.slide, r.present.step)
+	}
+}
+
+func TestPresent_Reload(t *testing.T) {
+	r := press(t, slidesReader(t), "P", "right")
+	edited := strings.Replace(slidesDoc, "- Three", "- Three\n- Four", 1)
+	model, _ := r.Update(pageLoadedMsg{markdown: edited, source: "/docs/talk.md", reload: true})
+	r = model.(markdownReader)
+	if r.present.slide != 1 || !strings.Contains(ansi.Strip(r.View().Content), "Four") {
+		t.Errorf("slide = %d; want the updated slide", r.present.slide)
+	}
+}
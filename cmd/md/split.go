@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+)
+
+// splitLayout is the arrangement of the reader's panes.
+type splitLayout int
+
+const (
+	splitNone splitLayout = iota
+	splitVertical
+	splitHorizontal
+)
+
+// splitState describes the panes of a split reader. Each pane shows its own
+// tab. The focused pane always shows the active tab, so that everything that
+// acts on the active tab acts on the focused pane.
+type splitState struct {
+	layout splitLayout
+	other  int  // index of the tab shown in the unfocused pane
+	second bool // the focused pane is the right or bottom one
+
+	// If true, one pane shows the Markdown source of the other pane's
+	// document and the two scroll together.
+	sync bool
+}
+
+// splitSeparatorStyle styles the line between panes.
+var splitSeparatorStyle = lipgloss.NewStyle().Foreground(colorMuted)
+
+// paneSize returns the size of the first (left or top) or second (right or
+// bottom) pane. Without a split, both panes fill the view.
+func (r *markdownReader) paneSize(second bool) (width, height int) {
+	width, height = r.width, r.viewHeight()
+	switch r.split.layout {
+	case splitVertical:
+		// One column is used by the separator.
+		first := (width - 1) / 2
+		if second {
+			return width - 1 - first, height
+		}
+		return first, height
+	case splitHorizontal:
+		// One line is used by the separator.
+		first := (height - 1) / 2
+		if second {
+			return width, height - 1 - first
+		}
+		return width, first
+	}
+	return width, height
+}
+
+// tabSize returns the size of the view of the tab at the given index. Tabs
+// that are not shown in the unfocused pane are sized for the focused pane.
+func (r *markdownReader) tabSize(idx int) (width, height int) {
+	if r.split.layout != splitNone && idx == r.split.other {
+		return r.paneSize(!r.split.second)
+	}
+	return r.paneSize(r.split.second)
+}
+
+// toggleSplit splits the reader with the given layout. If the reader is
+// already split that way, the split is closed; if it is split the other way,
+// the layout is changed. The unfocused pane shows the next tab, or a copy of
+// the active document if there is only one tab.
+func (r *markdownReader) toggleSplit(layout splitLayout) {
+	switch r.split.layout {
+	case layout:
+		r.unsplit()
+		return
+	case splitNone:
+		// Open the split below.
+	default:
+		r.split.layout = layout
+		r.resizeAllViews()
+		return
+	}
+
+	if len(r.tabs) == 1 {
+		at := r.active()
+		t := r.newTab()
+		t.view.SetText(at.view.GetName(), string(at.view.GetMarkdown()))
+		t.currentSource = at.currentSource
+		r.restoreMarks(&t)
+		r.restoreAnnotations(&t)
+		t.view.Restore(at.view.Snapshot())
+		r.tabs = append(r.tabs, t)
+	}
+	r.split = splitState{layout: layout, other: (r.activeTab + 1) % len(r.tabs)}
+	r.resizeAllViews()
+}
+
+// toggleSourceSync opens a vertical split that shows the Markdown source of
+// the active document next to it, scrolling the two together. If the reader
+// is already split, the split is closed.
+func (r *markdownReader) toggleSourceSync() {
+	if r.split.layout != splitNone {
+		r.unsplit()
+		return
+	}
+
+	at := r.active()
+	if at.showSource {
+		return
+	}
+	t := r.newTab()
+	t.sourceOrigName = at.view.GetName()
+	t.sourceOrigMarkdown = string(at.view.GetMarkdown())
+	t.view.SetText(t.sourceOrigName, fenceSource(t.sourceOrigMarkdown))
+	t.currentSource = at.currentSource
+	t.showSource = true
+	t.syncSource = true
+	r.tabs = append(r.tabs, t)
+
+	r.split = splitState{layout: splitVertical, other: len(r.tabs) - 1, sync: true}
+	r.resizeAllViews()
+	r.syncPanes()
+}
+
+// unsplit closes the split, leaving the focused pane. The source tab of a
+// source sync split is closed with it.
+func (r *markdownReader) unsplit() {
+	if r.split.layout == splitNone {
+		return
+	}
+	if r.split.sync && r.active().syncSource {
+		r.switchPane()
+	}
+	split := r.split
+	r.split = splitState{}
+	if split.sync {
+		r.removeTab(split.other)
+	}
+	r.resizeAllViews()
+}
+
+// removeTab removes the tab at the given index without saving its state and
+// keeps the active and unfocused pane tabs pointing at the same tabs.
+func (r *markdownReader) removeTab(idx int) {
+	r.tabs = append(r.tabs[:idx], r.tabs[idx+1:]...)
+	if r.activeTab > idx || r.activeTab == len(r.tabs) {
+		r.activeTab--
+	}
+	if r.split.other > idx {
+		r.split.other--
+	}
+}
+
+// selectTab makes the tab at the given index active. If the tab is shown in
+// the unfocused pane, focus moves to that pane.
+func (r *markdownReader) selectTab(idx int) {
+	if r.split.layout != splitNone && idx == r.split.other {
+		r.switchPane()
+		return
+	}
+	if idx != r.activeTab {
+		r.focusDocumentPane()
+	}
+	r.activeTab = idx
+}
+
+// focusDocumentPane moves focus from the source pane of a source sync split to
+// the document's pane. It is called before another tab is made active, so that
+// the source pane keeps showing the source of the focused pane's document.
+func (r *markdownReader) focusDocumentPane() {
+	if r.split.sync && r.active().syncSource {
+		r.switchPane()
+	}
+}
+
+// switchPane moves focus to the other pane.
+func (r *markdownReader) switchPane() {
+	if r.split.layout == splitNone {
+		return
+	}
+	r.activeTab, r.split.other = r.split.other, r.activeTab
+	r.split.second = !r.split.second
+}
+
+// syncPanes scrolls the unfocused pane of a source sync split to the text shown
+// at the top of the focused pane. If the rendered pane now shows a different
+// document, the source pane is updated to show its source.
+func (r *markdownReader) syncPanes() {
+	if !r.split.sync {
+		return
+	}
+	focused, other := r.active(), &r.tabs[r.split.other]
+
+	rendered, source := focused, other
+	if focused.syncSource {
+		rendered, source = other, focused
+	}
+	if !source.syncSource || rendered.syncSource {
+		return
+	}
+	if markdown := string(rendered.view.GetMarkdown()); markdown != source.sourceOrigMarkdown {
+		source.sourceOrigName = rendered.view.GetName()
+		source.sourceOrigMarkdown = markdown
+		source.view.SetText(source.sourceOrigName, fenceSource(markdown))
+		source.currentSource = rendered.currentSource
+	}
+
+	// The source pane's document is the source wrapped in a code fence, so its
+	// offsets are shifted by the length of the opening fence line.
+	header := bytes.IndexByte(source.view.GetMarkdown(), '\n') + 1
+	if focused == rendered {
+		source.view.ScrollToSourceOffset(rendered.view.SourceOffset() + header)
+	} else {
+		rendered.view.ScrollToSourceOffset(source.view.SourceOffset() - header)
+	}
+}
+
+// renderPanes renders the views of the panes of a split reader.
+func (r *markdownReader) renderPanes() string {
+	first, second := r.active(), &r.tabs[r.split.other]
+	if r.split.second {
+		first, second = second, first
+	}
+
+	firstView, secondView := first.view.View(), second.view.View()
+	if r.split.layout == splitHorizontal {
+		separator := splitSeparatorStyle.Render(strings.Repeat("─", r.width))
+		return firstView + "\n" + separator + "\n" + secondView
+	}
+
+	width, height := r.paneSize(false)
+	firstPane := lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(firstView)
+	separator := splitSeparatorStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+	return lipgloss.JoinHorizontal(lipgloss.Top, firstPane, separator, secondView)
+}
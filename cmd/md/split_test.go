@@ -0,0 +1,135 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func splitReader(t *testing.T) markdownReader {
+	t.Helper()
+	r := testReader("", markDoc("First"), "/docs/a.md")
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 81, Height: 20})
+	return model.(markdownReader)
+}
+
+func press(t *testing.T, r markdownReader, keys ...string) markdownReader {
+	t.Helper()
+	var model tea.Model = r
+	for _, k := range keys {
+		model, _ = model.Update(keyMsg(k))
+	}
+	return model.(markdownReader)
+}
+
+func TestSplit_VerticalCopiesDocument(t *testing.T) {
+	r := press(t, splitReader(t), "|")
+	if r.split.layout != splitVertical {
+		t.Fatalf("layout = %v, want vertical", r.split.layout)
+	}
+	if len(r.tabs) != 2 || r.split.other != 1 || r.activeTab != 0 {
+		t.Fatalf("tabs = %d, other = %d, active = %d", len(r.tabs), r.split.other, r.activeTab)
+	}
+	if w, _ := r.paneSize(false); w != 40 {
+		t.Errorf("pane width = %d, want 40", w)
+	}
+
+	lines := strings.Split(ansi.Strip(r.View().Content), "\n")
+	// The tab bar is followed by the panes.
+	if !strings.Contains(lines[1], "│") || strings.Count(lines[1], "First") != 2 {
+		t.Errorf("expected both panes on one line, got %q", lines[1])
+	}
+
+	// Pressing the key again closes the split.
+	r = press(t, r, "|")
+	if r.split.layout != splitNone {
+		t.Errorf("expected the split to close")
+	}
+}
+
+func TestSplit_SwitchPaneAndTabs(t *testing.T) {
+	r := splitReader(t)
+	r.openNewTab("", markDoc("Second"), "/docs/b.md")
+	r.openNewTab("", markDoc("Third"), "/docs/c.md")
+	r.activeTab = 0
+	r = press(t, r, "_")
+	if r.split.layout != splitHorizontal || r.split.other != 1 {
+		t.Fatalf("split = %+v", r.split)
+	}
+
+	// Switching tabs skips the tab in the other pane.
+	r = press(t, r, "tab")
+	if r.activeTab != 2 {
+		t.Errorf("activeTab = %d, want 2", r.activeTab)
+	}
+
+	r = press(t, r, "ctrl+x")
+	if r.activeTab != 1 || r.split.other != 2 || !r.split.second {
+		t.Errorf("after switching panes: active = %d, split = %+v", r.activeTab, r.split)
+	}
+
+	// Closing a pane's tab closes the split.
+	r = press(t, r, "ctrl+w")
+	if r.split.layout != splitNone || len(r.tabs) != 2 {
+		t.Errorf("after closing: tabs = %d, split = %+v", len(r.tabs), r.split)
+	}
+}
+
+func TestSplit_SourceSync(t *testing.T) {
+	r := press(t, splitReader(t), "U")
+	if !r.split.sync || len(r.tabs) != 2 || !r.tabs[1].syncSource {
+		t.Fatalf("expected a source pane, split = %+v", r.split)
+	}
+	source := &r.tabs[1]
+	if !strings.Contains(string(source.view.GetMarkdown()), "## Middle") {
+		t.Fatal("expected the source pane to show the source")
+	}
+
+	// Scrolling the document scrolls the source.
+	r = press(t, r, "G")
+	if r.tabs[0].view.SourceOffset() == 0 {
+		t.Fatal("expected the document to scroll")
+	}
+	header := strings.Index(string(r.tabs[1].view.GetMarkdown()), "\n") + 1
+	if got, want := r.tabs[1].view.SourceOffset()-header, r.tabs[0].view.SourceOffset(); got != want {
+		t.Errorf("source offset = %d, want %d", got, want)
+	}
+
+	// Scrolling the source scrolls the document.
+	r = press(t, r, "ctrl+x", "g")
+	if r.activeTab != 1 {
+		t.Fatalf("activeTab = %d, want 1", r.activeTab)
+	}
+	if off := r.tabs[0].view.SourceOffset(); off != 0 && off != 2 {
+		t.Errorf("document offset = %d, want the top", off)
+	}
+
+	// Closing the split closes the source tab and focuses the document.
+	r = press(t, r, "U")
+	if r.split.layout != splitNone || len(r.tabs) != 1 || r.active().syncSource {
+		t.Errorf("after closing: tabs = %d, split = %+v", len(r.tabs), r.split)
+	}
+}
+
+func TestSplit_SourceSyncSwitchTabFromSourcePane(t *testing.T) {
+	r := splitReader(t)
+	r.openNewTab("", markDoc("Second"), "/docs/b.md")
+	r.activeTab = 0
+	first := string(r.tabs[0].view.GetMarkdown())
+
+	// Switching tabs from the source pane moves focus to the document pane
+	// first, so that the source pane follows the newly active document.
+	r = press(t, r, "U", "ctrl+x", "tab", "j")
+	if !r.split.sync || r.active().syncSource || r.activeTab != 1 {
+		t.Fatalf("active = %d, split = %+v", r.activeTab, r.split)
+	}
+	if got := string(r.tabs[0].view.GetMarkdown()); got != first || r.tabs[0].currentSource != "/docs/a.md" {
+		t.Errorf("the first document was overwritten: source = %q", r.tabs[0].currentSource)
+	}
+	source := &r.tabs[r.split.other]
+	if !source.syncSource || source.currentSource != "/docs/b.md" || !strings.Contains(string(source.view.GetMarkdown()), "# Second") {
+		t.Errorf("expected the source pane to show the source of b.md, got %q", source.currentSource)
+	}
+}
@@ -1,5 +1,7 @@
 package main
 
+import "unicode"
+
 // subsequenceMatch returns true if all characters in needle appear in haystack
 // in order (case-insensitive matching should be done by caller).
 func subsequenceMatch(haystack, needle string) bool {
@@ -20,3 +22,35 @@ func subsequenceMatch(haystack, needle string) bool {
 	}
 	return true
 }
+
+// subsequenceScore matches needle against haystack like subsequenceMatch and
+// scores the match. Matches that start words or continue the previous match
+// score higher, so "gs" ranks "Getting Started" above "Settings". Matching
+// is greedy, so the score is not always the best possible.
+func subsequenceScore(haystack, needle string) (int, bool) {
+	h := []rune(haystack)
+	score, hi, last := 0, 0, -2
+	for _, c := range needle {
+		found := false
+		for hi < len(h) {
+			i := hi
+			hi++
+			if h[i] != c {
+				continue
+			}
+			score++
+			if i == 0 || !unicode.IsLetter(h[i-1]) && !unicode.IsDigit(h[i-1]) {
+				score += 5
+			}
+			if i == last+1 {
+				score += 3
+			}
+			last, found = i, true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+// tabSearchRow is a row in the tab search results list: either the name of a
+// tab or a match within it.
+type tabSearchRow struct {
+	header  bool
+	tab     int
+	tabName string
+	result  mdk.SearchResult
+}
+
+// tabSearchPicker searches the rendered text of every open tab and lists the
+// matches grouped by tab.
+type tabSearchPicker struct {
+	input     textinput.Model
+	tabs      []*tab
+	rows      []tabSearchRow
+	matches   int
+	cursor    int // index into rows; always a match row when there are matches
+	minIdx    int
+	maxIdx    int
+	height    int
+	width     int
+	selected  bool
+	dismissed bool
+}
+
+// Style constants for the tab search picker.
+var (
+	tsHeaderStyle = lipgloss.NewStyle().Bold(true)
+	tsCrumbStyle  = lipgloss.NewStyle().Foreground(colorMuted)
+	tsMatchStyle  = lipgloss.NewStyle().Foreground(colorTabActiveFg).Background(colorTabActiveBg)
+)
+
+func newTabSearchPicker(tabs []*tab, height, width int) tabSearchPicker {
+	ti := textinput.New()
+	ti.Prompt = "  Search: "
+	ti.Placeholder = "type to search open tabs..."
+	innerW := width - 4 // account for border + padding
+	ti.SetWidth(innerW - lipgloss.Width(ti.Prompt) - 1)
+
+	listHeight := height - 2 // subtract input line + match count
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	return tabSearchPicker{
+		input:  ti,
+		tabs:   tabs,
+		maxIdx: listHeight - 1,
+		height: listHeight,
+		width:  width,
+	}
+}
+
+func (ts tabSearchPicker) Update(msg tea.Msg) (tabSearchPicker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			ts.dismissed = true
+			return ts, nil
+
+		case "enter":
+			if ts.matches == 0 {
+				return ts, nil
+			}
+			ts.selected = true
+			return ts, nil
+
+		case "up", "ctrl+p":
+			ts.move(-1)
+			return ts, nil
+
+		case "down", "ctrl+n":
+			ts.move(1)
+			return ts, nil
+
+		default:
+			prevValue := ts.input.Value()
+			var cmd tea.Cmd
+			ts.input, cmd = ts.input.Update(msg)
+			if ts.input.Value() != prevValue {
+				ts.search()
+			}
+			return ts, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	ts.input, cmd = ts.input.Update(msg)
+	return ts, cmd
+}
+
+// search runs the query against every tab and rebuilds the results list.
+func (ts *tabSearchPicker) search() {
+	ts.rows, ts.matches = nil, 0
+	if query := ts.input.Value(); strings.TrimSpace(query) != "" {
+		for i, t := range ts.tabs {
+			results := t.view.FindAll(query)
+			if len(results) == 0 {
+				continue
+			}
+			name := t.displayName()
+			ts.rows = append(ts.rows, tabSearchRow{header: true, tab: i, tabName: name})
+			for _, res := range results {
+				ts.rows = append(ts.rows, tabSearchRow{tab: i, tabName: name, result: res})
+			}
+			ts.matches += len(results)
+		}
+	}
+
+	ts.cursor, ts.minIdx, ts.maxIdx = 0, 0, ts.height-1
+	ts.move(0)
+}
+
+// move moves the cursor by delta match rows, skipping tab headers, and keeps
+// the cursor and its tab header in view.
+func (ts *tabSearchPicker) move(delta int) {
+	if ts.matches == 0 {
+		ts.cursor = 0
+		return
+	}
+
+	if ts.rows[ts.cursor].header {
+		ts.cursor++
+	}
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	for ; delta != 0; delta -= step {
+		next := ts.cursor + step
+		for next >= 0 && next < len(ts.rows) && ts.rows[next].header {
+			next += step
+		}
+		if next < 0 || next >= len(ts.rows) {
+			break
+		}
+		ts.cursor = next
+	}
+
+	cursor, top := ts.cursor, ts.cursor
+	if ts.rows[cursor-1].header {
+		top-- // keep the tab name in view
+	}
+	if top < ts.minIdx {
+		ts.minIdx = top
+		ts.maxIdx = ts.minIdx + ts.height - 1
+	}
+	if cursor > ts.maxIdx {
+		ts.maxIdx = cursor
+		ts.minIdx = ts.maxIdx - ts.height + 1
+	}
+}
+
+// click moves the cursor to the match shown on the given line of the picker's
+// view. Returns false if no match is shown there.
+func (ts *tabSearchPicker) click(line int) bool {
+	i, ok := listEntryAt(line, 1, ts.minIdx, ts.maxIdx, len(ts.rows))
+	if !ok || ts.rows[i].header {
+		return false
+	}
+	ts.cursor = i
+	return true
+}
+
+// snippet renders a result's line with the match highlighted, trimmed to fit
+// within width columns.
+func snippet(res mdk.SearchResult, width int) string {
+	line := res.Line
+	start, end := res.StartCol, res.EndCol
+
+	// Drop indentation.
+	trimmed := strings.TrimLeft(line, " ")
+	indent := len(line) - len(trimmed)
+	line, start, end = trimmed, max(start-indent, 0), max(end-indent, 0)
+
+	// Keep some context before the match visible.
+	if context := width / 4; start > context {
+		cut := start - context
+		line = ansi.TruncateLeft(line, cut+3, "...")
+		start, end = start-cut, end-cut
+	}
+
+	before := ansi.Cut(line, 0, start)
+	match := ansi.Cut(line, start, end)
+	after := ansi.Cut(line, end, ansi.StringWidth(line))
+	if avail := width - ansi.StringWidth(before+match); avail < ansi.StringWidth(after) {
+		after = ansi.Truncate(after, max(avail, 0), "...")
+	}
+	return before + tsMatchStyle.Render(match) + after
+}
+
+func (ts tabSearchPicker) View() string {
+	var s strings.Builder
+
+	s.WriteString(ts.input.View())
+	s.WriteRune('\n')
+
+	switch {
+	case ts.input.Value() == "":
+	case ts.matches == 0:
+		s.WriteString(hpEmptyStyle.Render("  No matches in open tabs."))
+		s.WriteRune('\n')
+	default:
+		for i, row := range ts.rows {
+			if i < ts.minIdx || i > ts.maxIdx {
+				continue
+			}
+
+			if row.header {
+				name := row.tabName
+				if name == "" {
+					name = "(untitled)"
+				}
+				s.WriteString(" " + tsHeaderStyle.Render(ansi.Truncate(name, ts.width-6, "...")))
+				s.WriteRune('\n')
+				continue
+			}
+
+			avail := ts.width - 8 // cursor + indent + padding
+			var crumbs string
+			if len(row.result.Breadcrumbs) > 0 {
+				crumbs = ansi.Truncate(strings.Join(row.result.Breadcrumbs, " > "), avail/2, "...") + ": "
+			}
+			text := snippet(row.result, avail-ansi.StringWidth(crumbs))
+
+			if i == ts.cursor {
+				s.WriteString(hpCursorStyle.Render(">"))
+			} else {
+				s.WriteString(hpCursorStyle.Render(" "))
+			}
+			s.WriteString("   " + tsCrumbStyle.Render(crumbs) + text)
+			s.WriteRune('\n')
+		}
+	}
+
+	// Pad remaining list height.
+	rendered := lipgloss.Height(s.String())
+	for i := rendered; i <= ts.height; i++ {
+		s.WriteRune('\n')
+	}
+
+	if ts.matches > 0 {
+		count := fmt.Sprintf("  %d matches", ts.matches)
+		if ts.matches == 1 {
+			count = "  1 match"
+		}
+		s.WriteString(tsCrumbStyle.Render(count))
+	}
+
+	return s.String()
+}
+
+// DidSelect returns whether a match was chosen and, if so, its row.
+func (ts tabSearchPicker) DidSelect() (bool, tabSearchRow) {
+	if !ts.selected || ts.matches == 0 || ts.cursor >= len(ts.rows) {
+		return false, tabSearchRow{}
+	}
+	return true, ts.rows[ts.cursor]
+}
+
+// gotoTabSearchResult switches to the tab of a match and shows it using the
+// tab's in-document search, so that the next and previous match keys
+// continue from it.
+func (r *markdownReader) gotoTabSearchResult(query string, row tabSearchRow) {
+	if row.tab < 0 || row.tab >= len(r.tabs) {
+		return
+	}
+	r.selectTab(row.tab)
+	r.active().view.ShowSearchResult(query, row.result.Match)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	mdk "github.com/pgavlin/markdown-kit/view"
+)
+
+const tabSearchDoc = "# Guide\n\n## Install\n\nRun the installer.\n\n## Usage\n\nRun `md`.\n"
+
+func tabSearchReader() markdownReader {
+	r := testReader("", tabSearchDoc, "/docs/guide.md")
+	r.openNewTab("", "# Notes\n\nNothing to run here.\n", "/docs/notes.md")
+	r.activeTab = 0
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return model.(markdownReader)
+}
+
+func TestSnippet(t *testing.T) {
+	res := mdk.SearchResult{Line: "    a long line with a match near the end", StartCol: 23, EndCol: 28}
+	got := ansi.Strip(snippet(res, 20))
+	if !strings.HasPrefix(got, "...") || !strings.Contains(got, "match") {
+		t.Errorf("snippet = %q", got)
+	}
+	if w := ansi.StringWidth(got); w > 20 {
+		t.Errorf("snippet width = %d, want <= 20", w)
+	}
+}
+
+func TestTabSearchPicker_GroupsByTab(t *testing.T) {
+	r := tabSearchReader()
+	model, _ := r.Update(keyMsg("A"))
+	r = model.(markdownReader)
+	if !r.showTabSearch {
+		t.Fatal("expected the tab search picker to be shown")
+	}
+	r.tabSearchPicker.input.Focus()
+	for _, c := range "run" {
+		model, _ = model.Update(tea.KeyPressMsg{Code: c, Text: string(c)})
+	}
+	r = model.(markdownReader)
+
+	ts := r.tabSearchPicker
+	if ts.matches != 3 {
+		t.Fatalf("matches = %d, want 3", ts.matches)
+	}
+	var headers []string
+	for _, row := range ts.rows {
+		if row.header {
+			headers = append(headers, row.tabName)
+		}
+	}
+	if strings.Join(headers, "|") != "Guide|Notes" {
+		t.Errorf("headers = %q", headers)
+	}
+	if ts.rows[ts.cursor].header {
+		t.Error("cursor should skip the tab name")
+	}
+	if crumbs := ts.rows[ts.cursor].result.Breadcrumbs; strings.Join(crumbs, " > ") != "Guide > Install" {
+		t.Errorf("breadcrumbs = %q", crumbs)
+	}
+
+	view := ansi.Strip(ts.View())
+	for _, want := range []string{"Guide", "Guide > Install: Run the installer.", "Notes", "3 matches"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestUpdate_TabSearchSelect(t *testing.T) {
+	r := tabSearchReader()
+	model, _ := r.Update(keyMsg("A"))
+	r = model.(markdownReader)
+	r.tabSearchPicker.input.Focus()
+	model = r
+	for _, c := range "run" {
+		model, _ = model.Update(tea.KeyPressMsg{Code: c, Text: string(c)})
+	}
+
+	// The third match is the only one in the second tab.
+	for _, k := range []string{"down", "down", "down", "enter"} {
+		model, _ = model.Update(keyMsg(k))
+	}
+	r = model.(markdownReader)
+	if r.showTabSearch {
+		t.Error("expected the picker to close")
+	}
+	if r.activeTab != 1 {
+		t.Fatalf("activeTab = %d, want 1", r.activeTab)
+	}
+	if current, total := r.active().view.SearchPosition(); current != 1 || total != 1 {
+		t.Errorf("search position = %d/%d, want 1/1", current, total)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/alecthomas/chroma"
+	chromaStyles "github.com/alecthomas/chroma/styles"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/styles"
+)
+
+// themeEntry represents a single theme in the theme picker list.
+type themeEntry struct {
+	name    string        // display name (the style name)
+	setting string        // value to store in the config's theme setting
+	source  string        // where the theme comes from ("built-in" or a file path)
+	style   *chroma.Style // the theme itself
+}
+
+// themeFiles holds the theme files offered by the theme picker, keyed by
+// their resolved paths. Theme files are not registered with chroma, so the
+// picker keeps its own styles; a file is only parsed again when its contents
+// change.
+type themeFiles map[string]themeFile
+
+// themeFile is a parsed theme file and the contents it was parsed from.
+type themeFile struct {
+	data  []byte
+	style *chroma.Style
+}
+
+// load returns the style of the theme file at path, resolved as by
+// loadThemeFile.
+func (files themeFiles) load(path, dir string, fsys fileSystem) (*chroma.Style, error) {
+	path, data, err := readThemeFile(path, dir, fsys)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := files[path]; ok && bytes.Equal(f.data, data) {
+		return f.style, nil
+	}
+	style, err := styles.ParseThemeFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	files[path] = themeFile{data: data, style: style}
+	return style, nil
+}
+
+// themesDirName is the directory, relative to the config directory, that is
+// scanned for user theme files.
+const themesDirName = "themes"
+
+// themeEntries returns the themes offered by the theme picker: the built-in
+// markdown themes first, then user theme files, then the remaining chroma
+// styles in name order. User theme files are read from the themes directory
+// next to the config file and loaded through loaded; current is the configured
+// theme setting, which is also listed if it names a theme file outside that
+// directory. Theme files that fail to load are skipped. A theme file that
+// shares the name of a built-in theme is listed alongside it.
+func themeEntries(configDir, current string, loaded themeFiles, fsys fileSystem, logger *slog.Logger) []themeEntry {
+	var entries []themeEntry
+	seen := map[string]bool{}
+	add := func(e themeEntry) {
+		if !seen[e.setting] {
+			seen[e.setting] = true
+			entries = append(entries, e)
+		}
+	}
+
+	for _, s := range []*chroma.Style{styles.GlamourDark, styles.GlamourLight, styles.Pulumi} {
+		add(themeEntry{name: s.Name, setting: s.Name, source: "built-in", style: s})
+	}
+
+	var files []string
+	if configDir != "" {
+		if dirEntries, err := fsys.ReadDir(filepath.Join(configDir, themesDirName)); err == nil {
+			for _, de := range dirEntries {
+				if !de.IsDir() && styles.IsThemeFile(de.Name()) {
+					files = append(files, path.Join(themesDirName, de.Name()))
+				}
+			}
+		}
+	}
+	if styles.IsThemeFile(current) && !slices.Contains(files, current) {
+		files = append(files, current)
+	}
+	for _, f := range files {
+		s, err := loaded.load(f, configDir, fsys)
+		if err != nil {
+			logger.Error("theme_load_error", "path", f, "error", err)
+			continue
+		}
+		add(themeEntry{name: s.Name, setting: f, source: f, style: s})
+	}
+
+	names := chromaStyles.Names()
+	sort.Strings(names)
+	for _, name := range names {
+		add(themeEntry{name: name, setting: name, source: "built-in", style: chromaStyles.Get(name)})
+	}
+	return entries
+}
+
+// themePicker is a list selector with text filtering for choosing a color theme.
+// The highlighted theme is previewed live by the reader.
+type themePicker struct {
+	input     textinput.Model
+	all       []themeEntry // full list
+	filtered  []themeEntry // after text filter
+	cursor    int
+	minIdx    int
+	maxIdx    int
+	height    int
+	width     int
+	selected  bool
+	dismissed bool
+}
+
+// newThemePicker creates a theme picker with the cursor on the entry whose
+// setting or style matches the current theme.
+func newThemePicker(entries []themeEntry, currentSetting string, current *chroma.Style, height, width int) themePicker {
+	ti := textinput.New()
+	ti.Prompt = "  Filter: "
+	ti.Placeholder = "type to filter..."
+	innerW := width - 4 // account for border + padding
+	ti.SetWidth(innerW - lipgloss.Width(ti.Prompt) - 1)
+
+	listHeight := height - 1 // subtract input line
+	if listHeight < 1 {
+		listHeight = 1
+	}
+
+	cursor := 0
+	for i, e := range entries {
+		if e.setting == currentSetting && currentSetting != "" {
+			cursor = i
+			break
+		}
+		if e.style == current {
+			cursor = i
+		}
+	}
+
+	tp := themePicker{
+		input:    ti,
+		all:      entries,
+		filtered: append([]themeEntry(nil), entries...),
+		cursor:   cursor,
+		height:   listHeight,
+		width:    width,
+	}
+	tp.minIdx = 0
+	tp.maxIdx = listHeight - 1
+	if tp.cursor > tp.maxIdx {
+		tp.minIdx = tp.cursor - listHeight + 1
+		tp.maxIdx = tp.cursor
+	}
+	return tp
+}
+
+func (tp themePicker) Update(msg tea.Msg) (themePicker, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "esc":
+			tp.dismissed = true
+			return tp, nil
+
+		case "enter":
+			if len(tp.filtered) == 0 || tp.cursor < 0 {
+				return tp, nil
+			}
+			tp.selected = true
+			return tp, nil
+
+		case "up", "ctrl+p":
+			tp.cursor--
+			if tp.cursor < 0 {
+				tp.cursor = 0
+			}
+			if tp.cursor < tp.minIdx {
+				tp.minIdx = tp.cursor
+				tp.maxIdx = tp.minIdx + tp.height - 1
+			}
+			return tp, nil
+
+		case "down", "ctrl+n":
+			tp.cursor++
+			if tp.cursor >= len(tp.filtered) {
+				tp.cursor = len(tp.filtered) - 1
+			}
+			if tp.cursor < 0 {
+				tp.cursor = 0
+			}
+			if tp.cursor > tp.maxIdx {
+				tp.maxIdx = tp.cursor
+				tp.minIdx = tp.maxIdx - tp.height + 1
+			}
+			return tp, nil
+
+		case "pgup":
+			tp.cursor -= tp.height
+			if tp.cursor < 0 {
+				tp.cursor = 0
+			}
+			tp.minIdx -= tp.height
+			if tp.minIdx < 0 {
+				tp.minIdx = 0
+			}
+			tp.maxIdx = tp.minIdx + tp.height - 1
+			return tp, nil
+
+		case "pgdown":
+			tp.cursor += tp.height
+			if tp.cursor >= len(tp.filtered) {
+				tp.cursor = max(0, len(tp.filtered)-1)
+			}
+			tp.maxIdx += tp.height
+			if tp.maxIdx >= len(tp.filtered) {
+				tp.maxIdx = max(0, len(tp.filtered)-1)
+			}
+			tp.minIdx = tp.maxIdx - tp.height + 1
+			if tp.minIdx < 0 {
+				tp.minIdx = 0
+			}
+			return tp, nil
+
+		default:
+			prevValue := tp.input.Value()
+			var cmd tea.Cmd
+			tp.input, cmd = tp.input.Update(msg)
+			if tp.input.Value() != prevValue {
+				tp.filter()
+			}
+			return tp, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	tp.input, cmd = tp.input.Update(msg)
+	return tp, cmd
+}
+
+// filter applies case-insensitive subsequence matching on theme name and source.
+func (tp *themePicker) filter() {
+	query := strings.ToLower(tp.input.Value())
+	tp.filtered = nil
+	for _, e := range tp.all {
+		if query == "" || subsequenceMatch(strings.ToLower(e.name), query) || subsequenceMatch(strings.ToLower(e.source), query) {
+			tp.filtered = append(tp.filtered, e)
+		}
+	}
+
+	tp.cursor = 0
+	tp.minIdx = 0
+	tp.maxIdx = tp.height - 1
+}
+
+func (tp themePicker) View() string {
+	var s strings.Builder
+
+	s.WriteString(tp.input.View())
+	s.WriteRune('\n')
+
+	if len(tp.filtered) == 0 {
+		s.WriteString(hpEmptyStyle.Render("  No matching themes."))
+		s.WriteRune('\n')
+	} else {
+		for i, entry := range tp.filtered {
+			if i < tp.minIdx || i > tp.maxIdx {
+				continue
+			}
+
+			// Truncate source to fit.
+			sourceMaxW := tp.width - ansi.StringWidth(entry.name) - 6 // cursor + spaces + padding
+			source := entry.source
+			if sourceMaxW > 0 && ansi.StringWidth(source) > sourceMaxW {
+				source = "..." + source[len(source)-sourceMaxW+3:]
+			}
+
+			if i == tp.cursor {
+				s.WriteString(hpCursorStyle.Render(">") + hpSelectedStyle.Render(" "+entry.name+"  "+source))
+			} else {
+				s.WriteString(hpCursorStyle.Render(" "))
+				s.WriteString(" " + hpNameStyle.Render(entry.name))
+				s.WriteString("  " + hpSourceStyle.Render(source))
+			}
+			s.WriteRune('\n')
+		}
+	}
+
+	// Pad remaining height.
+	rendered := lipgloss.Height(s.String())
+	for i := rendered; i <= tp.height+1; i++ {
+		s.WriteRune('\n')
+	}
+
+	return s.String()
+}
+
+// click moves the cursor to the entry shown on the given line of the picker's
+// view. Returns false if no entry is shown there.
+func (tp *themePicker) click(line int) bool {
+	i, ok := listEntryAt(line, 1, tp.minIdx, tp.maxIdx, len(tp.filtered))
+	if ok {
+		tp.cursor = i
+	}
+	return ok
+}
+
+// Highlighted returns the theme under the cursor, if any.
+func (tp themePicker) Highlighted() (themeEntry, bool) {
+	if tp.cursor < 0 || tp.cursor >= len(tp.filtered) {
+		return themeEntry{}, false
+	}
+	return tp.filtered[tp.cursor], true
+}
+
+// DidSelect returns whether a theme was chosen and, if so, the chosen theme.
+func (tp themePicker) DidSelect() (bool, themeEntry) {
+	if !tp.selected {
+		return false, themeEntry{}
+	}
+	e, ok := tp.Highlighted()
+	return ok, e
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/alecthomas/chroma"
+	chromaStyles "github.com/alecthomas/chroma/styles"
+	"github.com/pgavlin/markdown-kit/styles"
+)
+
+const testThemeFile = `name = "picker-test-theme"
+
+[styles]
+GenericHeading = "#ff0000 bold"
+`
+
+func TestThemeEntries(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/config/md/themes/mine.toml"] = []byte(testThemeFile)
+	fs.files["/config/md/themes/broken.toml"] = []byte("[styles]\nNope = \"bold\"\n")
+	fs.files["/config/md/themes/notes.txt"] = []byte("not a theme")
+
+	entries := themeEntries("/config/md", "", themeFiles{}, fs, discardLogger())
+
+	if len(entries) < 4 {
+		t.Fatalf("expected at least 4 entries, got %d", len(entries))
+	}
+	// Built-in markdown themes come first.
+	for i, want := range []string{styles.GlamourDark.Name, styles.GlamourLight.Name, styles.Pulumi.Name} {
+		if entries[i].name != want {
+			t.Errorf("entry %d: name = %q, want %q", i, entries[i].name, want)
+		}
+	}
+	// Then user theme files, stored relative to the config directory.
+	if entries[3].name != "picker-test-theme" || entries[3].setting != "themes/mine.toml" {
+		t.Errorf("entry 3: name = %q, setting = %q", entries[3].name, entries[3].setting)
+	}
+
+	names := map[string]int{}
+	for _, e := range entries {
+		names[e.name]++
+		if e.style == nil {
+			t.Errorf("entry %q has no style", e.name)
+		}
+	}
+	if names["monokai"] != 1 {
+		t.Error("expected chroma styles to be listed once")
+	}
+	if names[styles.Pulumi.Name] != 1 {
+		t.Error("expected built-in themes not to be repeated")
+	}
+}
+
+func TestThemeEntries_CurrentThemeFile(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/elsewhere/current.toml"] = []byte(testThemeFile)
+
+	entries := themeEntries("/config/md", "/elsewhere/current.toml", themeFiles{}, fs, discardLogger())
+
+	found := false
+	for _, e := range entries {
+		if e.setting == "/elsewhere/current.toml" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the configured theme file to be listed")
+	}
+}
+
+func testThemeEntries() []themeEntry {
+	return []themeEntry{
+		{name: "glamour-dark", setting: "glamour-dark", source: "built-in", style: styles.GlamourDark},
+		{name: "glamour-light", setting: "glamour-light", source: "built-in", style: styles.GlamourLight},
+		{name: "pulumi", setting: "pulumi", source: "built-in", style: styles.Pulumi},
+	}
+}
+
+func TestNewThemePicker_CursorOnCurrent(t *testing.T) {
+	tp := newThemePicker(testThemeEntries(), "pulumi", nil, 20, 60)
+	if tp.cursor != 2 {
+		t.Errorf("cursor = %d, want 2", tp.cursor)
+	}
+
+	// With no setting, the current style is used.
+	tp = newThemePicker(testThemeEntries(), "", styles.GlamourLight, 20, 60)
+	if tp.cursor != 1 {
+		t.Errorf("cursor = %d, want 1", tp.cursor)
+	}
+}
+
+func TestThemePicker_NavigateAndSelect(t *testing.T) {
+	tp := newThemePicker(testThemeEntries(), "", styles.GlamourDark, 20, 60)
+
+	tp, _ = tp.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if e, ok := tp.Highlighted(); !ok || e.name != "glamour-light" {
+		t.Errorf("highlighted = %q, want glamour-light", e.name)
+	}
+	if ok, _ := tp.DidSelect(); ok {
+		t.Error("expected no selection before enter")
+	}
+
+	tp, _ = tp.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	ok, e := tp.DidSelect()
+	if !ok || e.name != "glamour-light" {
+		t.Errorf("DidSelect() = %v, %q", ok, e.name)
+	}
+}
+
+func TestThemePicker_Filter(t *testing.T) {
+	tp := newThemePicker(testThemeEntries(), "", nil, 20, 60)
+	tp.input.Focus()
+
+	for _, r := range "pul" {
+		tp, _ = tp.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if len(tp.filtered) != 1 || tp.filtered[0].name != "pulumi" {
+		t.Fatalf("filtered = %v", tp.filtered)
+	}
+	if e, _ := tp.Highlighted(); e.name != "pulumi" {
+		t.Errorf("highlighted = %q, want pulumi", e.name)
+	}
+
+	for _, r := range "zzz" {
+		tp, _ = tp.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if _, ok := tp.Highlighted(); ok {
+		t.Error("expected nothing highlighted when no themes match")
+	}
+	if !strings.Contains(tp.View(), "No matching themes.") {
+		t.Error("expected empty message in view")
+	}
+}
+
+// --- Theme picker integration tests ---
+
+func TestUpdate_Themes_PreviewAndDismiss(t *testing.T) {
+	r := testReader("test", "# Hello", "")
+	r.openNewTab("second", "# Second", "")
+
+	m, cmd := r.Update(keyMsg("C"))
+	reader := m.(markdownReader)
+	if !reader.showThemes {
+		t.Fatal("expected showThemes=true after C")
+	}
+	if cmd == nil {
+		t.Error("expected non-nil command (focus)")
+	}
+
+	// Moving the cursor previews the theme in every tab.
+	m, _ = reader.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	reader = m.(markdownReader)
+	if reader.theme != styles.GlamourLight {
+		t.Errorf("theme = %q, want glamour-light", reader.theme.Name)
+	}
+	for i, tab := range reader.tabs {
+		if tab.view.Theme() != styles.GlamourLight {
+			t.Errorf("tab %d was not re-themed", i)
+		}
+	}
+
+	// Esc restores the previous theme.
+	m, _ = reader.Update(keyMsg("esc"))
+	reader = m.(markdownReader)
+	if reader.showThemes {
+		t.Error("expected showThemes=false after esc")
+	}
+	if reader.theme != styles.GlamourDark {
+		t.Errorf("theme = %q, want glamour-dark", reader.theme.Name)
+	}
+	for i, tab := range reader.tabs {
+		if tab.view.Theme() != styles.GlamourDark {
+			t.Errorf("tab %d was not restored", i)
+		}
+	}
+}
+
+func TestUpdate_Themes_SelectPersists(t *testing.T) {
+	r := testReader("test", "# Hello", "")
+	fs := r.fsys.(*memFS)
+	r.configPath = "/config/md/config.toml"
+	fs.files[r.configPath] = []byte(defaultConfig)
+
+	m, _ := r.Update(keyMsg("C"))
+	reader := m.(markdownReader)
+	m, _ = reader.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	reader = m.(markdownReader)
+	m, _ = reader.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	reader = m.(markdownReader)
+	m, _ = reader.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	reader = m.(markdownReader)
+
+	if reader.showThemes {
+		t.Error("expected showThemes=false after enter")
+	}
+	if reader.theme != styles.Pulumi || reader.themeSetting != "pulumi" {
+		t.Errorf("theme = %q, setting = %q", reader.theme.Name, reader.themeSetting)
+	}
+
+	cfg, err := loadConfig(r.configPath, fs, discardLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "pulumi" {
+		t.Errorf("saved theme = %q, want pulumi", cfg.Theme)
+	}
+}
+
+func TestView_ThemePicker(t *testing.T) {
+	r := testReader("test", "# Hello", "")
+	m, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	reader := m.(markdownReader)
+	m, _ = reader.Update(keyMsg("C"))
+	reader = m.(markdownReader)
+
+	v := reader.View()
+	if !strings.Contains(v.Content, "Theme") {
+		t.Error("expected 'Theme' header in view")
+	}
+	if !strings.Contains(v.Content, "glamour-dark") {
+		t.Error("expected theme names in view")
+	}
+}
+
+func TestThemeEntries_FileSharesBuiltInName(t *testing.T) {
+	fs := newMemFS()
+	fs.files["/config/md/themes/monokai.toml"] = []byte("[styles]\nGenericHeading = \"#ff0000 bold\"\n")
+
+	files := themeFiles{}
+	entries := themeEntries("/config/md", "", files, fs, discardLogger())
+
+	// The theme file is listed alongside the built-in style, which it does not
+	// replace.
+	var settings []string
+	for _, e := range entries {
+		if e.name == "monokai" {
+			settings = append(settings, e.setting)
+		}
+	}
+	if len(settings) != 2 || settings[0] != "themes/monokai.toml" || settings[1] != "monokai" {
+		t.Errorf("monokai entries = %v", settings)
+	}
+	if chromaStyles.Get("monokai").Get(chroma.GenericHeading).Colour.String() == "#ff0000" {
+		t.Error("expected the theme file not to replace the built-in style")
+	}
+
+	// Unchanged theme files are not parsed again.
+	first := files["/config/md/themes/monokai.toml"].style
+	themeEntries("/config/md", "", files, fs, discardLogger())
+	if files["/config/md/themes/monokai.toml"].style != first {
+		t.Error("expected an unchanged theme file to be reused")
+	}
+	fs.files["/config/md/themes/monokai.toml"] = []byte(testThemeFile)
+	themeEntries("/config/md", "", files, fs, discardLogger())
+	if files["/config/md/themes/monokai.toml"].style == first {
+		t.Error("expected a changed theme file to be parsed again")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/alecthomas/chroma"
+	chromaStyles "github.com/alecthomas/chroma/styles"
+	goldmark_renderer "github.com/pgavlin/goldmark/renderer"
+	"github.com/pgavlin/goldmark/util"
+	"github.com/pgavlin/markdown-kit/markdown"
+	"github.com/pgavlin/markdown-kit/renderer"
+	"github.com/pgavlin/markdown-kit/styles"
+)
+
+// themePreviewDocument is the sample document rendered by "md theme preview".
+// It exercises every element that a theme can style.
+const themePreviewDocument = "# Heading 1\n" +
+	"\n" +
+	"Body text with *emphasis*, **strong**, ***strong emphasis***,\n" +
+	"`inline code`, and a [link](https://github.com/pgavlin/markdown-kit).\n" +
+	"\n" +
+	"## Heading 2\n" +
+	"\n" +
+	"> A block quote, with *emphasis*.\n" +
+	"\n" +
+	"- A bullet list\n" +
+	"  - with a nested item\n" +
+	"- and another item\n" +
+	"\n" +
+	"1. A numbered list\n" +
+	"2. with two items\n" +
+	"\n" +
+	"### Heading 3\n" +
+	"\n" +
+	"```go\n" +
+	"// Package main is a code block.\n" +
+	"package main\n" +
+	"\n" +
+	"import \"fmt\"\n" +
+	"\n" +
+	"func main() {\n" +
+	"\tfor i := 0; i < 3; i++ {\n" +
+	"\t\tfmt.Printf(\"hello, %d\\n\", i)\n" +
+	"\t}\n" +
+	"}\n" +
+	"```\n" +
+	"\n" +
+	"#### Heading 4\n" +
+	"\n" +
+	"| Element | Token |\n" +
+	"|---------|-------|\n" +
+	"| Table header | TableHeader |\n" +
+	"| Alternate row | TableRowAlt |\n" +
+	"| Code span | CodeSpan |\n" +
+	"\n" +
+	"---\n" +
+	"\n" +
+	"![An image](image.png)\n"
+
+// resolvePreviewTheme returns the theme named by spec, which is either the path
+// to a theme file or the name of a registered chroma style.
+func resolvePreviewTheme(spec string, fsys fileSystem) (*chroma.Style, error) {
+	if styles.IsThemeFile(spec) {
+		return loadThemeFile(spec, "", fsys)
+	}
+	s, ok := chromaStyles.Registry[spec]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q", spec)
+	}
+	return s, nil
+}
+
+// renderThemePreview renders themePreviewDocument to w using the given theme.
+func renderThemePreview(w io.Writer, theme *chroma.Style, width int) error {
+	source := []byte(themePreviewDocument)
+
+	document := markdown.NewParser().Parse(source)
+
+	r := renderer.New(
+		renderer.WithTheme(theme),
+		renderer.WithWordWrap(width),
+		renderer.WithSoftBreak(width != 0),
+		renderer.WithPad(true),
+	)
+	gr := goldmark_renderer.NewRenderer(goldmark_renderer.WithNodeRenderers(util.Prioritized(r, 100)))
+	return gr.Render(w, source, document)
+}
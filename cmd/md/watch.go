@@ -0,0 +1,123 @@
+package main
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// watchInterval is the time between checks of the watched file.
+const watchInterval = 500 * time.Millisecond
+
+// watchState tracks the local file shown in the active tab so that the tab
+// can be reloaded when the file changes. For files that are converted to
+// Markdown, the watched file is the converter's input.
+type watchState struct {
+	enabled bool
+	gen     int // incremented each time watching starts, to stop old ticks
+
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// watchTickMsg is sent when it is time to check the watched file.
+type watchTickMsg struct {
+	gen int
+}
+
+// watchStatMsg reports the state of the watched file.
+type watchStatMsg struct {
+	gen     int
+	path    string
+	modTime time.Time
+	size    int64
+	err     error
+}
+
+// watchTick returns a command that sends a watchTickMsg after watchInterval.
+func watchTick(gen int) tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg { return watchTickMsg{gen: gen} })
+}
+
+// statWatched returns a command that stats the file at path.
+func statWatched(gen int, path string, fsys fileSystem) tea.Cmd {
+	return func() tea.Msg {
+		fi, err := fsys.Stat(path)
+		if err != nil {
+			return watchStatMsg{gen: gen, path: path, err: err}
+		}
+		return watchStatMsg{gen: gen, path: path, modTime: fi.ModTime(), size: fi.Size()}
+	}
+}
+
+// startWatch starts watching the active tab's file.
+func (r *markdownReader) startWatch() tea.Cmd {
+	r.watch.enabled = true
+	r.watch.gen++
+	r.watch.path = ""
+	return watchTick(r.watch.gen)
+}
+
+// toggleWatch turns watch mode on or off.
+func (r *markdownReader) toggleWatch() tea.Cmd {
+	if r.watch.enabled {
+		r.watch.enabled = false
+		return r.flashStatus("Stopped watching for changes")
+	}
+	return tea.Batch(r.startWatch(), r.flashStatus("Watching for changes"))
+}
+
+// watchedSource returns the local file shown in the active tab, if any.
+func (r *markdownReader) watchedSource() (string, bool) {
+	source := r.active().currentSource
+	if !isLocalSource(source) || (!isMarkdownFile(source) && !isConvertibleFile(source, r.registry)) {
+		return "", false
+	}
+	return source, true
+}
+
+// checkWatched stats the watched file, or schedules the next check if the
+// active tab does not show a local file.
+func (r *markdownReader) checkWatched(msg watchTickMsg) tea.Cmd {
+	if !r.watch.enabled || msg.gen != r.watch.gen {
+		return nil
+	}
+	path, ok := r.watchedSource()
+	if !ok {
+		r.watch.path = ""
+		return watchTick(r.watch.gen)
+	}
+	return statWatched(r.watch.gen, path, r.fsys)
+}
+
+// updateWatched records the state of the watched file and reloads the active
+// tab if the file has changed since it was last checked. The first check of a
+// file only records its state.
+func (r *markdownReader) updateWatched(msg watchStatMsg) tea.Cmd {
+	if !r.watch.enabled || msg.gen != r.watch.gen {
+		return nil
+	}
+	next := watchTick(r.watch.gen)
+	if msg.err != nil {
+		// The file may be briefly missing while an editor replaces it.
+		r.logger.Debug("watch_stat_error", "path", msg.path, "error", msg.err)
+		return next
+	}
+
+	changed := msg.path == r.watch.path && (!msg.modTime.Equal(r.watch.modTime) || msg.size != r.watch.size)
+	if changed && r.loading {
+		// Try again once the page being loaded is shown.
+		return next
+	}
+	r.watch.path, r.watch.modTime, r.watch.size = msg.path, msg.modTime, msg.size
+	if !changed || r.active().currentSource != msg.path {
+		return next
+	}
+
+	r.logger.Info("watch_reload", "path", msg.path)
+	if reload := r.pageReloader(msg.path); reload != nil {
+		return tea.Batch(reload, next)
+	}
+	return next
+}
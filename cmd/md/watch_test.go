@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var watchDoc = "# Live\n\n" + strings.Repeat("Intro.\n\n", 20) + "## Details\n\nThe details.\n\n" + strings.Repeat("More.\n\n", 20)
+
+// watchReader returns a sized reader for a local file in watch mode.
+func watchReader(t *testing.T) markdownReader {
+	t.Helper()
+	r := testReader("", watchDoc, "/docs/live.md")
+	r.fsys.(*memFS).files["/docs/live.md"] = []byte(watchDoc)
+	model, _ := r.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	r = press(t, model.(markdownReader), "R")
+	if !r.watch.enabled {
+		t.Fatal("expected watch mode to start")
+	}
+	return r
+}
+
+// checkWatch delivers a watch tick and the resulting stat to the reader and
+// returns the command that follows.
+func checkWatch(t *testing.T, r markdownReader) (markdownReader, tea.Cmd) {
+	t.Helper()
+	model, cmd := r.Update(watchTickMsg{gen: r.watch.gen})
+	if cmd == nil {
+		t.Fatal("expected the watched file to be checked")
+	}
+	model, cmd = model.Update(cmd())
+	return model.(markdownReader), cmd
+}
+
+func TestWatch_ReloadsChangedFile(t *testing.T) {
+	r := watchReader(t)
+	r.active().view.SelectAnchor("details")
+	r.active().view.ScrollDown(1)
+	top := strings.TrimSpace(strings.Split(ansi.Strip(r.active().view.View()), "\n")[0])
+
+	// The first check only records the file's state.
+	r, _ = checkWatch(t, r)
+	if r.watch.path != "/docs/live.md" {
+		t.Fatalf("watched path = %q", r.watch.path)
+	}
+
+	edited := strings.Replace(watchDoc, "# Live\n\n", "# Live\n\nA new paragraph.\n\n", 1)
+	r.fsys.(*memFS).files["/docs/live.md"] = []byte(edited)
+	r, cmd := checkWatch(t, r)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected a reload and the next check, got %#v", cmd())
+	}
+	model, _ := r.Update(batch[0]())
+	r = model.(markdownReader)
+
+	at := r.active()
+	if string(at.view.GetMarkdown()) != edited {
+		t.Fatal("expected the edited file to be shown")
+	}
+	if got := strings.TrimSpace(strings.Split(ansi.Strip(at.view.View()), "\n")[0]); got != top {
+		t.Errorf("top line = %q, want %q", got, top)
+	}
+	if len(at.pageStack) != 0 {
+		t.Error("expected a reload not to push the page")
+	}
+}
+
+func TestWatch_Toggle(t *testing.T) {
+	r := watchReader(t)
+	gen := r.watch.gen
+	r = press(t, r, "R")
+	if r.watch.enabled {
+		t.Fatal("expected watch mode to stop")
+	}
+	if _, cmd := r.Update(watchTickMsg{gen: gen}); cmd != nil {
+		t.Error("expected ticks to stop with watch mode")
+	}
+
+	// Ticks from before watch mode was restarted are ignored.
+	r = press(t, r, "R")
+	if _, cmd := r.Update(watchTickMsg{gen: gen}); cmd != nil {
+		t.Error("expected a stale tick to be ignored")
+	}
+}
+
+func TestWatch_SkipsRemoteDocuments(t *testing.T) {
+	r := testReader("", "# Remote\n", "https://example.com/doc.md")
+	r.watch.enabled = true
+	model, cmd := r.Update(watchTickMsg{gen: r.watch.gen})
+	if cmd == nil {
+		t.Fatal("expected the next check to be scheduled")
+	}
+	if model.(markdownReader).watch.path != "" {
+		t.Error("expected no file to be watched")
+	}
+}
@@ -1,26 +1,39 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/odt"
 )
 
 func main() {
-	if len(os.Args) != 2 {
+	frontMatter := flag.Bool("f", false, "hide YAML front matter")
+	typographer := flag.Bool("t", false, "substitute typographic quotes and dashes")
+	attributes := flag.Bool("a", false, "parse heading attributes")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
 		fmt.Fprintf(os.Stderr, "usage: %v [path to markdown file]\n", filepath.Base(os.Args[0]))
 		os.Exit(-1)
 	}
+	path := flag.Arg(0)
 
-	doc, err := os.ReadFile(os.Args[1])
+	doc, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to read %v: %v\n", os.Args[1], err)
+		fmt.Fprintf(os.Stderr, "failed to read %v: %v\n", path, err)
 		os.Exit(-1)
 	}
 
-	if err = odt.FromMarkdown(os.Stdout, doc); err != nil {
+	parser := markdown.NewParser(
+		markdown.WithFrontMatter(*frontMatter),
+		markdown.WithTypographer(*typographer),
+		markdown.WithAttributes(*attributes),
+	)
+	if err = odt.FromMarkdown(os.Stdout, doc, odt.WithParser(parser)); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to convert markdown: %v\n", err)
 		os.Exit(-1)
 	}
@@ -11,13 +11,10 @@ import (
 
 	"github.com/alecthomas/chroma"
 	"github.com/eliukblau/pixterm/pkg/ansimage"
-	"github.com/pgavlin/goldmark"
-	"github.com/pgavlin/goldmark/extension"
-	goldmark_parser "github.com/pgavlin/goldmark/parser"
 	goldmark_renderer "github.com/pgavlin/goldmark/renderer"
-	"github.com/pgavlin/goldmark/text"
 	"github.com/pgavlin/goldmark/util"
 	"github.com/pgavlin/markdown-kit/diagram"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/renderer"
 	"github.com/pgavlin/markdown-kit/styles"
 	_ "github.com/pgavlin/svg2"
@@ -31,6 +28,9 @@ func main() {
 	width := flag.Uint("w", 0, "the maximum line width for wrappable content")
 	images := flag.Bool("i", true, "display images")
 	hyperlinks := flag.Bool("l", false, "display hyperlinks instead of link text")
+	frontMatter := flag.Bool("f", false, "hide YAML front matter")
+	typographer := flag.Bool("t", false, "substitute typographic quotes and dashes")
+	attributes := flag.Bool("a", false, "parse heading attributes")
 	flag.Parse()
 
 	if flag.NArg() != 1 {
@@ -57,11 +57,12 @@ func main() {
 		}
 	}
 
-	parser := goldmark.DefaultParser()
-	parser.AddOptions(goldmark_parser.WithParagraphTransformers(
-		util.Prioritized(extension.NewTableParagraphTransformer(), 200),
-	))
-	document := parser.Parse(text.NewReader(source))
+	parser := markdown.NewParser(
+		markdown.WithFrontMatter(*frontMatter),
+		markdown.WithTypographer(*typographer),
+		markdown.WithAttributes(*attributes),
+	)
+	document := parser.Parse(source)
 
 	imageEncoder := renderer.KittyGraphicsEncoder()
 	if *images && !supportsImages {
@@ -1,8 +1,10 @@
 package docsearch
 
 import (
-	"regexp"
 	"strings"
+
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/markdown"
 )
 
 // chunk represents a section of a document for embedding.
@@ -11,38 +13,38 @@ type chunk struct {
 	text    string // plain text content to embed
 }
 
-var reHeadingLine = regexp.MustCompile(`^(#{1,6})\s+(.+)`)
-
 // chunkMarkdown splits markdown into heading-based sections, strips formatting,
 // and returns chunks suitable for embedding. Each chunk includes the heading
 // hierarchy as context. Chunks exceeding maxLen are split into overlapping windows.
-func chunkMarkdown(markdown string, maxLen int) []chunk {
+// The markdown is parsed with the given parser, and sections begin at the
+// document's top-level headings.
+func chunkMarkdown(p *markdown.Parser, source string, maxLen int) []chunk {
 	if maxLen <= 0 {
 		maxLen = 2000
 	}
 
-	lines := strings.Split(markdown, "\n")
+	src := []byte(source)
+	doc := p.Parse(src)
 
 	type section struct {
 		level   int
 		heading string
-		lines   []string
+		blocks  []ast.Node
 	}
 
 	var sections []section
 	current := section{level: 0, heading: ""}
 
-	for _, line := range lines {
-		if m := reHeadingLine.FindStringSubmatch(line); m != nil {
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.Heading); ok {
 			// Save previous section.
 			sections = append(sections, current)
 
-			level := len(m[1])
-			heading := stripMarkdown(strings.TrimSpace(m[2]))
-			current = section{level: level, heading: heading}
+			heading := strings.Join(strings.Fields(inlineText(src, h)), " ")
+			current = section{level: h.Level, heading: heading}
 			continue
 		}
-		current.lines = append(current.lines, line)
+		current.blocks = append(current.blocks, n)
 	}
 	// Save the last section.
 	sections = append(sections, current)
@@ -50,7 +52,7 @@ func chunkMarkdown(markdown string, maxLen int) []chunk {
 	// If there are no headings (only section 0 with no heading), treat the
 	// entire document as one chunk.
 	if len(sections) == 1 && sections[0].heading == "" {
-		text := stripMarkdown(markdown)
+		text := plainText(src, children(doc))
 		if text == "" {
 			return nil
 		}
@@ -63,7 +65,7 @@ func chunkMarkdown(markdown string, maxLen int) []chunk {
 
 	var chunks []chunk
 	for _, sec := range sections {
-		if sec.heading == "" && len(sec.lines) == 0 {
+		if sec.heading == "" && len(sec.blocks) == 0 {
 			continue
 		}
 
@@ -88,7 +90,7 @@ func chunkMarkdown(markdown string, maxLen int) []chunk {
 		headingPath := strings.Join(pathParts, " > ")
 
 		// Strip markdown from the section body.
-		body := stripMarkdown(strings.Join(sec.lines, "\n"))
+		body := plainText(src, sec.blocks)
 		if body == "" {
 			continue
 		}
@@ -106,7 +108,7 @@ func chunkMarkdown(markdown string, maxLen int) []chunk {
 
 	if len(chunks) == 0 {
 		// All sections were empty after stripping; fall back to whole-document.
-		text := stripMarkdown(markdown)
+		text := plainText(src, children(doc))
 		if text == "" {
 			return nil
 		}
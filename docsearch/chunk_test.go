@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -94,7 +95,7 @@ func TestChunkMarkdown(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := chunkMarkdown(tt.markdown, tt.maxLen)
+			result := chunkMarkdown(markdown.NewParser(), tt.markdown, tt.maxLen)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -103,8 +104,8 @@ func TestChunkMarkdown(t *testing.T) {
 func TestChunkMarkdownLongSection(t *testing.T) {
 	// Create a section longer than maxLen.
 	long := strings.Repeat("word ", 100) // 500 chars
-	markdown := "# Title\n\n" + long
-	chunks := chunkMarkdown(markdown, 100)
+	source := "# Title\n\n" + long
+	chunks := chunkMarkdown(markdown.NewParser(), source, 100)
 
 	require.True(t, len(chunks) > 1, "should split into multiple chunks")
 
@@ -118,3 +119,18 @@ func TestChunkMarkdownLongSection(t *testing.T) {
 		assert.LessOrEqual(t, len(c.text), 100)
 	}
 }
+
+func TestChunkMarkdownParser(t *testing.T) {
+	source := "---\ntitle: Notes\n---\n\n# Setup\n\n```sh\n# not a heading\nmake\n```\n"
+
+	// Lines in code blocks do not start sections.
+	chunks := chunkMarkdown(markdown.NewParser(), source, 2000)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "Setup", chunks[0].heading)
+	assert.Contains(t, chunks[0].text, "# not a heading\nmake")
+
+	// Front matter is not indexed when the parser recognizes it.
+	chunks = chunkMarkdown(markdown.NewParser(markdown.WithFrontMatter(true)), source, 2000)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, "Setup:\n# not a heading\nmake", chunks[0].text)
+}
@@ -10,6 +10,7 @@ import (
 
 	vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/pgavlin/markdown-kit/markdown"
 )
 
 func init() {
@@ -28,11 +29,25 @@ type Result struct {
 type Index struct {
 	db       *sql.DB
 	embedder Embedder
+	parser   *markdown.Parser
+}
+
+// An Option configures an Index.
+type Option func(idx *Index)
+
+// WithParser sets the parser used to chunk and strip documents for
+// embedding. Indexing with the parser used to display documents keeps
+// sections and text in agreement with what is shown. The default parser is
+// markdown.NewParser().
+func WithParser(p *markdown.Parser) Option {
+	return func(idx *Index) {
+		idx.parser = p
+	}
 }
 
 // Open opens (or creates) the index database at the given path.
 // If embedder is nil, only keyword search will be available.
-func Open(path string, embedder Embedder) (*Index, error) {
+func Open(path string, embedder Embedder, options ...Option) (*Index, error) {
 	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_foreign_keys=on")
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
@@ -50,7 +65,11 @@ func Open(path string, embedder Embedder) (*Index, error) {
 		}
 	}
 
-	return &Index{db: db, embedder: embedder}, nil
+	idx := &Index{db: db, embedder: embedder, parser: markdown.NewParser()}
+	for _, o := range options {
+		o(idx)
+	}
+	return idx, nil
 }
 
 // Close closes the database.
@@ -168,7 +187,7 @@ func (idx *Index) updateEmbeddings(ctx context.Context, docID int64, markdown st
 	}
 
 	// Chunk the markdown.
-	chunks := chunkMarkdown(markdown, 2000)
+	chunks := chunkMarkdown(idx.parser, markdown, 2000)
 	if len(chunks) == 0 {
 		// Nothing to embed (e.g. empty document).
 		return nil
@@ -304,10 +323,10 @@ func (idx *Index) FindSimilar(ctx context.Context, content, excludePath string,
 	}
 
 	// Chunk and embed the query content.
-	queryChunks := chunkMarkdown(content, 2000)
+	queryChunks := chunkMarkdown(idx.parser, content, 2000)
 	if len(queryChunks) == 0 {
 		// Nothing to search with — embed the raw content as fallback.
-		stripped := stripMarkdown(content)
+		stripped := stripMarkdown(idx.parser, content)
 		if stripped == "" {
 			return nil, nil
 		}
@@ -1,110 +1,143 @@
 package docsearch
 
 import (
+	"bytes"
 	"regexp"
 	"strings"
-)
-
-var (
-	// Heading prefixes: lines starting with 1-6 '#' chars followed by a space.
-	reHeadingPrefix = regexp.MustCompile(`^#{1,6}\s+`)
-
-	// Setext heading underlines: lines of only '=' or '-' (at least 3).
-	reSetextUnderline = regexp.MustCompile(`^(?:={3,}|-{3,})\s*$`)
 
-	// Code fence lines: ``` or ~~~ optionally followed by a language tag.
-	reCodeFence = regexp.MustCompile("^(?:```|~~~)")
-
-	// Links: [text](url) — capture the text.
-	reLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	"github.com/pgavlin/goldmark/ast"
+	xast "github.com/pgavlin/goldmark/extension/ast"
+	"github.com/pgavlin/markdown-kit/markdown"
+)
 
-	// Images: ![alt](url) — capture the alt text.
-	reImage = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+// HTML tags.
+var reHTMLTag = regexp.MustCompile(`<[^>]+>`)
 
-	// HTML tags.
-	reHTMLTag = regexp.MustCompile(`<[^>]+>`)
+// stripMarkdown removes markdown formatting from the given text, returning
+// plain text suitable for embedding. The text is parsed with the given
+// parser, so that it is stripped according to the same document structure
+// that is displayed.
+func stripMarkdown(p *markdown.Parser, source string) string {
+	src := []byte(source)
+	return plainText(src, children(p.Parse(src)))
+}
 
-	// Table alignment rows: lines like |---|---|, | :---: | --- |, etc.
-	reTableAlignRow = regexp.MustCompile(`^\|?[\s:]*-{3,}[\s:]*(\|[\s:]*-{3,}[\s:]*)*\|?\s*$`)
+// children returns the children of the given node.
+func children(n ast.Node) []ast.Node {
+	var result []ast.Node
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		result = append(result, c)
+	}
+	return result
+}
 
-	// Bold/italic markers: **, __, *, _, ~~
-	reEmphasis = regexp.MustCompile(`(\*{1,2}|_{1,2}|~~)`)
-)
+// plainText returns the plain text of the given blocks. Blocks are separated
+// by a newline, or by a blank line if they are separated by blank lines in
+// the source. Runs of blank lines are collapsed.
+func plainText(source []byte, blocks []ast.Node) string {
+	var sb strings.Builder
+	writeBlocks(&sb, source, blocks)
 
-// stripMarkdown removes markdown formatting from the given text, returning
-// plain text suitable for embedding. It operates line-by-line without
-// requiring a full markdown parser.
-func stripMarkdown(markdown string) string {
-	lines := strings.Split(markdown, "\n")
 	var result []string
-	inCodeBlock := false
 	lastBlank := false
-
-	for _, line := range lines {
-		// Handle code fences.
-		if reCodeFence.MatchString(strings.TrimSpace(line)) {
-			inCodeBlock = !inCodeBlock
+	for _, line := range strings.Split(sb.String(), "\n") {
+		blank := strings.TrimSpace(line) == ""
+		if blank && lastBlank {
 			continue
 		}
+		lastBlank = blank
+		result = append(result, line)
+	}
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
 
-		// Inside code blocks, keep the content as-is.
-		if inCodeBlock {
-			result = append(result, line)
-			lastBlank = false
-			continue
+// writeBlocks writes the plain text of the given blocks.
+func writeBlocks(sb *strings.Builder, source []byte, blocks []ast.Node) {
+	for i, b := range blocks {
+		if i > 0 {
+			sb.WriteByte('\n')
+			if b.HasBlankPreviousLines() {
+				sb.WriteByte('\n')
+			}
 		}
+		writeBlock(sb, source, b)
+	}
+}
 
-		// Remove setext underlines.
-		if reSetextUnderline.MatchString(line) {
-			continue
+// writeBlock writes the plain text of a block.
+func writeBlock(sb *strings.Builder, source []byte, n ast.Node) {
+	switch n := n.(type) {
+	case *ast.CodeBlock, *ast.FencedCodeBlock:
+		// Keep code as-is.
+		var code bytes.Buffer
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			code.Write(line.Value(source))
 		}
-
-		// Remove table alignment rows.
-		if reTableAlignRow.MatchString(line) {
-			continue
+		sb.Write(bytes.TrimRight(code.Bytes(), "\n"))
+	case *ast.HTMLBlock:
+		lines := n.Lines()
+		var text []string
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			if l := strings.TrimSpace(reHTMLTag.ReplaceAllString(string(line.Value(source)), "")); l != "" {
+				text = append(text, l)
+			}
 		}
-
-		// Strip heading prefixes.
-		line = reHeadingPrefix.ReplaceAllString(line, "")
-
-		// Strip images before links (images contain links).
-		line = reImage.ReplaceAllString(line, "$1")
-
-		// Strip links.
-		line = reLink.ReplaceAllString(line, "$1")
-
-		// Strip inline code backticks.
-		line = strings.ReplaceAll(line, "`", "")
-
-		// Strip emphasis markers.
-		line = reEmphasis.ReplaceAllString(line, "")
-
-		// Strip HTML tags.
-		line = reHTMLTag.ReplaceAllString(line, "")
-
-		// Strip table pipes.
-		if strings.Contains(line, "|") {
-			line = strings.ReplaceAll(line, "|", " ")
-			// Collapse multiple spaces from pipe removal.
-			for strings.Contains(line, "  ") {
-				line = strings.ReplaceAll(line, "  ", " ")
+		sb.WriteString(strings.Join(text, "\n"))
+	case *xast.Table:
+		var rows []string
+		for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				if text := strings.Join(strings.Fields(inlineText(source, cell)), " "); text != "" {
+					cells = append(cells, text)
+				}
 			}
+			rows = append(rows, strings.Join(cells, " "))
 		}
-
-		line = strings.TrimSpace(line)
-
-		// Collapse multiple blank lines.
-		if line == "" {
-			if lastBlank {
-				continue
+		sb.WriteString(strings.Join(rows, "\n"))
+	default:
+		switch {
+		case n.IsRaw() || !n.HasChildren():
+			// Thematic breaks, link reference definitions, front matter, etc.
+		case n.FirstChild().Type() == ast.TypeInline:
+			lines := strings.Split(inlineText(source, n), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimSpace(line)
 			}
-			lastBlank = true
-		} else {
-			lastBlank = false
+			sb.WriteString(strings.Join(lines, "\n"))
+		default:
+			writeBlocks(sb, source, children(n))
 		}
-
-		result = append(result, line)
 	}
+}
 
-	return strings.TrimSpace(strings.Join(result, "\n"))
+// inlineText returns the text of the inline children of the given node. Line
+// breaks are kept; raw HTML is removed.
+func inlineText(source []byte, n ast.Node) string {
+	var sb strings.Builder
+	var walk func(n ast.Node)
+	walk = func(n ast.Node) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch c := c.(type) {
+			case *ast.Text:
+				sb.Write(c.Segment.Value(source))
+				if c.SoftLineBreak() || c.HardLineBreak() {
+					sb.WriteByte('\n')
+				}
+			case *ast.String:
+				sb.Write(c.Value)
+			case *ast.AutoLink:
+				sb.Write(c.Label(source))
+			case *ast.RawHTML:
+				// Remove tags.
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return sb.String()
 }
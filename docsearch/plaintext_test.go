@@ -3,6 +3,7 @@ package docsearch
 import (
 	"testing"
 
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,7 +92,7 @@ func TestStripMarkdown(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := stripMarkdown(tt.input)
+			result := stripMarkdown(markdown.NewParser(), tt.input)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -0,0 +1,89 @@
+package docsearch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Position is a saved reading position in a document. It is relative to a
+// section heading rather than to the start of the document so that it
+// survives edits elsewhere in the document.
+type Position struct {
+	// Anchor is the anchor of the heading of the section being read, or empty
+	// if the position is above the first heading.
+	Anchor string
+	// Offset is the source byte offset of the position relative to the start
+	// of the section's heading, or to the start of the document if Anchor is
+	// empty.
+	Offset int
+	// UpdatedAt is the time the position was saved.
+	UpdatedAt time.Time
+}
+
+// SavePosition records the reading position for the document at path,
+// replacing any previous position.
+func (idx *Index) SavePosition(ctx context.Context, path string, pos Position) error {
+	_, err := idx.db.ExecContext(ctx,
+		`INSERT INTO positions (path, anchor, section_offset, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET anchor = excluded.anchor, section_offset = excluded.section_offset, updated_at = excluded.updated_at`,
+		path, pos.Anchor, pos.Offset, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("saving position: %w", err)
+	}
+	return nil
+}
+
+// Position returns the saved reading position for the document at path. The
+// boolean result is false if no position has been saved.
+func (idx *Index) Position(ctx context.Context, path string) (Position, bool, error) {
+	var pos Position
+	var updatedAt int64
+	err := idx.db.QueryRowContext(ctx,
+		`SELECT anchor, section_offset, updated_at FROM positions WHERE path = ?`, path,
+	).Scan(&pos.Anchor, &pos.Offset, &updatedAt)
+	if err == sql.ErrNoRows {
+		return Position{}, false, nil
+	}
+	if err != nil {
+		return Position{}, false, fmt.Errorf("looking up position: %w", err)
+	}
+	pos.UpdatedAt = time.Unix(updatedAt, 0)
+	return pos, true, nil
+}
+
+// PrunePositions removes the saved positions of documents for which keep
+// returns false, such as files that have been deleted. It returns the number
+// of positions removed.
+func (idx *Index) PrunePositions(ctx context.Context, keep func(path string) bool) (int, error) {
+	rows, err := idx.db.QueryContext(ctx, `SELECT path FROM positions`)
+	if err != nil {
+		return 0, fmt.Errorf("listing positions: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning position: %w", err)
+		}
+		if !keep(path) {
+			stale = append(stale, path)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, path := range stale {
+		if _, err := idx.db.ExecContext(ctx, `DELETE FROM positions WHERE path = ?`, path); err != nil {
+			return 0, fmt.Errorf("removing position: %w", err)
+		}
+	}
+	return len(stale), nil
+}
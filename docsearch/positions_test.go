@@ -0,0 +1,76 @@
+package docsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosition(t *testing.T) {
+	ctx := context.Background()
+	idx, err := Open(testDBPath(t), nil)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	_, ok, err := idx.Position(ctx, "/docs/a.md")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, idx.SavePosition(ctx, "/docs/a.md", Position{Anchor: "setup", Offset: 42}))
+	pos, ok, err := idx.Position(ctx, "/docs/a.md")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "setup", pos.Anchor)
+	assert.Equal(t, 42, pos.Offset)
+	assert.False(t, pos.UpdatedAt.IsZero())
+
+	// Saving again replaces the position.
+	require.NoError(t, idx.SavePosition(ctx, "/docs/a.md", Position{Offset: 7}))
+	pos, _, err = idx.Position(ctx, "/docs/a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "", pos.Anchor)
+	assert.Equal(t, 7, pos.Offset)
+}
+
+func TestPositionPersistence(t *testing.T) {
+	ctx := context.Background()
+	dbPath := testDBPath(t)
+
+	idx, err := Open(dbPath, nil)
+	require.NoError(t, err)
+	require.NoError(t, idx.SavePosition(ctx, "https://example.com/doc", Position{Anchor: "usage", Offset: 3}))
+	require.NoError(t, idx.Close())
+
+	idx, err = Open(dbPath, nil)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	pos, ok, err := idx.Position(ctx, "https://example.com/doc")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "usage", pos.Anchor)
+}
+
+func TestPrunePositions(t *testing.T) {
+	ctx := context.Background()
+	idx, err := Open(testDBPath(t), nil)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	for _, path := range []string{"/docs/kept.md", "/docs/deleted.md", "/docs/also-deleted.md"} {
+		require.NoError(t, idx.SavePosition(ctx, path, Position{}))
+	}
+
+	n, err := idx.PrunePositions(ctx, func(path string) bool { return path == "/docs/kept.md" })
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, ok, err := idx.Position(ctx, "/docs/kept.md")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = idx.Position(ctx, "/docs/deleted.md")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
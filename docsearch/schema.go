@@ -51,6 +51,14 @@ func createSchema(db *sql.DB) error {
 			content     TEXT NOT NULL DEFAULT ''
 		)`,
 		`CREATE INDEX IF NOT EXISTS chunks_document_id ON chunks(document_id)`,
+		// Reading positions, keyed by path. Documents need not be indexed to
+		// have a position.
+		`CREATE TABLE IF NOT EXISTS positions (
+			path           TEXT PRIMARY KEY,
+			anchor         TEXT NOT NULL DEFAULT '',
+			section_offset INTEGER NOT NULL DEFAULT 0,
+			updated_at     INTEGER NOT NULL
+		)`,
 	}
 
 	for _, stmt := range statements {
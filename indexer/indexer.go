@@ -3,6 +3,7 @@ package indexer
 import (
 	"bytes"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pgavlin/goldmark/ast"
@@ -216,6 +217,16 @@ func (index *DocumentIndex) LookupNode(anchor string) ([]ast.Node, bool) {
 	return nodes, ok
 }
 
+// AnchorIDs returns the IDs of all HTML anchors in the document, sorted.
+func (index *DocumentIndex) AnchorIDs() []string {
+	ids := make([]string, 0, len(index.nodeAnchors))
+	for id := range index.nodeAnchors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // AnchorNodes returns the set of all AST nodes that define HTML anchors.
 func (index *DocumentIndex) AnchorNodes() map[ast.Node]bool {
 	if len(index.nodeAnchors) == 0 {
@@ -613,3 +613,11 @@ func TestIndex_DeeplyNested(t *testing.T) {
 		}
 	}
 }
+
+func TestAnchorIDs(t *testing.T) {
+	source := []byte("<a id=\"zeta\"></a>\n\n# One\n\n<a name=\"alpha\"></a>\n\n## Two\n")
+	doc := parseMarkdown(t, source)
+	idx := Index(doc, source)
+
+	assert.Equal(t, []string{"alpha", "zeta"}, idx.AnchorIDs())
+}
@@ -0,0 +1,129 @@
+package markdown
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/parser"
+	"github.com/pgavlin/goldmark/text"
+	"gopkg.in/yaml.v3"
+)
+
+// KindFrontMatter is the kind of FrontMatter nodes.
+var KindFrontMatter = ast.NewNodeKind("FrontMatter")
+
+// FrontMatter is the YAML front matter of a document. Its lines hold the YAML source, without the delimiters. Front
+// matter is only parsed if enabled with WithFrontMatter. Outputs do not render front matter.
+type FrontMatter struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node.Kind.
+func (n *FrontMatter) Kind() ast.NodeKind {
+	return KindFrontMatter
+}
+
+// IsRaw implements ast.Node.IsRaw.
+func (n *FrontMatter) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.Dump.
+func (n *FrontMatter) Dump(w io.Writer, source []byte, level int) {
+	ast.DumpHelper(w, n, source, level, nil, nil)
+}
+
+// Text returns the YAML source of the front matter.
+func (n *FrontMatter) Text(source []byte) []byte {
+	var buf bytes.Buffer
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(source))
+	}
+	return buf.Bytes()
+}
+
+// Decode decodes the front matter into v, as with yaml.Unmarshal.
+func (n *FrontMatter) Decode(source []byte, v any) error {
+	return yaml.Unmarshal(n.Text(source), v)
+}
+
+// GetFrontMatter returns the front matter of the given document, or nil if it has none.
+func GetFrontMatter(doc ast.Node) *FrontMatter {
+	if doc == nil {
+		return nil
+	}
+	fm, _ := doc.FirstChild().(*FrontMatter)
+	return fm
+}
+
+// advanceLine advances the reader to the end of the current line, before its newline.
+func advanceLine(reader text.Reader) {
+	line, segment := reader.PeekLine()
+	n := segment.Len()
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		n--
+	}
+	reader.Advance(n)
+}
+
+// frontMatterParser parses YAML front matter: a block that starts with a "---" line on the first line of the
+// document and ends with a "---" or "..." line.
+type frontMatterParser struct{}
+
+// isFrontMatterDelimiter returns true if the given line is a front matter delimiter.
+func isFrontMatterDelimiter(line []byte, closing bool) bool {
+	line = bytes.TrimRight(line, " \t\r\n")
+	return string(line) == "---" || closing && string(line) == "..."
+}
+
+func (frontMatterParser) Trigger() []byte {
+	return []byte{'-'}
+}
+
+func (frontMatterParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	if parent.Kind() != ast.KindDocument || segment.Start != 0 || !isFrontMatterDelimiter(line, false) {
+		return nil, parser.NoChildren
+	}
+
+	// Only parse front matter that is closed.
+	rest := reader.Source()[segment.Stop:]
+	closed := false
+	for len(rest) > 0 && !closed {
+		end := bytes.IndexByte(rest, '\n') + 1
+		if end == 0 {
+			end = len(rest)
+		}
+		closed, rest = isFrontMatterDelimiter(rest[:end], true), rest[end:]
+	}
+	if !closed {
+		return nil, parser.NoChildren
+	}
+
+	advanceLine(reader)
+	return &FrontMatter{}, parser.NoChildren
+}
+
+func (frontMatterParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if isFrontMatterDelimiter(line, true) {
+		advanceLine(reader)
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	advanceLine(reader)
+	return parser.Continue | parser.NoChildren
+}
+
+func (frontMatterParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (frontMatterParser) CanInterruptParagraph() bool {
+	return false
+}
+
+func (frontMatterParser) CanAcceptIndentedLine() bool {
+	return true
+}
@@ -0,0 +1,162 @@
+// Package markdown configures the Markdown parser shared by the packages and commands in this module, so that a
+// document has the same structure whether it is viewed, printed, converted, or indexed.
+package markdown
+
+import (
+	"sync"
+
+	"github.com/pgavlin/goldmark"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/extension"
+	"github.com/pgavlin/goldmark/parser"
+	"github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/goldmark/util"
+)
+
+// A Parser parses Markdown documents. Parsers are created with NewParser and may be used concurrently.
+type Parser struct {
+	tables        bool
+	strikethrough bool
+	frontMatter   bool
+	typographer   bool
+	attributes    bool
+	extensions    []goldmark.Extender
+	options       []parser.Option
+
+	once   sync.Once
+	parser parser.Parser
+}
+
+// An Option configures a Parser.
+type Option func(p *Parser)
+
+// WithTables enables or disables GFM tables. Tables are enabled by default.
+func WithTables(on bool) Option {
+	return func(p *Parser) {
+		p.tables = on
+	}
+}
+
+// WithStrikethrough enables or disables GFM strikethrough (~~text~~). Strikethrough is enabled by default.
+func WithStrikethrough(on bool) Option {
+	return func(p *Parser) {
+		p.strikethrough = on
+	}
+}
+
+// WithFrontMatter enables or disables YAML front matter. When enabled, a block delimited by "---" lines at the very
+// start of a document is parsed as a FrontMatter node rather than as Markdown. Front matter is disabled by default.
+func WithFrontMatter(on bool) Option {
+	return func(p *Parser) {
+		p.frontMatter = on
+	}
+}
+
+// typographicSubstitutions replaces punctuation with the characters themselves rather than with HTML entities, which
+// the terminal and ODT renderers would print verbatim.
+var typographicSubstitutions = extension.TypographicSubstitutions{
+	extension.LeftSingleQuote:  []byte("‘"),
+	extension.RightSingleQuote: []byte("’"),
+	extension.LeftDoubleQuote:  []byte("“"),
+	extension.RightDoubleQuote: []byte("”"),
+	extension.EnDash:           []byte("–"),
+	extension.EmDash:           []byte("—"),
+	extension.Ellipsis:         []byte("…"),
+	extension.LeftAngleQuote:   []byte("«"),
+	extension.RightAngleQuote:  []byte("»"),
+	extension.Apostrophe:       []byte("’"),
+}
+
+// WithTypographer enables or disables typographic substitutions, e.g. of curly quotes for straight quotes and of
+// dashes for "--" and "---". Typographic substitutions are disabled by default.
+func WithTypographer(on bool) Option {
+	return func(p *Parser) {
+		p.typographer = on
+	}
+}
+
+// WithAttributes enables or disables attributes on headings, e.g. "# Title {#id .class}". Attributes are disabled by
+// default.
+func WithAttributes(on bool) Option {
+	return func(p *Parser) {
+		p.attributes = on
+	}
+}
+
+// WithExtensions adds goldmark extensions to the parser. Only the parser options of the extensions are used;
+// renderers must be registered with each output separately (see e.g. renderer.WithNodeRenderer).
+func WithExtensions(extensions ...goldmark.Extender) Option {
+	return func(p *Parser) {
+		p.extensions = append(p.extensions, extensions...)
+	}
+}
+
+// WithParserOptions adds goldmark parser options to the parser, e.g. parser.WithASTTransformers or
+// parser.WithParagraphTransformers.
+func WithParserOptions(options ...parser.Option) Option {
+	return func(p *Parser) {
+		p.options = append(p.options, options...)
+	}
+}
+
+// NewParser creates a new Parser with the given options.
+func NewParser(options ...Option) *Parser {
+	p := &Parser{tables: true, strikethrough: true}
+	for _, o := range options {
+		o(p)
+	}
+	return p
+}
+
+// With returns a copy of the parser with the given options applied.
+func (p *Parser) With(options ...Option) *Parser {
+	c := &Parser{
+		tables:        p.tables,
+		strikethrough: p.strikethrough,
+		frontMatter:   p.frontMatter,
+		typographer:   p.typographer,
+		attributes:    p.attributes,
+		extensions:    append([]goldmark.Extender(nil), p.extensions...),
+		options:       append([]parser.Option(nil), p.options...),
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// Parse parses the given Markdown source.
+func (p *Parser) Parse(source []byte) ast.Node {
+	p.once.Do(p.init)
+	return p.parser.Parse(text.NewReader(source))
+}
+
+func (p *Parser) init() {
+	options := []parser.Option{}
+	if p.tables {
+		options = append(options, parser.WithParagraphTransformers(
+			util.Prioritized(extension.NewTableParagraphTransformer(), 200),
+		))
+	}
+	if p.strikethrough {
+		options = append(options, parser.WithInlineParsers(
+			util.Prioritized(extension.NewStrikethroughParser(), 500),
+		))
+	}
+	if p.frontMatter {
+		options = append(options, parser.WithBlockParsers(
+			util.Prioritized(frontMatterParser{}, 0),
+		))
+	}
+	if p.typographer {
+		options = append(options, parser.WithInlineParsers(
+			util.Prioritized(extension.NewTypographerParser(extension.WithTypographicSubstitutions(typographicSubstitutions)), 9999),
+		))
+	}
+	if p.attributes {
+		options = append(options, parser.WithAttribute())
+	}
+
+	p.parser = goldmark.New(goldmark.WithExtensions(p.extensions...)).Parser()
+	p.parser.AddOptions(append(options, p.options...)...)
+}
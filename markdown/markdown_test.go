@@ -0,0 +1,101 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/extension"
+	xast "github.com/pgavlin/goldmark/extension/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kinds returns the kinds of the nodes of the given document, in document order.
+func kinds(doc ast.Node) []ast.NodeKind {
+	var result []ast.NodeKind
+	_ = ast.Walk(doc, func(n ast.Node, enter bool) (ast.WalkStatus, error) {
+		if enter {
+			result = append(result, n.Kind())
+		}
+		return ast.WalkContinue, nil
+	})
+	return result
+}
+
+func TestParser_Defaults(t *testing.T) {
+	source := []byte("| a | b |\n|---|---|\n| 1 | 2 |\n\nSome ~~old~~ text.\n")
+	doc := NewParser().Parse(source)
+	assert.Contains(t, kinds(doc), xast.KindTable)
+	assert.Contains(t, kinds(doc), xast.KindStrikethrough)
+
+	doc = NewParser(WithTables(false), WithStrikethrough(false)).Parse(source)
+	assert.NotContains(t, kinds(doc), xast.KindTable)
+	assert.NotContains(t, kinds(doc), xast.KindStrikethrough)
+}
+
+func TestParser_FrontMatter(t *testing.T) {
+	source := []byte("---\ntitle: Notes\ntags: [a, b]\n---\n\n# Heading\n")
+
+	// Front matter is off by default.
+	doc := NewParser().Parse(source)
+	assert.Nil(t, GetFrontMatter(doc))
+	assert.Equal(t, ast.KindThematicBreak, doc.FirstChild().Kind())
+
+	doc = NewParser(WithFrontMatter(true)).Parse(source)
+	fm := GetFrontMatter(doc)
+	require.NotNil(t, fm)
+	assert.Equal(t, "title: Notes\ntags: [a, b]\n", string(fm.Text(source)))
+	var meta struct {
+		Title string   `yaml:"title"`
+		Tags  []string `yaml:"tags"`
+	}
+	require.NoError(t, fm.Decode(source, &meta))
+	assert.Equal(t, "Notes", meta.Title)
+	assert.Equal(t, []string{"a", "b"}, meta.Tags)
+	heading, ok := fm.NextSibling().(*ast.Heading)
+	require.True(t, ok)
+	assert.Equal(t, "Heading", string(heading.Text(source)))
+}
+
+func TestParser_FrontMatterMustBeFirstAndClosed(t *testing.T) {
+	p := NewParser(WithFrontMatter(true))
+	for _, source := range []string{
+		"---\ntitle: Notes\n",
+		"Text\n\n---\ntitle: Notes\n---\n",
+		"\n---\ntitle: Notes\n---\n",
+	} {
+		assert.Nil(t, GetFrontMatter(p.Parse([]byte(source))), "%q", source)
+	}
+}
+
+func TestParser_TypographerAndAttributes(t *testing.T) {
+	source := []byte("# Title {#custom}\n\n\"Quoted\" -- text.\n")
+
+	doc := NewParser().Parse(source)
+	assert.NotContains(t, kinds(doc), ast.KindString)
+	_, ok := doc.FirstChild().AttributeString("id")
+	assert.False(t, ok)
+
+	doc = NewParser(WithTypographer(true), WithAttributes(true)).Parse(source)
+	var strings []string
+	_ = ast.Walk(doc, func(n ast.Node, enter bool) (ast.WalkStatus, error) {
+		if s, ok := n.(*ast.String); ok && enter {
+			strings = append(strings, string(s.Value))
+		}
+		return ast.WalkContinue, nil
+	})
+	assert.Equal(t, []string{"“", "”", "–"}, strings)
+	id, ok := doc.FirstChild().AttributeString("id")
+	require.True(t, ok)
+	assert.Equal(t, "custom", string(id.([]byte)))
+}
+
+func TestParser_With(t *testing.T) {
+	base := NewParser(WithTables(false))
+	derived := base.With(WithExtensions(extension.DefinitionList))
+
+	source := []byte("Term\n: Definition\n\n| a |\n|---|\n")
+	assert.NotContains(t, kinds(base.Parse(source)), xast.KindDefinitionList)
+	assert.Contains(t, kinds(derived.Parse(source)), xast.KindDefinitionList)
+	assert.NotContains(t, kinds(derived.Parse(source)), xast.KindTable)
+}
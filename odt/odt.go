@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/pgavlin/goldmark"
-	mdtext "github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/markdown-kit/markdown"
 )
 
 func writeMimetype(zw *zip.Writer) error {
@@ -44,6 +43,7 @@ func writeManifest(zw *zip.Writer) error {
 type options struct {
 	proportionalFamily string
 	monospaceFamily    string
+	parser             *markdown.Parser
 }
 
 type RenderOption func(opts *options)
@@ -60,8 +60,16 @@ func WithMonospaceFamily(fontFamily string) RenderOption {
 	}
 }
 
-func FromMarkdown(w io.Writer, markdown []byte, renderOptions ...RenderOption) error {
-	var opts options
+// WithParser sets the parser used to parse the Markdown source. By default, the source is parsed with
+// markdown.NewParser().
+func WithParser(parser *markdown.Parser) RenderOption {
+	return func(opts *options) {
+		opts.parser = parser
+	}
+}
+
+func FromMarkdown(w io.Writer, source []byte, renderOptions ...RenderOption) error {
+	opts := options{parser: markdown.NewParser()}
 	for _, o := range renderOptions {
 		o(&opts)
 	}
@@ -81,9 +89,8 @@ func FromMarkdown(w io.Writer, markdown []byte, renderOptions ...RenderOption) e
 		return fmt.Errorf("creating content.xml: %w", err)
 	}
 
-	parser := goldmark.DefaultParser()
 	renderer := NewRenderer(opts.proportionalFamily, opts.monospaceFamily)
-	if err = renderer.Render(content, markdown, parser.Parse(mdtext.NewReader(markdown))); err != nil {
+	if err = renderer.Render(content, source, opts.parser.Parse(source)); err != nil {
 		return fmt.Errorf("rendering content: %w", err)
 	}
 
@@ -7,6 +7,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/pgavlin/goldmark/ast"
+	xast "github.com/pgavlin/goldmark/extension/ast"
 	mdtext "github.com/pgavlin/goldmark/text"
 )
 
@@ -20,6 +21,7 @@ type Renderer struct {
 	monospaceFamily    string
 
 	listStack []listState
+	tables    int
 }
 
 func NewRenderer(proportionalFamily, monospaceFamily string) *Renderer {
@@ -55,6 +57,16 @@ func (r *Renderer) Render(w io.Writer, source []byte, n ast.Node) error {
 		case *ast.ThematicBreak:
 			return r.renderThematicBreak(w, source, n, enter)
 
+		// extension blocks
+		case *xast.Table:
+			return r.renderTable(w, source, n, enter)
+		case *xast.TableHeader:
+			return r.renderTableHeader(w, source, n, enter)
+		case *xast.TableRow:
+			return r.renderTableRow(w, source, n, enter)
+		case *xast.TableCell:
+			return r.renderTableCell(w, source, n, enter)
+
 		// inlines
 		case *ast.AutoLink:
 			return r.renderAutoLink(w, source, n, enter)
@@ -70,6 +82,10 @@ func (r *Renderer) Render(w io.Writer, source []byte, n ast.Node) error {
 			return r.renderText(w, source, n, enter)
 		case *ast.String:
 			return r.renderString(w, source, n, enter)
+
+		// extension inlines
+		case *xast.Strikethrough:
+			return r.renderStrikethrough(w, source, n, enter)
 		}
 
 		return ast.WalkContinue, nil
@@ -145,6 +161,47 @@ const prolog = `<?xml version="1.0" encoding="UTF-8"?>
 		<style:style style:family="text" style:name="Code Span">
 			<style:text-properties style:font-name="Monospace" fo:background-color="#f6f8fa" fo:color="#000000"/>
 		</style:style>
+
+		<!-- Strikethrough -->
+		<style:style style:family="text" style:name="Strikethrough">
+			<style:text-properties style:text-line-through-style="solid" style:text-line-through-type="single"/>
+		</style:style>
+
+		<!-- Table styles -->
+
+		<!-- Table cell contents -->
+		<style:style style:family="paragraph" style:name="Table Contents" style:parent-style-name="Paragraph">
+		</style:style>
+
+		<!-- Table header cell contents -->
+		<style:style style:family="paragraph" style:name="Table Heading" style:parent-style-name="Table Contents">
+			<style:text-properties fo:font-weight="bold"/>
+		</style:style>
+
+		<!-- Aligned table cell contents -->
+		<style:style style:family="paragraph" style:name="Table Contents start" style:parent-style-name="Table Contents">
+			<style:paragraph-properties fo:text-align="start"/>
+		</style:style>
+		<style:style style:family="paragraph" style:name="Table Contents center" style:parent-style-name="Table Contents">
+			<style:paragraph-properties fo:text-align="center"/>
+		</style:style>
+		<style:style style:family="paragraph" style:name="Table Contents end" style:parent-style-name="Table Contents">
+			<style:paragraph-properties fo:text-align="end"/>
+		</style:style>
+		<style:style style:family="paragraph" style:name="Table Heading start" style:parent-style-name="Table Heading">
+			<style:paragraph-properties fo:text-align="start"/>
+		</style:style>
+		<style:style style:family="paragraph" style:name="Table Heading center" style:parent-style-name="Table Heading">
+			<style:paragraph-properties fo:text-align="center"/>
+		</style:style>
+		<style:style style:family="paragraph" style:name="Table Heading end" style:parent-style-name="Table Heading">
+			<style:paragraph-properties fo:text-align="end"/>
+		</style:style>
+
+		<!-- Table cells -->
+		<style:style style:family="table-cell" style:name="Table Cell">
+			<style:table-cell-properties fo:padding="0.04in" fo:border="0.5pt solid #808080"/>
+		</style:style>
 	</office:automatic-styles>
 
 	<office:body>
@@ -368,6 +425,66 @@ func (r *Renderer) renderThematicBreak(w io.Writer, source []byte, node *ast.The
 	return ast.WalkContinue, nil
 }
 
+// renderTable renders an *xast.Table node to the given io.Writer.
+func (r *Renderer) renderTable(w io.Writer, source []byte, node *xast.Table, enter bool) (ast.WalkStatus, error) {
+	if enter {
+		r.tables++
+		fmt.Fprintf(w, "\t\t\t<table:table table:name=\"Table%d\">\n", r.tables)
+		fmt.Fprintf(w, "\t\t\t<table:table-column table:number-columns-repeated=\"%d\"/>\n", len(node.Alignments))
+	} else {
+		fmt.Fprintln(w, "\t\t\t</table:table>")
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderTableHeader renders an *xast.TableHeader node to the given io.Writer.
+func (r *Renderer) renderTableHeader(w io.Writer, source []byte, node *xast.TableHeader, enter bool) (ast.WalkStatus, error) {
+	if enter {
+		fmt.Fprintln(w, "\t\t\t<table:table-header-rows>")
+		fmt.Fprintln(w, "\t\t\t<table:table-row>")
+	} else {
+		fmt.Fprintln(w, "\t\t\t</table:table-row>")
+		fmt.Fprintln(w, "\t\t\t</table:table-header-rows>")
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderTableRow renders an *xast.TableRow node to the given io.Writer.
+func (r *Renderer) renderTableRow(w io.Writer, source []byte, node *xast.TableRow, enter bool) (ast.WalkStatus, error) {
+	if enter {
+		fmt.Fprintln(w, "\t\t\t<table:table-row>")
+	} else {
+		fmt.Fprintln(w, "\t\t\t</table:table-row>")
+	}
+	return ast.WalkContinue, nil
+}
+
+// renderTableCell renders an *xast.TableCell node to the given io.Writer.
+func (r *Renderer) renderTableCell(w io.Writer, source []byte, node *xast.TableCell, enter bool) (ast.WalkStatus, error) {
+	if enter {
+		style := "Table Contents"
+		if node.Parent().Kind() == xast.KindTableHeader {
+			style = "Table Heading"
+		}
+		align := ""
+		switch node.Alignment {
+		case xast.AlignLeft:
+			align = "start"
+		case xast.AlignCenter:
+			align = "center"
+		case xast.AlignRight:
+			align = "end"
+		}
+		if align != "" {
+			style += " " + align
+		}
+		fmt.Fprintf(w, "\t\t\t<table:table-cell table:style-name=\"Table Cell\" office:value-type=\"string\"><text:p text:style-name=\"%s\">", style)
+	} else {
+		fmt.Fprintln(w, "</text:p></table:table-cell>")
+	}
+	return ast.WalkContinue, nil
+}
+
 // renderAutoLink renders an *ast.AutoLink node to the given io.Writer.
 func (r *Renderer) renderAutoLink(w io.Writer, source []byte, node *ast.AutoLink, enter bool) (ast.WalkStatus, error) {
 	if enter {
@@ -402,6 +519,16 @@ func (r *Renderer) renderEmphasis(w io.Writer, source []byte, node *ast.Emphasis
 	return ast.WalkContinue, nil
 }
 
+// renderStrikethrough renders an *xast.Strikethrough node to the given io.Writer.
+func (r *Renderer) renderStrikethrough(w io.Writer, source []byte, node *xast.Strikethrough, enter bool) (ast.WalkStatus, error) {
+	if enter {
+		fmt.Fprint(w, "<text:span text:style-name=\"Strikethrough\">")
+	} else {
+		fmt.Fprint(w, "</text:span>")
+	}
+	return ast.WalkContinue, nil
+}
+
 // renderImage renders an *ast.Image node to the given io.Writer.
 func (r *Renderer) renderImage(w io.Writer, source []byte, node *ast.Image, enter bool) (ast.WalkStatus, error) {
 	return ast.WalkContinue, nil
@@ -8,6 +8,7 @@ import (
 	"github.com/pgavlin/goldmark"
 	"github.com/pgavlin/goldmark/ast"
 	"github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,8 +19,7 @@ func renderMarkdown(t *testing.T, source string) string {
 	t.Helper()
 
 	src := []byte(source)
-	parser := goldmark.DefaultParser()
-	document := parser.Parse(text.NewReader(src))
+	document := markdown.NewParser().Parse(src)
 
 	var buf bytes.Buffer
 	r := NewRenderer("serif", "monospace")
@@ -582,3 +582,23 @@ func TestTextSoftLineBreak(t *testing.T) {
 	assert.Contains(t, output, "line one")
 	assert.Contains(t, output, "line two")
 }
+
+func TestTable(t *testing.T) {
+	source := "| Name | Count |\n|:-----|------:|\n| foo | 1 |\n"
+	output := renderMarkdown(t, source)
+
+	assert.Contains(t, output, `<table:table table:name="Table1">`)
+	assert.Contains(t, output, `<table:table-column table:number-columns-repeated="2"/>`)
+	assert.Contains(t, output, "<table:table-header-rows>")
+	assert.Contains(t, output, `<text:p text:style-name="Table Heading start">Name</text:p>`)
+	assert.Contains(t, output, `<text:p text:style-name="Table Contents end">1</text:p>`)
+	assert.Equal(t, 2, strings.Count(output, "<table:table-row>"))
+	assert.Contains(t, output, "</table:table>")
+}
+
+func TestStrikethrough(t *testing.T) {
+	output := renderMarkdown(t, "Some ~~old~~ text.\n")
+
+	assert.Contains(t, output, `<text:span text:style-name="Strikethrough">old</text:span>`)
+	assert.NotContains(t, output, "~~")
+}
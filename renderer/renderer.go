@@ -131,6 +131,11 @@ func (s *NodeSpan) Contains(offset int) bool {
 // Returns an error for unsupported languages, causing fallback to normal code rendering.
 type DiagramRenderer func(language string, source []byte) (string, error)
 
+// A NodeRenderer renders nodes of a particular kind, e.g. the nodes added by a goldmark extension. It is called with
+// the Renderer, which it should use to write its output (see Renderer) and to add spans for its nodes to the span tree
+// (see OpenBlock, CloseBlock, OpenSpan, and CloseSpan).
+type NodeRenderer func(r *Renderer, w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error)
+
 // An ImageEncoder converts an image to a binary representation that can be displayed by the target output device.
 type ImageEncoder func(w io.Writer, image image.Image, r *Renderer) (int, error)
 
@@ -172,7 +177,7 @@ func ANSIGraphicsEncoder(bg color.Color, ditherMode ansimage.DitheringMode) Imag
 // NodeRenderers that want to override rendering of particular node types should write through the Write* functions
 // provided by Renderer in order to retain proper indentation and prefices inside of lists and block quotes.
 type Renderer struct {
-	theme         *chroma.Style
+	theme         *styles.Theme
 	cols          int
 	rows          int
 	width         int
@@ -184,6 +189,7 @@ type Renderer struct {
 	contentRoot   string
 	imageEncoder    ImageEncoder
 	diagramRenderer DiagramRenderer
+	nodeRenderers   map[ast.NodeKind]NodeRenderer
 	softBreak       bool
 	padToWrap     []int
 	noBreak       int // nesting counter; when > 0, spaces don't break words
@@ -197,8 +203,9 @@ type Renderer struct {
 
 	rootSpan *NodeSpan
 
-	styles      []chroma.StyleEntry
+	styles      []styles.StyleEntry
 	prefixStack []string
+	prefixStyle []chroma.TokenType
 	prefix      []byte
 	wordBuffer  bytes.Buffer
 	lineWidth   int
@@ -212,8 +219,18 @@ type Renderer struct {
 type RendererOption func(r *Renderer)
 
 // WithTheme sets the theme used for colorization during rendering. If the theme is nil, output will not be
-// colorized.
+// colorized. The chroma style is converted using styles.FromChromaStyle; use WithStyles to style individual
+// markdown elements directly.
 func WithTheme(theme *chroma.Style) RendererOption {
+	return func(r *Renderer) {
+		r.theme = styles.FromChromaStyle(theme)
+	}
+}
+
+// WithStyles sets the theme used for colorization during rendering. Unlike WithTheme, the theme may style each
+// markdown element individually (e.g. styles.Heading3, styles.ListBullet, or styles.LinkURL). If the theme is nil,
+// output will not be colorized.
+func WithStyles(theme *styles.Theme) RendererOption {
 	return func(r *Renderer) {
 		r.theme = theme
 	}
@@ -300,6 +317,17 @@ func WithDiagramRenderer(dr DiagramRenderer) RendererOption {
 	}
 }
 
+// WithNodeRenderer sets the function used to render nodes of the given kind. This can be used to render nodes added by
+// goldmark extensions, or to replace the rendering of the standard node kinds.
+func WithNodeRenderer(kind ast.NodeKind, render NodeRenderer) RendererOption {
+	return func(r *Renderer) {
+		if r.nodeRenderers == nil {
+			r.nodeRenderers = map[ast.NodeKind]NodeRenderer{}
+		}
+		r.nodeRenderers[kind] = render
+	}
+}
+
 // WithSoftBreak enables or disables soft line breaks. When soft line breaks are enabled, a soft line break in the
 // input will _not_ be rendered as a newline in the output. When soft line breaks are disabled, a soft line break in
 // the input _will_ be rendered as a newline. In general, soft line breaks should be enabled if word wrapping is
@@ -351,6 +379,21 @@ func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(ast.KindText, r.RenderText)
 	reg.Register(ast.KindString, r.RenderString)
 	reg.Register(ast.KindWhitespace, r.RenderWhitespace)
+
+	// extension inlines
+	reg.Register(xast.KindStrikethrough, r.RenderStrikethrough)
+
+	// custom renderers
+	for kind, render := range r.nodeRenderers {
+		reg.Register(kind, r.nodeRendererFunc(render))
+	}
+}
+
+// nodeRendererFunc adapts a NodeRenderer to a goldmark NodeRendererFunc.
+func (r *Renderer) nodeRendererFunc(render NodeRenderer) renderer.NodeRendererFunc {
+	return func(w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error) {
+		return render(r, w, source, node, enter)
+	}
 }
 
 // SpanTree returns the root of the rendered document's span tree. This tree maps AST nodes to their representative
@@ -382,11 +425,11 @@ func (r *Renderer) beginLine(w io.Writer) error {
 		}
 	}
 
-	n, err := w.Write(r.prefix)
+	n, err := w.Write(r.styledPrefix())
 	if n != 0 {
 		r.atNewline = r.prefix[len(r.prefix)-1] == '\n'
 		if !r.atNewline {
-			r.lineWidth = n
+			r.lineWidth = len(r.prefix)
 		}
 		r.byteOffset += n
 	}
@@ -695,14 +738,50 @@ func (r *Renderer) PushIndent(amount int) {
 
 // PushPrefix adds the specified string to the current line prefix.
 func (r *Renderer) PushPrefix(prefix string) {
+	r.PushStyledPrefix(prefix, 0)
+}
+
+// PushStyledPrefix adds the specified string to the current line prefix. If the theme defines a style for the given
+// token, the prefix is written in that style, independent of the style of the surrounding content.
+func (r *Renderer) PushStyledPrefix(prefix string, token chroma.TokenType) {
 	r.prefixStack = append(r.prefixStack, prefix)
+	r.prefixStyle = append(r.prefixStyle, token)
 	r.prefix = append(r.prefix, []byte(prefix)...)
 }
 
-// PopPrefix removes the last piece added by a call to PushIndent or PushPrefix from the current line prefix.
+// PopPrefix removes the last piece added by a call to PushIndent, PushPrefix, or PushStyledPrefix from the current
+// line prefix.
 func (r *Renderer) PopPrefix() {
 	r.prefix = r.prefix[:len(r.prefix)-len(r.prefixStack[len(r.prefixStack)-1])]
 	r.prefixStack = r.prefixStack[:len(r.prefixStack)-1]
+	r.prefixStyle = r.prefixStyle[:len(r.prefixStyle)-1]
+}
+
+// styledPrefix returns the bytes to write for the current line prefix. Pieces pushed with a token the theme styles are
+// wrapped in that style; all other pieces are written as-is.
+func (r *Renderer) styledPrefix() []byte {
+	if r.theme == nil {
+		return r.prefix
+	}
+
+	var buf []byte
+	styled := false
+	for i, piece := range r.prefixStack {
+		if token := r.prefixStyle[i]; token != 0 && r.theme.Has(styles.TokenType(token)) {
+			if sgr := buildStyleSGR(r.theme.Get(styles.TokenType(token))); len(sgr) != 0 {
+				buf = append(buf, sgr...)
+				buf = append(buf, piece...)
+				buf = append(buf, "\033[0m"...)
+				styled = true
+				continue
+			}
+		}
+		buf = append(buf, piece...)
+	}
+	if !styled {
+		return r.prefix
+	}
+	return buf
 }
 
 // OpenSpan begins a new span associated with the given node.
@@ -815,7 +894,7 @@ func (r *Renderer) CloseBlock(w io.Writer) error {
 
 // RenderDocument renders an *ast.Document node to the given BufWriter.
 func (r *Renderer) RenderDocument(w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error) {
-	r.listStack, r.prefixStack, r.prefix, r.wrapping, r.atNewline = nil, nil, nil, []bool{true}, false
+	r.listStack, r.prefixStack, r.prefixStyle, r.prefix, r.wrapping, r.atNewline = nil, nil, nil, nil, []bool{true}, false
 
 	if enter {
 		r.OpenSpan(node)
@@ -845,11 +924,7 @@ func (r *Renderer) RenderHeading(w util.BufWriter, source []byte, node ast.Node,
 
 		r.PushWordWrap(false)
 
-		style := chroma.GenericHeading
-		if node.(*ast.Heading).Level > 2 {
-			style = chroma.GenericSubheading
-		}
-		if err := r.PushStyle(w, style); err != nil {
+		if err := r.PushStyle(w, styles.HeadingLevel(node.(*ast.Heading).Level)); err != nil {
 			return ast.WalkStop, err
 		}
 
@@ -907,14 +982,23 @@ func (r *Renderer) RenderBlockquote(w util.BufWriter, source []byte, node ast.No
 		// - case 208, a list item in a lazy blockquote
 		// - cases 262 and 263, a blockquote in a list item
 
-		if err := r.PushStyle(w, chroma.GenericEmph); err != nil {
+		pushed, err := r.pushElementStyle(w, styles.BlockquoteBar)
+		if err != nil {
 			return ast.WalkStop, err
 		}
-
 		if _, err := r.WriteString(w, "> "); err != nil {
 			return ast.WalkStop, err
 		}
-		r.PushPrefix("> ")
+		if pushed {
+			if err := r.PopStyle(w); err != nil {
+				return ast.WalkStop, err
+			}
+		}
+		r.PushStyledPrefix("> ", styles.BlockquoteBar)
+
+		if err := r.PushStyle(w, styles.Blockquote); err != nil {
+			return ast.WalkStop, err
+		}
 	} else {
 		r.PopPrefix()
 
@@ -1204,6 +1288,14 @@ func (r *Renderer) RenderListItem(w util.BufWriter, source []byte, node ast.Node
 
 		markerWidth := 2
 		state := &r.listStack[len(r.listStack)-1]
+		markerStyle := styles.ListBullet
+		if state.ordered {
+			markerStyle = styles.ListNumber
+		}
+		pushed, err := r.pushElementStyle(w, markerStyle)
+		if err != nil {
+			return ast.WalkStop, err
+		}
 		if state.ordered {
 			width, err := r.WriteString(w, strconv.FormatInt(int64(state.index), 10))
 			if err != nil {
@@ -1212,7 +1304,15 @@ func (r *Renderer) RenderListItem(w util.BufWriter, source []byte, node ast.Node
 			state.index++
 			markerWidth += width
 		}
-		if _, err := r.Write(w, []byte{state.marker, ' '}); err != nil {
+		if err := r.writeByte(w, state.marker); err != nil {
+			return ast.WalkStop, err
+		}
+		if pushed {
+			if err := r.PopStyle(w); err != nil {
+				return ast.WalkStop, err
+			}
+		}
+		if err := r.writeByte(w, ' '); err != nil {
 			return ast.WalkStop, err
 		}
 
@@ -1300,13 +1400,25 @@ func (r *Renderer) RenderThematicBreak(w util.BufWriter, source []byte, node ast
 		if width <= 0 {
 			width = 80
 		}
-		if err := r.writeSGR(w, "2"); err != nil {
+		// Rules are dimmed unless the theme styles them explicitly.
+		pushed, err := r.pushElementStyle(w, styles.ThematicBreak)
+		if err != nil {
 			return ast.WalkStop, err
 		}
+		if !pushed {
+			if err := r.writeSGR(w, "2"); err != nil {
+				return ast.WalkStop, err
+			}
+		}
 		if _, err := r.WriteString(w, strings.Repeat("─", width)); err != nil {
 			return ast.WalkStop, err
 		}
-		if err := r.writeSGR(w, "22"); err != nil {
+		if pushed {
+			err = r.PopStyle(w)
+		} else {
+			err = r.writeSGR(w, "22")
+		}
+		if err != nil {
 			return ast.WalkStop, err
 		}
 		if _, err := r.WriteString(w, "\n"); err != nil {
@@ -1334,7 +1446,7 @@ func (r *Renderer) RenderAutoLink(w util.BufWriter, source []byte, node ast.Node
 		if _, err := r.WriteString(w, ansi.SetHyperlink(string(url))); err != nil {
 			return ast.WalkStop, err
 		}
-		if err := r.PushStyle(w, chroma.GenericUnderline); err != nil {
+		if err := r.PushStyle(w, r.hyperlinkStyle(styles.LinkText)); err != nil {
 			return ast.WalkStop, err
 		}
 		if _, err := r.Write(w, label); err != nil {
@@ -1350,9 +1462,18 @@ func (r *Renderer) RenderAutoLink(w util.BufWriter, source []byte, node ast.Node
 		if err := r.writeByte(w, '<'); err != nil {
 			return ast.WalkStop, err
 		}
+		pushed, err := r.pushElementStyle(w, styles.LinkURL)
+		if err != nil {
+			return ast.WalkStop, err
+		}
 		if _, err := r.Write(w, label); err != nil {
 			return ast.WalkStop, err
 		}
+		if pushed {
+			if err := r.PopStyle(w); err != nil {
+				return ast.WalkStop, err
+			}
+		}
 		if err := r.writeByte(w, '>'); err != nil {
 			return ast.WalkStop, err
 		}
@@ -1496,6 +1617,36 @@ func (r *Renderer) RenderEmphasis(w util.BufWriter, source []byte, node ast.Node
 	return ast.WalkContinue, nil
 }
 
+// RenderStrikethrough renders an *xast.Strikethrough node to the given BufWriter.
+func (r *Renderer) RenderStrikethrough(w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error) {
+	if r.theme == nil {
+		// No theme: write raw markers for round-tripping.
+		if enter {
+			r.OpenSpan(node)
+		} else {
+			r.CloseSpan()
+		}
+		if _, err := r.WriteString(w, "~~"); err != nil {
+			return ast.WalkStop, err
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if enter {
+		r.OpenSpan(node)
+		if err := r.PushStyle(w, chroma.GenericDeleted); err != nil {
+			return ast.WalkStop, err
+		}
+	} else {
+		if err := r.PopStyle(w); err != nil {
+			return ast.WalkStop, err
+		}
+		r.CloseSpan()
+	}
+
+	return ast.WalkContinue, nil
+}
+
 func (r *Renderer) escapeLinkDest(dest []byte) []byte {
 	requiresEscaping := false
 	for _, c := range dest {
@@ -1529,12 +1680,29 @@ func (r *Renderer) linkTitleDelimiter(title []byte) byte {
 	return '"'
 }
 
+// linkTextStyle returns the style token for the text of a link or the alt text of an image.
+func linkTextStyle(node ast.Node) chroma.TokenType {
+	if node.Kind() == ast.KindImage {
+		return styles.ImageAlt
+	}
+	return styles.LinkText
+}
+
+// hyperlinkStyle returns the style token for the text of a hyperlink. Hyperlinks are underlined unless the theme
+// styles the given element token explicitly.
+func (r *Renderer) hyperlinkStyle(token chroma.TokenType) chroma.TokenType {
+	if r.theme != nil && r.theme.Has(styles.TokenType(token)) {
+		return token
+	}
+	return chroma.GenericUnderline
+}
+
 func (r *Renderer) renderHyperlink(w util.BufWriter, node ast.Node, open string, refType ast.LinkReferenceType, label, dest, title []byte, enter bool) error {
 	if enter {
 		if _, err := r.WriteString(w, ansi.SetHyperlink(string(dest))); err != nil {
 			return err
 		}
-		if err := r.PushStyle(w, chroma.GenericUnderline); err != nil {
+		if err := r.PushStyle(w, r.hyperlinkStyle(linkTextStyle(node))); err != nil {
 			return err
 		}
 	} else {
@@ -1560,11 +1728,22 @@ func (r *Renderer) renderLinkOrImage(w util.BufWriter, node ast.Node, open strin
 		return r.renderHyperlink(w, node, open, refType, label, dest, title, enter)
 	}
 
+	// Outside of hyperlink mode, link text and destinations are only styled if the theme defines styles for them.
+	textStyle := linkTextStyle(node)
 	if enter {
 		if _, err := r.WriteString(w, open); err != nil {
 			return err
 		}
+		if _, err := r.pushElementStyle(w, textStyle); err != nil {
+			return err
+		}
 	} else {
+		if r.theme != nil && r.theme.Has(styles.TokenType(textStyle)) {
+			if err := r.PopStyle(w); err != nil {
+				return err
+			}
+		}
+
 		switch refType {
 		case ast.LinkFullReference:
 			if _, err := r.WriteString(w, "]["); err != nil {
@@ -1589,9 +1768,18 @@ func (r *Renderer) renderLinkOrImage(w util.BufWriter, node ast.Node, open strin
 				return err
 			}
 
+			pushed, err := r.pushElementStyle(w, styles.LinkURL)
+			if err != nil {
+				return err
+			}
 			if _, err := r.Write(w, r.escapeLinkDest(dest)); err != nil {
 				return err
 			}
+			if pushed {
+				if err := r.PopStyle(w); err != nil {
+					return err
+				}
+			}
 			if len(title) != 0 {
 				delimiter := r.linkTitleDelimiter(title)
 				if _, err := fmt.Fprintf(w, ` %c%s%c`, delimiter, string(title), delimiter); err != nil {
@@ -14,6 +14,7 @@ import (
 	goldmark_renderer "github.com/pgavlin/goldmark/renderer"
 	"github.com/pgavlin/goldmark/text"
 	"github.com/pgavlin/goldmark/util"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/styles"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -805,7 +806,7 @@ func TestRendererOptionsTheme(t *testing.T) {
 	r := New(WithTheme(styles.Pulumi))
 
 	assert.NotNil(t, r.theme, "theme should not be nil")
-	assert.Equal(t, styles.Pulumi, r.theme, "theme should be the Pulumi theme")
+	assert.Equal(t, styles.FromChromaStyle(styles.Pulumi), r.theme, "theme should be the Pulumi theme")
 }
 
 // TestRendererOptionsDefaults verifies that a renderer created with no options has sensible defaults.
@@ -1291,3 +1292,54 @@ func TestFencedCodeBlockDiagramFallback(t *testing.T) {
 	// Fence markers should appear in normal rendering.
 	assert.True(t, strings.Contains(stripped, "```"), "output should contain fence markers on fallback")
 }
+
+// TestWithNodeRenderer verifies that a custom node renderer replaces the
+// rendering of its node kind and writes through the Renderer.
+func TestWithNodeRenderer(t *testing.T) {
+	renderBreak := func(r *Renderer, w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error) {
+		if !enter {
+			return ast.WalkContinue, r.CloseBlock(w)
+		}
+		if err := r.OpenBlock(w, source, node); err != nil {
+			return ast.WalkStop, err
+		}
+		_, err := r.WriteString(w, "~ ~ ~")
+		return ast.WalkContinue, err
+	}
+
+	// The break is written inside the block quote's prefix.
+	input := "> one\n>\n> ---\n>\n> two\n"
+	output, r := renderMarkdown(t, input, WithNodeRenderer(ast.KindThematicBreak, renderBreak))
+	assert.Equal(t, "> one\n> ~ ~ ~\n> two\n", ansi.Strip(output))
+
+	// The custom renderer's block is in the span tree.
+	var found bool
+	for s := r.SpanTree(); s != nil; s = s.Next {
+		if s.Node.Kind() == ast.KindThematicBreak {
+			found = true
+			assert.Contains(t, ansi.Strip(output[s.Start:s.End]), "~ ~ ~")
+		}
+	}
+	assert.True(t, found)
+}
+
+// TestStrikethrough verifies that strikethrough is rendered with its markers
+// without a theme and with the deleted style with one.
+func TestStrikethrough(t *testing.T) {
+	source := []byte("Some ~~old~~ text.\n")
+	document := markdown.NewParser().Parse(source)
+
+	render := func(options ...RendererOption) string {
+		var buf bytes.Buffer
+		r := New(options...)
+		gmr := goldmark_renderer.NewRenderer(goldmark_renderer.WithNodeRenderers(util.Prioritized(r, 100)))
+		require.NoError(t, gmr.Render(&buf, source, document))
+		return buf.String()
+	}
+
+	assert.Equal(t, "Some ~~old~~ text.\n", render())
+
+	output := render(WithTheme(styles.Pulumi))
+	assert.Equal(t, "Some old text.\n", ansi.Strip(output))
+	assert.Contains(t, output, "\x1b[38;2;215;95;95mold")
+}
@@ -2,9 +2,11 @@ package renderer
 
 import (
 	"fmt"
+	"image/color"
 	"io"
 
 	"github.com/alecthomas/chroma"
+	"github.com/pgavlin/markdown-kit/styles"
 )
 
 func (r *Renderer) writeSGR(w io.Writer, command string) error {
@@ -13,37 +15,54 @@ func (r *Renderer) writeSGR(w io.Writer, command string) error {
 	return err
 }
 
-func (r *Renderer) writeTristateSGR(w io.Writer, off, on string, value chroma.Trilean) error {
-	if value == chroma.Yes {
+func (r *Renderer) writeTristateSGR(w io.Writer, off, on string, value styles.Trilean) error {
+	if value == styles.Yes {
 		return r.writeSGR(w, on)
 	}
 	return r.writeSGR(w, off)
 }
 
-func (r *Renderer) writeColorSGR(w io.Writer, command string, color chroma.Colour) error {
-	return r.writeSGR(w, command+fmt.Sprintf(";2;%v;%v;%v", color.Red(), color.Green(), color.Blue()))
+func (r *Renderer) writeColorSGR(w io.Writer, command string, c color.Color) error {
+	red, green, blue := rgb(c)
+	return r.writeSGR(w, command+fmt.Sprintf(";2;%v;%v;%v", red, green, blue))
 }
 
-func (r *Renderer) writeDelta(w io.Writer, base, new chroma.StyleEntry) error {
+// rgb returns the 8-bit red, green, and blue components of c.
+func rgb(c color.Color) (uint8, uint8, uint8) {
+	r, g, b, _ := c.RGBA()
+	return uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)
+}
+
+// sameColor returns true if a and b are both nil or have the same RGB components.
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ar, ag, ab := rgb(a)
+	br, bg, bb := rgb(b)
+	return ar == br && ag == bg && ab == bb
+}
+
+func (r *Renderer) writeDelta(w io.Writer, base, new styles.StyleEntry) error {
 	if new.IsZero() {
 		// Write a reset command.
 		return r.writeSGR(w, "0")
 	}
 
-	if new.Background.IsSet() && (base.IsZero() || new.Background != base.Background) {
+	if new.Background != nil && (base.IsZero() || !sameColor(new.Background, base.Background)) {
 		if err := r.writeColorSGR(w, "48", new.Background); err != nil {
 			return err
 		}
-	} else if !new.Background.IsSet() && !base.IsZero() && base.Background.IsSet() {
+	} else if new.Background == nil && !base.IsZero() && base.Background != nil {
 		if err := r.writeSGR(w, "49"); err != nil {
 			return err
 		}
 	}
-	if new.Colour.IsSet() && (base.IsZero() || new.Colour != base.Colour) {
+	if new.Colour != nil && (base.IsZero() || !sameColor(new.Colour, base.Colour)) {
 		if err := r.writeColorSGR(w, "38", new.Colour); err != nil {
 			return err
 		}
-	} else if !new.Colour.IsSet() && !base.IsZero() && base.Colour.IsSet() {
+	} else if new.Colour == nil && !base.IsZero() && base.Colour != nil {
 		if err := r.writeSGR(w, "39"); err != nil {
 			return err
 		}
@@ -69,34 +88,34 @@ func (r *Renderer) writeDelta(w io.Writer, base, new chroma.StyleEntry) error {
 // resolveStyle looks up the token's style in the theme and applies inheritance
 // from the current top of the style stack. Returns the resolved style entry and
 // true, or a zero entry and false if the theme is nil or has no style for the token.
-func (r *Renderer) resolveStyle(token chroma.TokenType) (chroma.StyleEntry, bool) {
+func (r *Renderer) resolveStyle(token chroma.TokenType) (styles.StyleEntry, bool) {
 	if r.theme == nil {
-		return chroma.StyleEntry{}, false
+		return styles.StyleEntry{}, false
 	}
 
-	tokenStyle := r.theme.Get(token)
+	tokenStyle := r.theme.Get(styles.TokenType(token))
 	if tokenStyle.IsZero() {
-		return chroma.StyleEntry{}, false
+		return styles.StyleEntry{}, false
 	}
 
-	var base chroma.StyleEntry
+	var base styles.StyleEntry
 	if len(r.styles) != 0 {
 		base = r.styles[len(r.styles)-1]
 	}
 	// Inherit colors from parent when unset.
-	if !tokenStyle.Background.IsSet() && base.Background.IsSet() {
+	if tokenStyle.Background == nil && base.Background != nil {
 		tokenStyle.Background = base.Background
 	}
-	if !tokenStyle.Colour.IsSet() && base.Colour.IsSet() {
+	if tokenStyle.Colour == nil && base.Colour != nil {
 		tokenStyle.Colour = base.Colour
 	}
-	if tokenStyle.Bold == chroma.Pass {
+	if tokenStyle.Bold == styles.Pass {
 		tokenStyle.Bold = base.Bold
 	}
-	if tokenStyle.Underline == chroma.Pass {
+	if tokenStyle.Underline == styles.Pass {
 		tokenStyle.Underline = base.Underline
 	}
-	if tokenStyle.Italic == chroma.Pass {
+	if tokenStyle.Italic == styles.Pass {
 		tokenStyle.Italic = base.Italic
 	}
 	return tokenStyle, true
@@ -110,46 +129,58 @@ func (r *Renderer) reapplyStyle(w io.Writer) error {
 	if len(r.styles) == 0 {
 		return nil
 	}
-	return r.writeDelta(w, chroma.StyleEntry{}, r.styles[len(r.styles)-1])
+	return r.writeDelta(w, styles.StyleEntry{}, r.styles[len(r.styles)-1])
 }
 
 // buildStyleSGR returns the raw ANSI SGR bytes needed to establish the given
 // style from a reset state. Returns nil if the style is zero. This is used by
 // beginLine to restore the active style after writing the prefix without going
 // through the renderer's Write pipeline (which would recurse back into beginLine).
-func buildStyleSGR(style chroma.StyleEntry) []byte {
+func buildStyleSGR(style styles.StyleEntry) []byte {
 	if style.IsZero() {
 		return nil
 	}
 	var buf []byte
-	if style.Background.IsSet() {
-		buf = fmt.Appendf(buf, "\033[48;2;%d;%d;%dm", style.Background.Red(), style.Background.Green(), style.Background.Blue())
+	if style.Background != nil {
+		red, green, blue := rgb(style.Background)
+		buf = fmt.Appendf(buf, "\033[48;2;%d;%d;%dm", red, green, blue)
 	}
-	if style.Colour.IsSet() {
-		buf = fmt.Appendf(buf, "\033[38;2;%d;%d;%dm", style.Colour.Red(), style.Colour.Green(), style.Colour.Blue())
+	if style.Colour != nil {
+		red, green, blue := rgb(style.Colour)
+		buf = fmt.Appendf(buf, "\033[38;2;%d;%d;%dm", red, green, blue)
 	}
-	if style.Bold == chroma.Yes {
+	if style.Bold == styles.Yes {
 		buf = append(buf, "\033[1m"...)
 	}
-	if style.Underline == chroma.Yes {
+	if style.Underline == styles.Yes {
 		buf = append(buf, "\033[4m"...)
 	}
-	if style.Italic == chroma.Yes {
+	if style.Italic == styles.Yes {
 		buf = append(buf, "\033[3m"...)
 	}
 	return buf
 }
 
+// PushStyle pushes the style for the given token onto the style stack and writes the ANSI sequences needed to
+// apply it. The token may be any chroma token or one of the custom tokens in the styles package. Each call to
+// PushStyle must be balanced by a call to PopStyle.
 func (r *Renderer) PushStyle(w io.Writer, token chroma.TokenType) error {
-	resolved, ok := r.resolveStyle(token)
-	if !ok {
+	if r.theme == nil {
 		return nil
 	}
 
-	var base chroma.StyleEntry
+	var base styles.StyleEntry
 	if len(r.styles) != 0 {
 		base = r.styles[len(r.styles)-1]
 	}
+
+	resolved, ok := r.resolveStyle(token)
+	if !ok {
+		// The theme has no style for this token: keep the current style so that the matching PopStyle is a no-op.
+		r.styles = append(r.styles, base)
+		return nil
+	}
+
 	if err := r.writeDelta(w, base, resolved); err != nil {
 		return err
 	}
@@ -157,12 +188,14 @@ func (r *Renderer) PushStyle(w io.Writer, token chroma.TokenType) error {
 	return nil
 }
 
+// PopStyle pops the style pushed by the most recent call to PushStyle and writes the ANSI sequences needed to restore
+// the previous style.
 func (r *Renderer) PopStyle(w io.Writer) error {
 	if r.theme == nil {
 		return nil
 	}
 
-	var new chroma.StyleEntry
+	var new styles.StyleEntry
 	if len(r.styles) > 1 {
 		new = r.styles[len(r.styles)-2]
 	}
@@ -172,3 +205,13 @@ func (r *Renderer) PopStyle(w io.Writer) error {
 	r.styles = r.styles[:len(r.styles)-1]
 	return nil
 }
+
+// pushElementStyle pushes the style for a markdown element token, but only if the theme defines a style for the token
+// or its group. Elements the theme does not mention are left unstyled so that themes which predate per-element styling
+// render as they always have. Returns true if a style was pushed, in which case the caller must call PopStyle.
+func (r *Renderer) pushElementStyle(w io.Writer, token chroma.TokenType) (bool, error) {
+	if r.theme == nil || !r.theme.Has(styles.TokenType(token)) {
+		return false, nil
+	}
+	return true, r.PushStyle(w, token)
+}
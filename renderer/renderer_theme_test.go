@@ -0,0 +1,121 @@
+package renderer
+
+import (
+	"fmt"
+	"image/color"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+// fg returns the SGR sequence the renderer emits to set the foreground to c.
+func fg(c color.RGBA) string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// assertStyled asserts that text appears in output immediately after the SGR sequence sgr, allowing for other SGR
+// sequences in between.
+func assertStyled(t *testing.T, output, sgr, text string) {
+	t.Helper()
+	re := regexp.MustCompile(regexp.QuoteMeta(sgr) + `(\x1b\[[0-9;]*m)*` + regexp.QuoteMeta(text))
+	assert.Regexp(t, re, output)
+}
+
+var (
+	testRed    = color.RGBA{R: 200, A: 255}
+	testGreen  = color.RGBA{G: 200, A: 255}
+	testBlue   = color.RGBA{B: 200, A: 255}
+	testYellow = color.RGBA{R: 200, G: 200, A: 255}
+	testCyan   = color.RGBA{G: 200, B: 200, A: 255}
+)
+
+func TestWithStyles_HeadingLevels(t *testing.T) {
+	theme := styles.NewTheme(map[styles.TokenType]styles.StyleEntry{
+		styles.TokenType(styles.Heading):  {Bold: styles.Yes},
+		styles.TokenType(styles.Heading1): {Colour: testRed},
+		styles.TokenType(styles.Heading3): {Colour: testGreen},
+	})
+
+	output, _ := renderMarkdown(t, "# One\n\n### Three\n\n##### Five\n", WithStyles(theme))
+
+	assertStyled(t, output, fg(testRed)+"\033[1m", "# One")
+	assertStyled(t, output, fg(testGreen)+"\033[1m", "### Three")
+	// Levels without their own style use the Heading group style.
+	assertStyled(t, output, "\033[1m", "##### Five")
+	assert.NotRegexp(t, `\x1b\[38;[0-9;]*m(\x1b\[[0-9;]*m)*##### Five`, output)
+	assert.Equal(t, "# One\n\n### Three\n\n##### Five\n", ansi.Strip(output))
+}
+
+func TestWithStyles_ListMarkers(t *testing.T) {
+	theme := styles.NewTheme(map[styles.TokenType]styles.StyleEntry{
+		styles.TokenType(styles.ListBullet): {Colour: testRed},
+		styles.TokenType(styles.ListNumber): {Colour: testGreen},
+	})
+
+	output, _ := renderMarkdown(t, "- bullet\n\n1. number\n", WithStyles(theme))
+
+	assertStyled(t, output, fg(testRed), "-\033[0m bullet")
+	assertStyled(t, output, fg(testGreen), "1.\033[0m number")
+	assert.Equal(t, "- bullet\n\n1. number\n", ansi.Strip(output))
+}
+
+func TestWithStyles_BlockquoteBar(t *testing.T) {
+	theme := styles.NewTheme(map[styles.TokenType]styles.StyleEntry{
+		styles.TokenType(styles.Blockquote):    {Italic: styles.Yes},
+		styles.TokenType(styles.BlockquoteBar): {Colour: testBlue},
+	})
+
+	output, _ := renderMarkdown(t, "> one\n>\n> two\n", WithStyles(theme))
+
+	// Both the opening line and the prefix of later lines carry the bar style.
+	bar := regexp.MustCompile(regexp.QuoteMeta(fg(testBlue)) + `(\x1b\[[0-9;]*m)*> \x1b\[0m`)
+	assert.Len(t, bar.FindAllString(output, -1), 2, "output: %q", output)
+	assertStyled(t, output, "\033[3m", "one")
+	assert.Equal(t, "> one\n\n> two\n", ansi.Strip(output))
+}
+
+func TestWithStyles_Links(t *testing.T) {
+	theme := styles.NewTheme(map[styles.TokenType]styles.StyleEntry{
+		styles.TokenType(styles.LinkText): {Colour: testRed},
+		styles.TokenType(styles.LinkURL):  {Colour: testGreen},
+		styles.TokenType(styles.ImageAlt): {Colour: testYellow},
+	})
+
+	input := "[text](https://example.com) ![alt](image.png)\n"
+	output, _ := renderMarkdown(t, input, WithStyles(theme))
+
+	assertStyled(t, output, "["+fg(testRed), "text\033[0m](")
+	assertStyled(t, output, "]("+fg(testGreen), "https://example.com\033[0m)")
+	assertStyled(t, output, "!["+fg(testYellow), "alt")
+	assert.Equal(t, input, ansi.Strip(output))
+
+	output, _ = renderMarkdown(t, input, WithStyles(theme), WithHyperlinks(true))
+	assertStyled(t, output, fg(testRed), "text")
+	assert.NotContains(t, output, fg(testGreen))
+}
+
+func TestWithStyles_ThematicBreak(t *testing.T) {
+	theme := styles.NewTheme(map[styles.TokenType]styles.StyleEntry{
+		styles.TokenType(styles.ThematicBreak): {Colour: testCyan},
+	})
+
+	output, _ := renderMarkdown(t, "---\n", WithStyles(theme), WithWordWrap(10))
+	assertStyled(t, output, fg(testCyan), strings.Repeat("─", 10))
+	assert.NotContains(t, output, "\033[2m")
+
+	// Without a rule style, rules are dimmed.
+	output, _ = renderMarkdown(t, "---\n", WithStyles(styles.NewTheme(nil)), WithWordWrap(10))
+	assert.Contains(t, output, "\033[2m"+strings.Repeat("─", 10)+"\033[22m")
+}
+
+func TestWithTheme_ChromaAdapter(t *testing.T) {
+	input := "# Title\n\n- item\n\n[link](https://example.com)\n"
+
+	chromaOutput, _ := renderMarkdown(t, input, WithTheme(styles.Pulumi))
+	themeOutput, _ := renderMarkdown(t, input, WithStyles(styles.FromChromaStyle(styles.Pulumi)))
+	assert.Equal(t, chromaOutput, themeOutput)
+}
@@ -0,0 +1,86 @@
+package styles
+
+import (
+	"image/color"
+
+	"github.com/alecthomas/chroma"
+)
+
+// markdownFallbacks maps markdown element tokens to the chroma tokens that the
+// renderer used for them before per-element styling existed. FromChromaStyle
+// uses these when a chroma style does not define an element (or its group), so
+// that plain chroma styles keep rendering as they always have.
+var markdownFallbacks = []struct {
+	element, fallback chroma.TokenType
+}{
+	{Heading1, chroma.GenericHeading},
+	{Heading2, chroma.GenericHeading},
+	{Heading3, chroma.GenericSubheading},
+	{Heading4, chroma.GenericSubheading},
+	{Heading5, chroma.GenericSubheading},
+	{Heading6, chroma.GenericSubheading},
+	{Blockquote, chroma.GenericEmph},
+}
+
+// entryFromChroma converts a chroma.StyleEntry to a StyleEntry.
+func entryFromChroma(e chroma.StyleEntry) StyleEntry {
+	var out StyleEntry
+	if e.Colour.IsSet() {
+		out.Colour = color.RGBA{R: e.Colour.Red(), G: e.Colour.Green(), B: e.Colour.Blue(), A: 0xff}
+	}
+	if e.Background.IsSet() {
+		out.Background = color.RGBA{R: e.Background.Red(), G: e.Background.Green(), B: e.Background.Blue(), A: 0xff}
+	}
+	out.Bold = trileanFromChroma(e.Bold)
+	out.Italic = trileanFromChroma(e.Italic)
+	out.Underline = trileanFromChroma(e.Underline)
+	return out
+}
+
+// trileanFromChroma converts a chroma.Trilean to a Trilean.
+func trileanFromChroma(t chroma.Trilean) Trilean {
+	switch t {
+	case chroma.Yes:
+		return Yes
+	case chroma.No:
+		return No
+	default:
+		return Pass
+	}
+}
+
+// FromChromaStyle converts a *chroma.Style into a *Theme. Every token type the
+// style defines (including the custom tokens in this package) is carried over
+// with chroma's inheritance already applied. Markdown elements that the style
+// does not define fall back to the chroma tokens historically used to render
+// them (e.g. Heading1 falls back to GenericHeading). A nil style yields a nil
+// theme.
+func FromChromaStyle(s *chroma.Style) *Theme {
+	if s == nil {
+		return nil
+	}
+
+	entries := map[TokenType]StyleEntry{}
+	for _, tok := range s.Types() {
+		entries[TokenType(tok)] = entryFromChroma(s.Get(tok))
+	}
+
+	// Decide every fallback against the style's own entries, so that a
+	// fallback does not depend on those that come before it.
+	defined := NewTheme(entries)
+	fallbacks := map[TokenType]StyleEntry{}
+	for _, f := range markdownFallbacks {
+		if !defined.Has(TokenType(f.element)) {
+			fallbacks[TokenType(f.element)] = entryFromChroma(s.Get(f.fallback))
+		}
+	}
+
+	all := make(map[TokenType]StyleEntry, len(entries)+len(fallbacks))
+	for tok, e := range entries {
+		all[tok] = e
+	}
+	for tok, e := range fallbacks {
+		all[tok] = e
+	}
+	return NewTheme(all)
+}
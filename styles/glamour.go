@@ -186,6 +186,18 @@ func FromStyleConfig(cfg ansi.StyleConfig) *Theme {
 	// Table.
 	set(TokenType(Table), primitiveToEntry(cfg.Table.StylePrimitive))
 
+	// Markdown elements.
+	for i, h := range []ansi.StyleBlock{cfg.H1, cfg.H2, cfg.H3, cfg.H4, cfg.H5, cfg.H6} {
+		set(TokenType(HeadingLevel(i+1)), overlay(headingBase, primitiveToEntry(h.StylePrimitive)))
+	}
+	set(TokenType(ListBullet), primitiveToEntry(cfg.Item))
+	set(TokenType(ListNumber), primitiveToEntry(cfg.Enumeration))
+	set(TokenType(Blockquote), primitiveToEntry(cfg.BlockQuote.StylePrimitive))
+	set(TokenType(LinkText), primitiveToEntry(mergePrimitive(cfg.Link, cfg.LinkText)))
+	set(TokenType(LinkURL), primitiveToEntry(cfg.Link))
+	set(TokenType(ImageAlt), primitiveToEntry(cfg.ImageText))
+	set(TokenType(ThematicBreak), primitiveToEntry(cfg.HorizontalRule))
+
 	return NewTheme(entries)
 }
 
@@ -252,6 +264,12 @@ func mergePrimitive(base, specific ansi.StylePrimitive) ansi.StylePrimitive {
 // registered under the given name. This produces the same format used by the
 // renderer's WithTheme option.
 func ChromaStyleFromConfig(name string, cfg ansi.StyleConfig) *chroma.Style {
+	return chromaStyles.Register(chromaStyleFromConfig(name, cfg))
+}
+
+// chromaStyleFromConfig converts a glamour ansi.StyleConfig into an
+// unregistered *chroma.Style with the given name.
+func chromaStyleFromConfig(name string, cfg ansi.StyleConfig) *chroma.Style {
 	entries := chroma.StyleEntries{}
 
 	set := func(tok chroma.TokenType, style string) {
@@ -342,7 +360,19 @@ func ChromaStyleFromConfig(name string, cfg ansi.StyleConfig) *chroma.Style {
 	// Table.
 	set(Table, primitiveToChromaString(cfg.Table.StylePrimitive))
 
-	return chromaStyles.Register(chroma.MustNewStyle(name, entries))
+	// Markdown elements.
+	for i, h := range []ansi.StyleBlock{cfg.H1, cfg.H2, cfg.H3, cfg.H4, cfg.H5, cfg.H6} {
+		set(HeadingLevel(i+1), primitiveToChromaString(mergePrimitive(headingBase, h.StylePrimitive)))
+	}
+	set(ListBullet, primitiveToChromaString(cfg.Item))
+	set(ListNumber, primitiveToChromaString(cfg.Enumeration))
+	set(Blockquote, primitiveToChromaString(cfg.BlockQuote.StylePrimitive))
+	set(LinkText, primitiveToChromaString(mergePrimitive(cfg.Link, cfg.LinkText)))
+	set(LinkURL, primitiveToChromaString(cfg.Link))
+	set(ImageAlt, primitiveToChromaString(cfg.ImageText))
+	set(ThematicBreak, primitiveToChromaString(cfg.HorizontalRule))
+
+	return chroma.MustNewStyle(name, entries)
 }
 
 // Glamour themes converted from github.com/charmbracelet/glamour/styles.
@@ -175,6 +175,17 @@ func NewTheme(entries map[TokenType]StyleEntry) *Theme {
 	return &Theme{entries: entries}
 }
 
+// Has returns true if the theme defines a style for the given token type, its
+// sub-category, or its category.
+func (t *Theme) Has(token TokenType) bool {
+	for _, tok := range []TokenType{token, token.subCategory(), token.category()} {
+		if e, ok := t.entries[tok]; ok && !e.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
 // Get returns the style entry for the given token type, inheriting from
 // parent token types (sub-category, category, Text, Background) as needed.
 // This matches chroma's Style.Get behavior.
@@ -4,6 +4,7 @@ import (
 	"image/color"
 	"testing"
 
+	"github.com/alecthomas/chroma"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -432,3 +433,72 @@ func TestTheme_Get_textToken(t *testing.T) {
 	assert.Equal(t, Yes, result.Bold)
 	assert.Equal(t, bg, result.Background)
 }
+
+func TestTheme_Has(t *testing.T) {
+	theme := NewTheme(map[TokenType]StyleEntry{
+		Keyword:                    {Bold: Yes},
+		TokenType(LinkURL):         {Underline: Yes},
+		TokenType(ListMarker):      {},
+		TokenType(HeadingLevel(2)): {Colour: color.White},
+	})
+
+	assert.True(t, theme.Has(Keyword))
+	assert.True(t, theme.Has(KeywordConstant), "inherits from category")
+	assert.True(t, theme.Has(TokenType(LinkURL)))
+	assert.False(t, theme.Has(TokenType(LinkText)))
+	assert.False(t, theme.Has(TokenType(ListBullet)), "zero entries do not count")
+	assert.True(t, theme.Has(TokenType(Heading2)))
+	assert.False(t, theme.Has(TokenType(Heading3)))
+}
+
+func TestHeadingLevel(t *testing.T) {
+	assert.Equal(t, Heading1, HeadingLevel(1))
+	assert.Equal(t, Heading6, HeadingLevel(6))
+	assert.Equal(t, Heading1, HeadingLevel(0))
+	assert.Equal(t, Heading6, HeadingLevel(9))
+}
+
+func TestFromChromaStyle(t *testing.T) {
+	assert.Nil(t, FromChromaStyle(nil))
+
+	theme := FromChromaStyle(Pulumi)
+	heading := Pulumi.Get(chroma.GenericHeading)
+	assert.Equal(t, Yes, theme.Get(TokenType(Heading1)).Bold)
+	assert.Equal(t, entryFromChroma(heading).Colour, theme.Get(TokenType(Heading2)).Colour)
+	assert.Equal(t, entryFromChroma(Pulumi.Get(chroma.GenericSubheading)), theme.Get(TokenType(Heading4)))
+	assert.Equal(t, Yes, theme.Get(TokenType(Blockquote)).Italic)
+	assert.Equal(t, entryFromChroma(Pulumi.Get(CodeSpan)), theme.Get(TokenType(CodeSpan)))
+	assert.False(t, theme.Has(TokenType(ListBullet)))
+
+	// Styles that define markdown elements keep them.
+	style := chroma.MustNewStyle("from-chroma-test", chroma.StyleEntries{
+		chroma.GenericHeading: "#ff0000",
+		Heading2:              "#00ff00",
+	})
+	theme = FromChromaStyle(style)
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, theme.Get(TokenType(Heading1)).Colour)
+	assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, theme.Get(TokenType(Heading2)).Colour)
+}
+
+func TestFromChromaStyle_FallbackOrder(t *testing.T) {
+	saved := markdownFallbacks
+	defer func() { markdownFallbacks = saved }()
+
+	style := chroma.MustNewStyle("fallback-order-test", chroma.StyleEntries{
+		chroma.GenericHeading: "#ff0000",
+		chroma.GenericStrong:  "#00ff00",
+	})
+
+	// A fallback for a group does not hide the fallback for one of its
+	// members, whichever comes first.
+	markdownFallbacks = []struct{ element, fallback chroma.TokenType }{
+		{Heading1, chroma.GenericHeading},
+		{Heading, chroma.GenericStrong},
+	}
+	want := FromChromaStyle(style)
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, want.Get(TokenType(Heading1)).Colour)
+	assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, want.Get(TokenType(Heading2)).Colour)
+
+	markdownFallbacks[0], markdownFallbacks[1] = markdownFallbacks[1], markdownFallbacks[0]
+	assert.Equal(t, want, FromChromaStyle(style))
+}
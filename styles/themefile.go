@@ -0,0 +1,398 @@
+package styles
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/alecthomas/chroma"
+	chromaStyles "github.com/alecthomas/chroma/styles"
+	"github.com/charmbracelet/glamour/ansi"
+	"gopkg.in/yaml.v3"
+)
+
+// ThemeFileError describes a problem with a theme file. Line is 1-based; it is
+// zero if the error is not associated with a particular line.
+type ThemeFileError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *ThemeFileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ThemeFileError) Unwrap() error {
+	return e.Err
+}
+
+// IsThemeFile returns true if path has the extension of a supported theme file
+// format: .json (glamour), .toml (native), or .yaml/.yml (base16).
+func IsThemeFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".toml", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// LoadThemeFile reads and parses the theme file at path. See ParseThemeFile.
+func LoadThemeFile(path string) (*chroma.Style, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseThemeFile(path, data)
+}
+
+// ParseThemeFile parses a theme file. The format is chosen by the extension of
+// path:
+//
+//   - .json: a glamour style config (as accepted by glamour.WithStylesFromJSONFile)
+//   - .toml: a native theme with chroma token styles and the custom tokens in
+//     this package (see below)
+//   - .yaml, .yml: a base16 color scheme
+//
+// A native theme looks like this:
+//
+//	name = "my-theme"
+//	base = "monokai" # optional; any registered chroma style
+//
+//	[styles]
+//	Text = "#d7d7d7"
+//	GenericHeading = "#d787af bold"
+//	CodeSpan = "#d7d7d7 bg:#303030"
+//	TableHeader = "#d787af"
+//
+// The resulting style is named after the theme's name, which defaults to the
+// base name of path without its extension. The style is not registered with
+// chroma, so a theme file that shares the name of a registered style does not
+// replace it; callers that look themes up by name keep their own map. Errors
+// are returned as *ThemeFileError.
+func ParseThemeFile(path string, data []byte) (*chroma.Style, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var style *chroma.Style
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		style, err = parseGlamourTheme(name, data)
+	case ".toml":
+		style, err = parseNativeTheme(name, data)
+	case ".yaml", ".yml":
+		style, err = parseBase16Theme(name, data)
+	default:
+		err = fmt.Errorf("unsupported theme format %q (expected .json, .toml, .yaml, or .yml)", filepath.Ext(path))
+	}
+	if err != nil {
+		var tfe *ThemeFileError
+		if errors.As(err, &tfe) {
+			tfe.Path = path
+			return nil, tfe
+		}
+		return nil, &ThemeFileError{Path: path, Err: err}
+	}
+	return style, nil
+}
+
+// lineForOffset returns the 1-based line number of the given byte offset.
+func lineForOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return bytes.Count(data[:offset], []byte{'\n'}) + 1
+}
+
+// reJSONUnknownField matches the error returned by encoding/json for an unknown
+// field, capturing the quoted field name.
+var reJSONUnknownField = regexp.MustCompile(`^json: unknown field ("[^"]*")$`)
+
+// parseGlamourTheme parses a glamour ansi.StyleConfig from JSON.
+func parseGlamourTheme(name string, data []byte) (*chroma.Style, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg ansi.StyleConfig
+	if err := dec.Decode(&cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// The offset points just past the offending byte.
+			return nil, &ThemeFileError{Line: lineForOffset(data, syntaxErr.Offset-1), Err: err}
+		case errors.As(err, &typeErr):
+			return nil, &ThemeFileError{Line: lineForOffset(data, typeErr.Offset), Err: err}
+		default:
+			// Unknown field errors do not carry an offset, so find the first
+			// occurrence of the field as an object key.
+			if m := reJSONUnknownField.FindStringSubmatch(err.Error()); m != nil {
+				re := regexp.MustCompile(regexp.QuoteMeta(m[1]) + `\s*:`)
+				if loc := re.FindIndex(data); loc != nil {
+					return nil, &ThemeFileError{Line: lineForOffset(data, int64(loc[0])), Err: err}
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return chromaStyleFromConfig(name, cfg), nil
+}
+
+// nativeTheme is the TOML representation of a native theme file.
+type nativeTheme struct {
+	Name   string            `toml:"name"`
+	Base   string            `toml:"base"`
+	Styles map[string]string `toml:"styles"`
+}
+
+// customTokens maps the names of the custom tokens in this package to their
+// token types.
+var customTokens = map[string]chroma.TokenType{
+	"Table":       Table,
+	"TableHeader": TableHeader,
+	"TableRow":    TableRow,
+	"TableRowAlt": TableRowAlt,
+	"StrongEmph":  StrongEmph,
+	"CodeSpan":    CodeSpan,
+
+	"Heading":       Heading,
+	"Heading1":      Heading1,
+	"Heading2":      Heading2,
+	"Heading3":      Heading3,
+	"Heading4":      Heading4,
+	"Heading5":      Heading5,
+	"Heading6":      Heading6,
+	"ListMarker":    ListMarker,
+	"ListBullet":    ListBullet,
+	"ListNumber":    ListNumber,
+	"Blockquote":    Blockquote,
+	"BlockquoteBar": BlockquoteBar,
+	"Link":          Link,
+	"LinkText":      LinkText,
+	"LinkURL":       LinkURL,
+	"Image":         Image,
+	"ImageAlt":      ImageAlt,
+	"ThematicBreak": ThematicBreak,
+	"Annotation":    Annotation,
+	"BrokenLink":    BrokenLink,
+}
+
+// tokenTypeByName returns the token type with the given name. Both chroma's
+// standard token names (e.g. "GenericHeading") and the custom tokens in this
+// package (e.g. "TableHeader") are accepted.
+func tokenTypeByName(name string) (chroma.TokenType, bool) {
+	if tok, ok := customTokens[name]; ok {
+		return tok, true
+	}
+	if name == chroma.Background.String() {
+		return chroma.Background, true
+	}
+	for tok := range chroma.StandardTypes {
+		if tok.String() == name {
+			return tok, true
+		}
+	}
+	return 0, false
+}
+
+// reTOMLKey matches a TOML table header or key/value line, capturing the table
+// name or the (possibly quoted) key.
+var reTOMLKey = regexp.MustCompile(`^\s*(?:\[\s*([^\]]+?)\s*\]|"?([A-Za-z0-9_-]+)"?\s*=)`)
+
+// tomlKeyLine returns the 1-based line on which key is defined in the given
+// table ("" for the root table), or 0 if it cannot be found.
+func tomlKeyLine(data []byte, table, key string) int {
+	current := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		m := reTOMLKey.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+		case m[1] != "":
+			current = m[1]
+		case current == table && m[2] == key:
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// parseNativeTheme parses a native TOML theme.
+func parseNativeTheme(name string, data []byte) (*chroma.Style, error) {
+	var theme nativeTheme
+	md, err := toml.Decode(string(data), &theme)
+	if err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &ThemeFileError{Line: parseErr.Position.Line, Err: errors.New(parseErr.Message)}
+		}
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		key := undecoded[0]
+		table := strings.Join(key[:len(key)-1], ".")
+		return nil, &ThemeFileError{
+			Line: tomlKeyLine(data, table, key[len(key)-1]),
+			Err:  fmt.Errorf("unknown key %q", key.String()),
+		}
+	}
+
+	if theme.Name != "" {
+		name = theme.Name
+	}
+
+	builder := chroma.NewStyleBuilder(name)
+	if theme.Base != "" {
+		base, ok := chromaStyles.Registry[theme.Base]
+		if !ok {
+			return nil, &ThemeFileError{Line: tomlKeyLine(data, "", "base"), Err: fmt.Errorf("unknown base style %q", theme.Base)}
+		}
+		builder = base.Builder()
+	}
+
+	// Validate entries in a stable order so that the first error reported is
+	// deterministic.
+	keys := make([]string, 0, len(theme.Styles))
+	for k := range theme.Styles {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return tomlKeyLine(data, "styles", keys[i]) < tomlKeyLine(data, "styles", keys[j])
+	})
+
+	for _, k := range keys {
+		v := theme.Styles[k]
+		tok, ok := tokenTypeByName(k)
+		if !ok {
+			return nil, &ThemeFileError{Line: tomlKeyLine(data, "styles", k), Err: fmt.Errorf("unknown token type %q", k)}
+		}
+		if _, err := chroma.ParseStyleEntry(v); err != nil {
+			return nil, &ThemeFileError{Line: tomlKeyLine(data, "styles", k), Err: fmt.Errorf("%s: %w", k, err)}
+		}
+		builder.Add(tok, v)
+	}
+
+	style, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	style.Name = name
+	return style, nil
+}
+
+// base16Keys lists the color slots of a base16 scheme in order.
+var base16Keys = []string{
+	"base00", "base01", "base02", "base03", "base04", "base05", "base06", "base07",
+	"base08", "base09", "base0A", "base0B", "base0C", "base0D", "base0E", "base0F",
+}
+
+// reBase16Color matches a base16 color value: six hex digits with an optional
+// leading '#'.
+var reBase16Color = regexp.MustCompile(`^#?[0-9A-Fa-f]{6}$`)
+
+// reYAMLError matches a yaml.v3 syntax error, capturing the line and message.
+var reYAMLError = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseBase16Theme parses a base16 color scheme from YAML and maps it onto
+// chroma and markdown tokens following the base16 styling guidelines.
+func parseBase16Theme(name string, data []byte) (*chroma.Style, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// yaml.v3 formats syntax errors as "yaml: line N: message".
+		if m := reYAMLError.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &ThemeFileError{Line: line, Err: errors.New(m[2])}
+		}
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, &ThemeFileError{Line: doc.Line, Err: errors.New("expected a mapping of base16 color slots")}
+	}
+
+	root := doc.Content[0]
+	colors := map[string]string{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		switch {
+		case k.Value == "scheme" || k.Value == "name":
+			if v.Value != "" {
+				name = v.Value
+			}
+		case k.Value == "author" || k.Value == "slug" || k.Value == "variant" || k.Value == "system":
+			// Metadata.
+		case strings.HasPrefix(strings.ToLower(k.Value), "base"):
+			slot := ""
+			for _, b := range base16Keys {
+				if strings.EqualFold(b, k.Value) {
+					slot = b
+				}
+			}
+			if slot == "" {
+				return nil, &ThemeFileError{Line: k.Line, Err: fmt.Errorf("unknown base16 color slot %q", k.Value)}
+			}
+			if v.Kind != yaml.ScalarNode || !reBase16Color.MatchString(v.Value) {
+				return nil, &ThemeFileError{Line: v.Line, Err: fmt.Errorf("%s: invalid color %q (expected six hex digits)", k.Value, v.Value)}
+			}
+			colors[slot] = "#" + strings.TrimPrefix(v.Value, "#")
+		default:
+			return nil, &ThemeFileError{Line: k.Line, Err: fmt.Errorf("unknown key %q", k.Value)}
+		}
+	}
+	for _, slot := range base16Keys {
+		if _, ok := colors[slot]; !ok {
+			return nil, &ThemeFileError{Line: root.Line, Err: fmt.Errorf("missing color slot %q", slot)}
+		}
+	}
+
+	c := func(slot string) string { return colors[slot] }
+	return chroma.NewStyle(name, chroma.StyleEntries{
+		chroma.Background:           "bg:" + c("base00"),
+		chroma.Text:                 c("base05"),
+		chroma.Error:                c("base08"),
+		chroma.Comment:              c("base03") + " italic",
+		chroma.Keyword:              c("base0E"),
+		chroma.KeywordType:          c("base0A"),
+		chroma.Operator:             c("base05"),
+		chroma.Punctuation:          c("base05"),
+		chroma.Name:                 c("base05"),
+		chroma.NameAttribute:        c("base0D"),
+		chroma.NameBuiltin:          c("base0C"),
+		chroma.NameClass:            c("base0A"),
+		chroma.NameConstant:         c("base09"),
+		chroma.NameFunction:         c("base0D"),
+		chroma.NameTag:              c("base08"),
+		chroma.NameVariable:         c("base08"),
+		chroma.Literal:              c("base09"),
+		chroma.LiteralNumber:        c("base09"),
+		chroma.LiteralString:        c("base0B"),
+		chroma.LiteralStringEscape:  c("base0C"),
+		chroma.LiteralStringRegex:   c("base0C"),
+		chroma.LiteralStringHeredoc: "bg:" + c("base01"),
+		chroma.GenericDeleted:       c("base08"),
+		chroma.GenericEmph:          "italic",
+		chroma.GenericHeading:       c("base0D") + " bold",
+		chroma.GenericInserted:      c("base0B"),
+		chroma.GenericStrong:        "bold",
+		chroma.GenericSubheading:    c("base0D"),
+		chroma.GenericUnderline:     c("base0C") + " underline",
+		StrongEmph:                  "bold italic",
+		CodeSpan:                    c("base0B") + " bg:" + c("base01"),
+		Table:                       "bg:" + c("base00"),
+		TableHeader:                 c("base0D") + " bold",
+		TableRowAlt:                 "bg:" + c("base01"),
+	})
+}
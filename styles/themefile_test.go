@@ -0,0 +1,183 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/alecthomas/chroma"
+	chromaStyles "github.com/alecthomas/chroma/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsThemeFile(t *testing.T) {
+	assert.True(t, IsThemeFile("theme.json"))
+	assert.True(t, IsThemeFile("/a/b/theme.TOML"))
+	assert.True(t, IsThemeFile("scheme.yaml"))
+	assert.True(t, IsThemeFile("scheme.yml"))
+	assert.False(t, IsThemeFile("monokai"))
+	assert.False(t, IsThemeFile("theme.xml"))
+}
+
+func TestParseThemeFile_Native(t *testing.T) {
+	style, err := ParseThemeFile("/themes/native-test.toml", []byte(`
+[styles]
+Text = "#d7d7d7"
+GenericHeading = "#d787af bold"
+TableHeader = "#00ff00"
+CodeSpan = "bg:#303030"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "native-test", style.Name)
+	assert.Equal(t, chroma.Yes, style.Get(chroma.GenericHeading).Bold)
+	assert.Equal(t, chroma.MustParseColour("#d787af"), style.Get(chroma.GenericHeading).Colour)
+	assert.Equal(t, chroma.MustParseColour("#00ff00"), style.Get(TableHeader).Colour)
+	assert.Equal(t, chroma.MustParseColour("#303030"), style.Get(CodeSpan).Background)
+
+	// The style is not registered.
+	assert.NotEqual(t, style, chromaStyles.Get("native-test"))
+}
+
+func TestParseThemeFile_DoesNotReplaceBuiltIn(t *testing.T) {
+	monokai := chromaStyles.Get("monokai")
+	style, err := ParseThemeFile("monokai.toml", []byte("[styles]\nGenericHeading = \"#ff0000\"\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "monokai", style.Name)
+	assert.Same(t, monokai, chromaStyles.Get("monokai"))
+}
+
+func TestParseThemeFile_NativeNameAndBase(t *testing.T) {
+	style, err := ParseThemeFile("theme.toml", []byte(`name = "native-based"
+base = "pulumi"
+
+[styles]
+GenericHeading = "#ff0000"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "native-based", style.Name)
+	assert.Equal(t, chroma.MustParseColour("#ff0000"), style.Get(chroma.GenericHeading).Colour)
+	// Entries not overridden come from the base style.
+	assert.Equal(t, Pulumi.Get(CodeSpan), style.Get(CodeSpan))
+}
+
+func TestParseThemeFile_NativeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantLine int
+		wantErr  string
+	}{
+		{"syntax", "name = \"x\"\nbase = pulumi\n", 2, ""},
+		{"unknown_key", "name = \"x\"\ncolour = \"red\"\n", 2, `unknown key "colour"`},
+		{"unknown_token", "[styles]\nText = \"#ffffff\"\nHeadling = \"bold\"\n", 3, `unknown token type "Headling"`},
+		{"bad_entry", "[styles]\n\nText = \"#ggg\"\n", 3, "Text:"},
+		{"unknown_base", "base = \"no-such-style\"\n", 1, `unknown base style "no-such-style"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseThemeFile("bad.toml", []byte(tt.source))
+			require.Error(t, err)
+			var tfe *ThemeFileError
+			require.ErrorAs(t, err, &tfe)
+			assert.Equal(t, "bad.toml", tfe.Path)
+			assert.Equal(t, tt.wantLine, tfe.Line)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseThemeFile_Glamour(t *testing.T) {
+	style, err := ParseThemeFile("glamour-test.json", []byte(`{
+  "document": {"color": "#cccccc"},
+  "heading": {"color": "#00ffff", "bold": true},
+  "code": {"color": "#ff00ff"}
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, "glamour-test", style.Name)
+	assert.Equal(t, chroma.MustParseColour("#00ffff"), style.Get(chroma.GenericHeading).Colour)
+	assert.Equal(t, chroma.Yes, style.Get(chroma.GenericHeading).Bold)
+	assert.Equal(t, chroma.MustParseColour("#ff00ff"), style.Get(CodeSpan).Colour)
+}
+
+func TestParseThemeFile_GlamourErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantLine int
+	}{
+		{"syntax", "{\n  \"heading\": {\n    \"bold\": tru\n  }\n}", 3},
+		{"type", "{\n  \"heading\": {\n    \"bold\": \"yes\"\n  }\n}", 3},
+		{"unknown_field", "{\n  \"heading\": {},\n  \"headline\": {}\n}", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseThemeFile("bad.json", []byte(tt.source))
+			var tfe *ThemeFileError
+			require.ErrorAs(t, err, &tfe)
+			assert.Equal(t, tt.wantLine, tfe.Line)
+		})
+	}
+}
+
+const testBase16Scheme = `scheme: "Base16 Test"
+author: "Test Author"
+base00: "181818"
+base01: "282828"
+base02: "383838"
+base03: "585858"
+base04: "b8b8b8"
+base05: "d8d8d8"
+base06: "e8e8e8"
+base07: "f8f8f8"
+base08: "ab4642"
+base09: "dc9656"
+base0A: "f7ca88"
+base0B: "a1b56c"
+base0C: "86c1b9"
+base0D: "7cafc2"
+base0E: "ba8baf"
+base0F: "a16946"
+`
+
+func TestParseThemeFile_Base16(t *testing.T) {
+	style, err := ParseThemeFile("default-dark.yaml", []byte(testBase16Scheme))
+	require.NoError(t, err)
+	assert.Equal(t, "Base16 Test", style.Name)
+	assert.Equal(t, chroma.MustParseColour("#d8d8d8"), style.Get(chroma.Text).Colour)
+	assert.Equal(t, chroma.MustParseColour("#181818"), style.Get(chroma.Background).Background)
+	assert.Equal(t, chroma.MustParseColour("#7cafc2"), style.Get(chroma.GenericHeading).Colour)
+	assert.Equal(t, chroma.MustParseColour("#ba8baf"), style.Get(chroma.Keyword).Colour)
+	assert.Equal(t, chroma.MustParseColour("#a1b56c"), style.Get(chroma.LiteralString).Colour)
+	assert.Equal(t, chroma.MustParseColour("#282828"), style.Get(TableRowAlt).Background)
+}
+
+func TestParseThemeFile_Base16Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantLine int
+		wantErr  string
+	}{
+		{"bad_color", "scheme: x\nbase00: \"zzzzzz\"\n", 2, "invalid color"},
+		{"unknown_slot", "scheme: x\nbase10: \"000000\"\n", 2, `unknown base16 color slot "base10"`},
+		{"unknown_key", "scheme: x\ncolours: 1\n", 2, `unknown key "colours"`},
+		{"missing_slot", "scheme: x\nbase00: \"000000\"\n", 1, `missing color slot "base01"`},
+		{"syntax", "scheme: x\nbase00: [\n", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseThemeFile("bad.yaml", []byte(tt.source))
+			var tfe *ThemeFileError
+			require.ErrorAs(t, err, &tfe)
+			assert.Equal(t, tt.wantLine, tfe.Line)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseThemeFile_UnsupportedFormat(t *testing.T) {
+	_, err := ParseThemeFile("theme.xml", []byte("<style/>"))
+	var tfe *ThemeFileError
+	require.ErrorAs(t, err, &tfe)
+	assert.Equal(t, 0, tfe.Line)
+	assert.Contains(t, err.Error(), "unsupported theme format")
+}
@@ -13,3 +13,61 @@ const (
 	StrongEmph
 	CodeSpan
 )
+
+// Markdown element tokens. These let a theme style structural elements that
+// have no natural chroma counterpart. Each group shares a sub-category, so a
+// theme may style a whole group (e.g. Heading) or a single member (e.g.
+// Heading1); members inherit from their group.
+const (
+	Heading chroma.TokenType = 10100 + iota
+	Heading1
+	Heading2
+	Heading3
+	Heading4
+	Heading5
+	Heading6
+)
+
+const (
+	ListMarker chroma.TokenType = 10200 + iota
+	ListBullet
+	ListNumber
+)
+
+const (
+	Blockquote chroma.TokenType = 10300 + iota
+	BlockquoteBar
+)
+
+const (
+	Link chroma.TokenType = 10400 + iota
+	LinkText
+	LinkURL
+)
+
+const (
+	Image chroma.TokenType = 10500 + iota
+	ImageAlt
+)
+
+const (
+	ThematicBreak chroma.TokenType = 10600 + iota
+)
+
+// Annotation styles passages highlighted with view.Model annotations. It is
+// not a Markdown element, but shares the element tokens' conventions.
+const (
+	Annotation chroma.TokenType = 10700 + iota
+)
+
+// BrokenLink styles links that view.Model.SetBrokenLinks marks as broken.
+const (
+	BrokenLink chroma.TokenType = 10800 + iota
+)
+
+// HeadingLevel returns the token for a heading of the given level (1-6).
+// Levels outside that range are clamped.
+func HeadingLevel(level int) chroma.TokenType {
+	level = min(max(level, 1), 6)
+	return Heading + chroma.TokenType(level)
+}
@@ -0,0 +1,692 @@
+package view
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/alecthomas/chroma"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/markdown-kit/styles"
+)
+
+// annotationContext is the number of source bytes saved on either side of an
+// annotated passage to tell it apart from other occurrences of the same text.
+const annotationContext = 32
+
+// Annotation is a highlighted passage of a document with an optional note.
+// It is anchored to the Markdown source by the highlighted text and the text
+// around it, so that it can be attached again after the document is edited.
+type Annotation struct {
+	// Text is the highlighted Markdown source.
+	Text string `json:"text"`
+	// Before and After are the source text immediately around the passage.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	// Offset is the source byte offset of the passage when it was saved. It
+	// breaks ties between equally good matches.
+	Offset int `json:"offset"`
+	// Note is the note attached to the passage, if any.
+	Note string `json:"note,omitempty"`
+}
+
+// AnnotationsChangedMsg is sent when the user adds, edits or deletes an
+// annotation. Embedders should handle this message to persist the result of
+// Annotations.
+type AnnotationsChangedMsg struct{}
+
+// annotation is an Annotation attached to the current document.
+type annotation struct {
+	Annotation
+
+	// The source byte range of the passage. Only valid if attached is true.
+	start, end int
+	attached   bool
+
+	// The rendered byte range of the passage, or -1 if it is not rendered.
+	renderedStart, renderedEnd int
+}
+
+// rendered reports whether the annotation is shown in the rendered document.
+func (a *annotation) rendered() bool {
+	return a.attached && a.renderedStart >= 0 && a.renderedEnd > a.renderedStart
+}
+
+// overlaps reports whether the annotation is rendered within the given
+// rendered byte range. An empty range overlaps the annotation that contains
+// it.
+func (a *annotation) overlaps(start, end int) bool {
+	return a.rendered() && a.renderedStart < max(end, start+1) && a.renderedEnd > start
+}
+
+// annotationState holds the annotations of the current document.
+type annotationState struct {
+	// The annotations, ordered by source offset. Annotations that could not be
+	// attached follow the others.
+	list []*annotation
+
+	// The annotation last navigated to, if any.
+	current *annotation
+
+	// If true, the note prompt is shown and keys edit input.
+	editing bool
+	input   string
+
+	// The annotation being created, or nil if the note of current is being
+	// edited.
+	draft *annotation
+}
+
+// SetAnnotations replaces the annotations of the current document. Each
+// annotation is attached to the occurrence of its text that best matches its
+// context; annotations whose text no longer occurs are kept but not shown.
+func (m *Model) SetAnnotations(annotations []Annotation) {
+	m.annotations = annotationState{}
+	for _, a := range annotations {
+		m.annotations.list = append(m.annotations.list, m.attachAnnotation(a))
+	}
+	m.sortAnnotations()
+	m.placeAnnotations()
+}
+
+// Annotations returns the annotations of the current document. The anchors of
+// attached annotations are refreshed from the current source.
+func (m *Model) Annotations() []Annotation {
+	if len(m.annotations.list) == 0 {
+		return nil
+	}
+	result := make([]Annotation, len(m.annotations.list))
+	for i, a := range m.annotations.list {
+		result[i] = a.Annotation
+		if a.attached {
+			result[i] = m.anchorAnnotation(a.start, a.end, a.Note)
+		}
+	}
+	return result
+}
+
+// AnnotationInputActive reports whether the Model is showing the note prompt
+// of an annotation. Embedders should forward key presses to the Model.
+func (m *Model) AnnotationInputActive() bool {
+	return m.annotations.editing
+}
+
+// anchorAnnotation returns an Annotation for the given source byte range.
+func (m *Model) anchorAnnotation(start, end int, note string) Annotation {
+	before := max(start-annotationContext, 0)
+	for before < start && !utf8.RuneStart(m.markdown[before]) {
+		before++
+	}
+	after := min(end+annotationContext, len(m.markdown))
+	for after > end && after < len(m.markdown) && !utf8.RuneStart(m.markdown[after]) {
+		after--
+	}
+	return Annotation{
+		Text:   string(m.markdown[start:end]),
+		Before: string(m.markdown[before:start]),
+		After:  string(m.markdown[end:after]),
+		Offset: start,
+		Note:   note,
+	}
+}
+
+// attachAnnotation finds the passage of an annotation in the current source.
+// Among the occurrences of its text, the one with the longest matching
+// context wins, then the one closest to the saved offset.
+func (m *Model) attachAnnotation(a Annotation) *annotation {
+	result := &annotation{Annotation: a, renderedStart: -1, renderedEnd: -1}
+	if a.Text == "" {
+		return result
+	}
+
+	needle := []byte(a.Text)
+	bestScore, bestDistance := -1, 0
+	for i := 0; i <= len(m.markdown); {
+		j := bytes.Index(m.markdown[i:], needle)
+		if j < 0 {
+			break
+		}
+		start := i + j
+		end := start + len(needle)
+		score := commonSuffixLen(m.markdown[:start], a.Before) + commonPrefixLen(m.markdown[end:], a.After)
+		distance := start - a.Offset
+		if distance < 0 {
+			distance = -distance
+		}
+		if score > bestScore || score == bestScore && distance < bestDistance {
+			bestScore, bestDistance = score, distance
+			result.start, result.end, result.attached = start, end, true
+		}
+		i = start + 1
+	}
+	return result
+}
+
+func commonPrefixLen(b []byte, s string) int {
+	n := 0
+	for n < len(b) && n < len(s) && b[n] == s[n] {
+		n++
+	}
+	return n
+}
+
+func commonSuffixLen(b []byte, s string) int {
+	n := 0
+	for n < len(b) && n < len(s) && b[len(b)-1-n] == s[len(s)-1-n] {
+		n++
+	}
+	return n
+}
+
+// sortAnnotations orders the annotations by source offset, with annotations
+// that are not attached last.
+func (m *Model) sortAnnotations() {
+	sort.SliceStable(m.annotations.list, func(i, j int) bool {
+		a, b := m.annotations.list[i], m.annotations.list[j]
+		if a.attached != b.attached {
+			return a.attached
+		}
+		return a.start < b.start
+	})
+}
+
+// placeAnnotations computes the rendered ranges of the annotations. It is
+// called whenever the document is rendered.
+func (m *Model) placeAnnotations() {
+	if len(m.annotations.list) == 0 || m.spanTree == nil {
+		return
+	}
+	segs := m.textSegments()
+	for _, a := range m.annotations.list {
+		a.renderedStart, a.renderedEnd = -1, -1
+		if !a.attached {
+			continue
+		}
+		start, ok := m.renderedOffsetForSource(segs, a.start, false)
+		if !ok {
+			continue
+		}
+		end, ok := m.renderedOffsetForSource(segs, a.end, true)
+		if !ok {
+			continue
+		}
+		a.renderedStart, a.renderedEnd = start, end
+	}
+}
+
+// A textSegment maps a range of source text to the range of rendered text
+// that shows it.
+type textSegment struct {
+	source     text.Segment
+	start, end int
+}
+
+// textSegments returns the segments of source text in the rendered document:
+// its text nodes and the lines of its code blocks, in rendered order.
+//
+// Within a segment, offsets are mapped by counting characters that are not
+// whitespace, since wrapping and indentation change only whitespace.
+func (m *Model) textSegments() []textSegment {
+	var segs []textSegment
+	for s := m.spanTree; s != nil; s = s.Next {
+		if n, ok := s.Node.(*ast.Text); ok {
+			segs = append(segs, textSegment{source: n.Segment, start: s.Start, end: s.End})
+			continue
+		}
+		lines, first, ok := m.codeLines(s)
+		if !ok {
+			continue
+		}
+		for i := 0; i < lines.Len() && first+i < len(m.renderedLines); i++ {
+			ln := m.renderedLines[first+i]
+			segs = append(segs, textSegment{source: lines.At(i), start: ln.start, end: ln.end})
+		}
+	}
+	return segs
+}
+
+// sourceOffsetForRendered maps a rendered byte offset to a source byte
+// offset. If end is true, the offset is the end of a range and maps to the
+// end of the preceding character; otherwise it maps to the start of the
+// following character.
+func (m *Model) sourceOffsetForRendered(segs []textSegment, offset int, end bool) (int, bool) {
+	var seg *textSegment
+	if end {
+		for i := len(segs) - 1; i >= 0; i-- {
+			if segs[i].start < offset {
+				seg = &segs[i]
+				offset = min(offset, seg.end)
+				break
+			}
+		}
+	} else {
+		for i := range segs {
+			if segs[i].end > offset {
+				seg = &segs[i]
+				offset = max(offset, seg.start)
+				break
+			}
+		}
+	}
+	if seg == nil {
+		return 0, false
+	}
+
+	n := m.countRendered(seg.start, offset)
+	src := m.markdown[:seg.source.Stop]
+	count := 0
+	for i := seg.source.Start; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		if !unicode.IsSpace(r) {
+			if !end && count == n {
+				return i, true
+			}
+			count++
+			if end && count == n {
+				return i + size, true
+			}
+		}
+		i += size
+	}
+	if end && n == 0 {
+		return seg.source.Start, true
+	}
+	return seg.source.Stop, true
+}
+
+// renderedOffsetForSource maps a source byte offset to a rendered byte
+// offset. Offsets in markup between segments snap to the nearest segment in
+// the direction of the range.
+func (m *Model) renderedOffsetForSource(segs []textSegment, offset int, end bool) (int, bool) {
+	var seg *textSegment
+	for i := range segs {
+		src := segs[i].source
+		if end && src.Start < offset && offset <= src.Stop || !end && src.Start <= offset && offset < src.Stop {
+			seg = &segs[i]
+			break
+		}
+	}
+	if seg == nil {
+		for i := range segs {
+			src := segs[i].source
+			switch {
+			case end && src.Stop <= offset && (seg == nil || src.Stop > seg.source.Stop):
+				seg = &segs[i]
+			case !end && src.Start >= offset && (seg == nil || src.Start < seg.source.Start):
+				seg = &segs[i]
+			}
+		}
+		if seg == nil {
+			return 0, false
+		}
+		offset = min(max(offset, seg.source.Start), seg.source.Stop)
+	}
+
+	n := 0
+	for _, r := range string(m.markdown[seg.source.Start:offset]) {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	if end && n == 0 {
+		return seg.start, true
+	}
+
+	count := 0
+	for li := m.renderedLineAt(seg.start); li < len(m.renderedLines); li++ {
+		ln := m.renderedLines[li]
+		if ln.start >= seg.end {
+			break
+		}
+		from, to := max(seg.start, ln.start)-ln.start, min(seg.end, ln.end)-ln.start
+		content := ln.content[:min(to, len(ln.content))]
+		var state ansi.State
+		for i := from; i < len(content); {
+			seq, width, size, newState := ansi.DecodeSequence(content[i:], state, nil)
+			state = newState
+			if width > 0 {
+				if r, _ := utf8.DecodeRuneInString(seq); !unicode.IsSpace(r) {
+					if !end && count == n {
+						return ln.start + i, true
+					}
+					count++
+					if end && count == n {
+						return ln.start + i + size, true
+					}
+				}
+			}
+			i += size
+		}
+	}
+	return seg.end, true
+}
+
+// countRendered returns the number of characters other than whitespace that
+// are rendered between the given byte offsets.
+func (m *Model) countRendered(start, end int) int {
+	n := 0
+	for _, r := range m.renderedText(start, end) {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// Annotate creates an annotation for the visual selection or the selected
+// node and shows the note prompt. Outside of visual mode, the note of the
+// annotation last navigated to is edited instead if it overlaps the selected
+// node or if nothing is selected. Returns false if there is nothing to
+// annotate.
+func (m *Model) Annotate() bool {
+	start, end, ok := m.copyRange()
+	if a := m.annotations.current; a != nil && !m.visualMode && (!ok || a.overlaps(start, end)) {
+		m.annotations.editing = true
+		m.annotations.input = a.Note
+		m.annotations.draft = nil
+		return true
+	}
+	if !ok {
+		return false
+	}
+
+	segs := m.textSegments()
+	srcStart, ok := m.sourceOffsetForRendered(segs, start, false)
+	if !ok {
+		return false
+	}
+	srcEnd, ok := m.sourceOffsetForRendered(segs, end, true)
+	if !ok || srcEnd <= srcStart {
+		return false
+	}
+	m.exitVisualMode()
+	m.annotations.draft = &annotation{
+		Annotation:    m.anchorAnnotation(srcStart, srcEnd, ""),
+		start:         srcStart,
+		end:           srcEnd,
+		attached:      true,
+		renderedStart: -1,
+		renderedEnd:   -1,
+	}
+	m.annotations.editing = true
+	m.annotations.input = ""
+	return true
+}
+
+// handleAnnotationKey handles key events while the note prompt is shown.
+func (m *Model) handleAnnotationKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.annotations.editing = false
+		m.annotations.draft = nil
+		m.annotations.input = ""
+		return nil
+	case "enter":
+		m.annotations.editing = false
+		note := strings.TrimSpace(m.annotations.input)
+		m.annotations.input = ""
+		if a := m.annotations.draft; a != nil {
+			m.annotations.draft = nil
+			a.Note = note
+			m.annotations.list = append(m.annotations.list, a)
+			m.sortAnnotations()
+			m.placeAnnotations()
+			m.annotations.current = a
+			m.statusMessage = "annotation added"
+		} else if a := m.annotations.current; a != nil {
+			a.Note = note
+			m.statusMessage = "note saved"
+		}
+		return func() tea.Msg { return AnnotationsChangedMsg{} }
+	case "backspace":
+		if len(m.annotations.input) > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.annotations.input)
+			m.annotations.input = m.annotations.input[:len(m.annotations.input)-size]
+		}
+		return nil
+	default:
+		if msg.Text != "" {
+			m.annotations.input += msg.Text
+		}
+		return nil
+	}
+}
+
+// renderedAnnotations returns the annotations shown in the rendered
+// document, in rendered order.
+func (m *Model) renderedAnnotations() []*annotation {
+	var shown []*annotation
+	for _, a := range m.annotations.list {
+		if a.rendered() {
+			shown = append(shown, a)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		return shown[i].renderedStart < shown[j].renderedStart
+	})
+	return shown
+}
+
+// NextAnnotation scrolls to the next annotation and shows its note in the
+// status line, wrapping around at the end of the document. Returns false if
+// no annotation is shown.
+func (m *Model) NextAnnotation() bool {
+	return m.stepAnnotation(1)
+}
+
+// PrevAnnotation scrolls to the previous annotation and shows its note in
+// the status line, wrapping around at the start of the document. Returns
+// false if no annotation is shown.
+func (m *Model) PrevAnnotation() bool {
+	return m.stepAnnotation(-1)
+}
+
+func (m *Model) stepAnnotation(dir int) bool {
+	shown := m.renderedAnnotations()
+	if len(shown) == 0 {
+		return false
+	}
+
+	idx := -1
+	for i, a := range shown {
+		if a == m.annotations.current {
+			idx = (i + dir + len(shown)) % len(shown)
+			break
+		}
+	}
+	if idx < 0 {
+		// Start from the top of the viewport.
+		top := 0
+		if m.lineOffset < len(m.lines) {
+			top = m.lines[m.lineOffset].start
+		}
+		idx = sort.Search(len(shown), func(i int) bool { return shown[i].renderedStart >= top })
+		if dir < 0 {
+			idx--
+		}
+		idx = (idx + len(shown)) % len(shown)
+	}
+
+	a := shown[idx]
+	m.annotations.current = a
+	li := m.renderedLineAt(a.renderedStart)
+	m.revealOffset(a.renderedStart)
+	m.lineOffset = max(m.displayedIndex(li)-m.pageSize/2, 0)
+	m.clampOffsets()
+
+	note := a.Note
+	if note == "" {
+		note = "(no note)"
+	}
+	m.statusMessage = fmt.Sprintf("[%d/%d] %s", idx+1, len(shown), note)
+	return true
+}
+
+// DeleteAnnotation deletes the annotation that overlaps the visual
+// selection. Outside of visual mode, it deletes the annotation last navigated
+// to or, if there is none, the annotation that overlaps the selected node.
+// Returns false if there is no such annotation.
+func (m *Model) DeleteAnnotation() bool {
+	var target *annotation
+	if m.visualMode || m.annotations.current == nil {
+		if start, end, ok := m.copyRange(); ok {
+			for _, a := range m.renderedAnnotations() {
+				if a.overlaps(start, end) {
+					target = a
+					break
+				}
+			}
+		}
+	} else {
+		target = m.annotations.current
+	}
+	if target == nil {
+		return false
+	}
+	for i, a := range m.annotations.list {
+		if a == target {
+			m.annotations.list = append(m.annotations.list[:i], m.annotations.list[i+1:]...)
+			break
+		}
+	}
+	m.annotations.current = nil
+	m.exitVisualMode()
+	m.statusMessage = "annotation deleted"
+	return true
+}
+
+// annotationColumns returns the column ranges of a displayed line that are
+// covered by annotations. It must be called with the line's content before
+// any other highlighting is applied.
+func (m *Model) annotationColumns(ln line, content string) [][2]int {
+	var cols [][2]int
+	for _, a := range m.annotations.list {
+		if !a.rendered() {
+			continue
+		}
+		if c, ok := rangeColumns(ln, content, a.renderedStart, a.renderedEnd); ok {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// rangeColumns returns the columns of a displayed line that are covered by the
+// given range of rendered offsets, if any.
+func rangeColumns(ln line, content string, start, end int) ([2]int, bool) {
+	if end <= ln.start || start >= ln.end {
+		return [2]int{}, false
+	}
+	from, to := 0, ansi.StringWidth(content)
+	if start > ln.start {
+		from = ansi.StringWidth(content[:min(start-ln.start, len(content))])
+	}
+	if end < ln.end {
+		to = ansi.StringWidth(content[:min(end-ln.start, len(content))])
+	}
+	return [2]int{from, to}, from < to
+}
+
+// applyAnnotations applies the annotation style to the given column ranges
+// of a line. Themes may style annotations with the styles.Annotation token;
+// otherwise annotated text is shown on a cyan background.
+func (m *Model) applyAnnotations(content string, cols [][2]int) string {
+	on, off := m.tokenStyle(styles.Annotation, "\033[30;46m", "\033[39;49m")
+	return applyColumns(content, cols, on, off)
+}
+
+// applyColumns wraps the given column ranges of a line in the given SGR
+// sequences.
+func applyColumns(content string, cols [][2]int, on, off string) string {
+	lineWidth := ansi.StringWidth(content)
+	// Ranges are applied from the right so that the columns of the others
+	// are unaffected.
+	sort.Slice(cols, func(i, j int) bool { return cols[i][0] > cols[j][0] })
+	for _, c := range cols {
+		before := ansiSlice(content, 0, c[0])
+		middle := ansiSlice(content, c[0], c[1])
+		after := ansiSlice(content, c[1], lineWidth)
+		idx := firstNonANSIByteIndex(middle)
+		content = before + middle[:idx] + on + middle[idx:] + off + after
+	}
+	return content
+}
+
+// tokenStyle returns the SGR sequences that turn the theme's style for the
+// given token on and off, or the given defaults if the theme does not style
+// the token.
+func (m *Model) tokenStyle(token chroma.TokenType, defaultOn, defaultOff string) (on, off string) {
+	if m.theme == nil || !m.theme.Has(token) {
+		return defaultOn, defaultOff
+	}
+	return styleSGR(m.theme.Get(token)), "\033[0m"
+}
+
+// styleSGR returns the SGR sequence that turns the given style on.
+func styleSGR(e chroma.StyleEntry) string {
+	var sb strings.Builder
+	if e.Colour.IsSet() {
+		fmt.Fprintf(&sb, "\033[38;2;%d;%d;%dm", e.Colour.Red(), e.Colour.Green(), e.Colour.Blue())
+	}
+	if e.Background.IsSet() {
+		fmt.Fprintf(&sb, "\033[48;2;%d;%d;%dm", e.Background.Red(), e.Background.Green(), e.Background.Blue())
+	}
+	if e.Bold == chroma.Yes {
+		sb.WriteString("\033[1m")
+	}
+	if e.Italic == chroma.Yes {
+		sb.WriteString("\033[3m")
+	}
+	if e.Underline == chroma.Yes {
+		sb.WriteString("\033[4m")
+	}
+	return sb.String()
+}
+
+// AnnotationSummary returns the annotations of the current document as
+// Markdown. Each highlighted passage is quoted under the breadcrumbs of the
+// section that contains it and followed by its note. Returns the empty
+// string if the document has no annotations.
+func (m *Model) AnnotationSummary() string {
+	if len(m.annotations.list) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Annotations: %s\n", m.name)
+
+	section := ""
+	for _, a := range m.annotations.list {
+		var heading string
+		switch {
+		case !a.attached:
+			heading = "Detached"
+		case a.rendered():
+			heading = strings.Join(m.breadcrumbsAt(a.renderedStart), " > ")
+		default:
+			heading = section
+		}
+		if heading != section {
+			fmt.Fprintf(&sb, "\n## %s\n", heading)
+			section = heading
+		}
+
+		sb.WriteByte('\n')
+		for _, l := range strings.Split(strings.TrimRight(a.Text, "\n"), "\n") {
+			sb.WriteString(strings.TrimRight("> "+l, " "))
+			sb.WriteByte('\n')
+		}
+		if a.Note != "" {
+			sb.WriteByte('\n')
+			sb.WriteString(a.Note)
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,191 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const annotationDoc = "# Design\n\n## Goals\n\nThe system must be fast and the system must be simple.\n\n```go\nfmt.Println(1)\n```\n\n## Risks\n\nSee [the *docs*](https://docs.example) for more.\n"
+
+func newAnnotationModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(WithTheme(styles.Pulumi), WithGutter(true))
+	m.SetText("design.md", annotationDoc)
+	m.SetSize(80, 20)
+	m.View()
+	return m
+}
+
+// annotationFor returns an annotation of the given occurrence of text in the
+// annotation document.
+func annotationFor(text string, occurrence int, note string) Annotation {
+	offset := -1
+	for range occurrence + 1 {
+		offset += 1 + strings.Index(annotationDoc[offset+1:], text)
+	}
+	m := Model{markdown: []byte(annotationDoc)}
+	return m.anchorAnnotation(offset, offset+len(text), note)
+}
+
+func TestAnnotate_VisualSelection(t *testing.T) {
+	m := newAnnotationModel(t)
+
+	// Select the word "fast".
+	for i, ln := range m.lines {
+		if col := strings.Index(ansi.Strip(ln.content), "fast"); col >= 0 {
+			m.cursorLine, m.cursorCol, m.cursorPositioned = i, col, true
+			break
+		}
+	}
+	m, _ = m.Update(keyMsg('v'))
+	m, _ = m.Update(keyMsg('e'))
+	require.True(t, m.visualMode)
+
+	m, _ = m.Update(keyMsg('a'))
+	require.True(t, m.AnnotationInputActive())
+	assert.False(t, m.visualMode)
+	for _, r := range "needs a number" {
+		m, _ = m.Update(keyMsg(r))
+	}
+	assert.Contains(t, ansi.Strip(m.View()), "note: needs a number_")
+
+	m, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+	assert.False(t, m.AnnotationInputActive())
+	require.NotNil(t, cmd)
+	assert.Equal(t, AnnotationsChangedMsg{}, cmd())
+
+	annotations := m.Annotations()
+	require.Len(t, annotations, 1)
+	assert.Equal(t, "fast", annotations[0].Text)
+	assert.Equal(t, "needs a number", annotations[0].Note)
+	assert.True(t, strings.HasSuffix(annotations[0].Before, "The system must be "))
+	assert.True(t, strings.HasPrefix(annotations[0].After, " and the system"))
+
+	// The passage is highlighted.
+	assert.Contains(t, m.View(), "\033[30;46mfast")
+}
+
+func TestAnnotate_Cancel(t *testing.T) {
+	m := newAnnotationModel(t)
+	m, _ = m.Update(keyMsg(']'))
+	m, _ = m.Update(keyMsg('a'))
+	require.True(t, m.AnnotationInputActive())
+
+	m, cmd := m.Update(specialKeyMsg(tea.KeyEscape))
+	assert.False(t, m.AnnotationInputActive())
+	assert.Nil(t, cmd)
+	assert.Empty(t, m.Annotations())
+}
+
+func TestAnnotations_RenderedRanges(t *testing.T) {
+	m := newAnnotationModel(t)
+	m.SetAnnotations([]Annotation{
+		annotationFor("must be fast", 0, ""),
+		annotationFor("Println", 0, ""),
+		annotationFor("for more", 0, ""),
+	})
+
+	var texts []string
+	for _, a := range m.annotations.list {
+		require.True(t, a.rendered(), "%q is not rendered", a.Text)
+		texts = append(texts, m.renderedText(a.renderedStart, a.renderedEnd))
+	}
+	assert.Equal(t, []string{"must be fast", "Println", "for more"}, texts)
+}
+
+func TestSetAnnotations_Reattach(t *testing.T) {
+	m := newAnnotationModel(t)
+	saved := []Annotation{annotationFor("system", 1, "second")}
+	m.SetAnnotations(saved)
+
+	// After an edit, the passage is found again by its context rather than
+	// by its offset, which is now closer to the other occurrence.
+	edited := strings.Replace(annotationDoc, "## Goals\n", "## Goals\n\nA new paragraph that moves the rest down.\n", 1)
+	m.SetText("design.md", edited)
+	m.SetAnnotations(saved)
+
+	annotations := m.Annotations()
+	require.Len(t, annotations, 1)
+	want := strings.Index(edited, "and the system") + len("and the ")
+	assert.Equal(t, want, annotations[0].Offset)
+	assert.Equal(t, "second", annotations[0].Note)
+
+	// Annotations whose text is gone are kept but not shown.
+	m.SetText("design.md", strings.ReplaceAll(edited, "system", "design"))
+	m.SetAnnotations(saved)
+	require.Len(t, m.Annotations(), 1)
+	assert.Equal(t, saved[0], m.Annotations()[0])
+	assert.Empty(t, m.renderedAnnotations())
+}
+
+func TestAnnotations_Navigate(t *testing.T) {
+	m := newAnnotationModel(t)
+	m.SetAnnotations([]Annotation{
+		annotationFor("Println", 0, "code"),
+		annotationFor("fast", 0, "speed"),
+	})
+
+	m, _ = m.Update(keyMsg(')'))
+	assert.Equal(t, "[1/2] speed", m.statusMessage)
+	m, _ = m.Update(keyMsg(')'))
+	assert.Equal(t, "[2/2] code", m.statusMessage)
+	m, _ = m.Update(keyMsg(')'))
+	assert.Equal(t, "[1/2] speed", m.statusMessage)
+	m, _ = m.Update(keyMsg('('))
+	assert.Equal(t, "[2/2] code", m.statusMessage)
+
+	// Without a selection, the current annotation's note is edited.
+	m, _ = m.Update(keyMsg('a'))
+	require.True(t, m.AnnotationInputActive())
+	m, _ = m.Update(keyMsg('!'))
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	assert.Equal(t, "code!", m.Annotations()[1].Note)
+
+	m, cmd := m.Update(keyMsg('X'))
+	require.NotNil(t, cmd)
+	assert.Equal(t, AnnotationsChangedMsg{}, cmd())
+	annotations := m.Annotations()
+	require.Len(t, annotations, 1)
+	assert.Equal(t, "fast", annotations[0].Text)
+}
+
+func TestAnnotationSummary(t *testing.T) {
+	m := newAnnotationModel(t)
+	assert.Equal(t, "", m.AnnotationSummary())
+
+	m.SetAnnotations([]Annotation{
+		annotationFor("must be fast", 0, "How fast?"),
+		annotationFor("Println", 0, ""),
+		annotationFor("the *docs*", 0, "Link the new docs."),
+		{Text: "removed text", Note: "Gone."},
+	})
+	want := `# Annotations: design.md
+
+## Design > Goals
+
+> must be fast
+
+How fast?
+
+> Println
+
+## Design > Risks
+
+> the *docs*
+
+Link the new docs.
+
+## Detached
+
+> removed text
+
+Gone.
+`
+	assert.Equal(t, want, m.AnnotationSummary())
+}
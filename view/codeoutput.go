@@ -0,0 +1,257 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+)
+
+// CodeBlock is a code block of the document.
+type CodeBlock struct {
+	// Index is the index of the code block among the document's code blocks.
+	// It identifies the block to SetCodeOutput until the text is next set.
+	Index int
+	// Language is the language named by a fenced code block's info string.
+	Language string
+	// Code is the content of the code block.
+	Code string
+}
+
+// CodeOutput is the output of running a code block, shown under the block.
+type CodeOutput struct {
+	// Text is the output of the run so far.
+	Text string
+	// Status summarizes the run, e.g. "running" or "exit status 1".
+	Status string
+	// Failed is true if the run failed. The status of a failed run is shown
+	// in red.
+	Failed bool
+}
+
+// codeOutput is the output shown under a code block.
+type codeOutput struct {
+	CodeOutput
+	collapsed bool
+}
+
+// codeBlockNodes returns the code block nodes of the document in document
+// order.
+func (m *Model) codeBlockNodes() []ast.Node {
+	if m.document == nil {
+		return nil
+	}
+	var nodes []ast.Node
+	ast.Walk(m.document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			if _, ok := isCodeBlock(n); ok {
+				nodes = append(nodes, n)
+				return ast.WalkSkipChildren, nil
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return nodes
+}
+
+// FocusedCodeBlock returns the selected code block. Returns false if the
+// selection is not a code block.
+func (m *Model) FocusedCodeBlock() (CodeBlock, bool) {
+	if m.selection == nil {
+		return CodeBlock{}, false
+	}
+	if _, ok := isCodeBlock(m.selection.Node); !ok {
+		return CodeBlock{}, false
+	}
+	for i, n := range m.codeBlockNodes() {
+		if n != m.selection.Node {
+			continue
+		}
+		block := CodeBlock{Index: i}
+		if fenced, ok := n.(*ast.FencedCodeBlock); ok {
+			block.Language = string(fenced.Language(m.markdown))
+		}
+		var code strings.Builder
+		lines := n.Lines()
+		for j := 0; j < lines.Len(); j++ {
+			seg := lines.At(j)
+			code.Write(seg.Value(m.markdown))
+		}
+		block.Code = code.String()
+		return block, true
+	}
+	return CodeBlock{}, false
+}
+
+// SetCodeOutput shows output under the code block with the given index,
+// replacing any output shown there before. Returns false if there is no such
+// code block.
+func (m *Model) SetCodeOutput(index int, out CodeOutput) bool {
+	nodes := m.codeBlockNodes()
+	if index < 0 || index >= len(nodes) {
+		return false
+	}
+	if m.outputs == nil {
+		m.outputs = map[ast.Node]*codeOutput{}
+	}
+	if o, ok := m.outputs[nodes[index]]; ok {
+		o.CodeOutput = out
+	} else {
+		m.outputs[nodes[index]] = &codeOutput{CodeOutput: out}
+	}
+	m.relayoutOutputs()
+	return true
+}
+
+// ClearCodeOutput removes the output shown under the code block with the
+// given index.
+func (m *Model) ClearCodeOutput(index int) {
+	nodes := m.codeBlockNodes()
+	if index < 0 || index >= len(nodes) || m.outputs[nodes[index]] == nil {
+		return
+	}
+	delete(m.outputs, nodes[index])
+	m.relayoutOutputs()
+}
+
+// toggleFocusedOutput collapses or expands the output of the selected code
+// block. Returns false if the selection is not a code block with output.
+func (m *Model) toggleFocusedOutput() bool {
+	if m.selection == nil || !m.isSelectionVisible() {
+		return false
+	}
+	o, ok := m.outputs[m.selection.Node]
+	if !ok {
+		return false
+	}
+	o.collapsed = !o.collapsed
+	m.relayoutOutputs()
+	return true
+}
+
+// relayoutOutputs rebuilds the rendered lines after the outputs have changed,
+// keeping the content at the top of the viewport in place.
+func (m *Model) relayoutOutputs() {
+	if m.lines == nil {
+		// Outputs are placed when the document is next rendered.
+		return
+	}
+	m.renderedLines = m.placeOutputs(m.documentLines)
+	m.search.stale = true
+	m.refold()
+	if m.search.query != "" {
+		m.executeSearch()
+	}
+}
+
+// placeOutputs returns the given rendered lines with the outputs of code
+// blocks inserted after the blocks' last lines. Output lines are empty ranges
+// at the end of their code block, so offsets in the rendered document never
+// refer to them.
+func (m *Model) placeOutputs(lines []line) []line {
+	if len(m.outputs) == 0 {
+		return lines
+	}
+	placed := make([]line, 0, len(lines))
+	next := 0
+	for s := m.spanTree; s != nil; s = s.Next {
+		o, ok := m.outputs[s.Node]
+		if !ok || s.End <= s.Start {
+			continue
+		}
+		last := next
+		for last < len(lines) && lines[last].end < s.End {
+			last++
+		}
+		if last >= len(lines) {
+			break
+		}
+		placed = append(placed, lines[next:last+1]...)
+		next = last + 1
+
+		indent := strings.Repeat(" ", leadingSpaces(ansi.Strip(lines[lineIndexAt(lines, s.Start)].content)))
+		for _, content := range o.lines(indent) {
+			if w := ansi.StringWidth(expandTabs(content, 8)); w > m.longestLine {
+				m.longestLine = w
+			}
+			placed = append(placed, line{start: s.End, end: s.End, content: content, output: true})
+		}
+	}
+	return append(placed, lines[next:]...)
+}
+
+// lineIndexAt returns the index of the line in lines that contains the given
+// rendered offset.
+func lineIndexAt(lines []line, offset int) int {
+	for i, ln := range lines {
+		if ln.end > offset {
+			return i
+		}
+	}
+	return max(len(lines)-1, 0)
+}
+
+// leadingSpaces returns the number of spaces at the start of s.
+func leadingSpaces(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// lines returns the displayed lines of the output: a header with the status,
+// followed by the output unless it is collapsed.
+func (o *codeOutput) lines(indent string) []string {
+	text := sanitizeOutput(o.Text)
+	var body []string
+	if text != "" {
+		body = strings.Split(text, "\n")
+	}
+
+	status := o.Status
+	if status == "" {
+		status = "done"
+	}
+	if o.Failed {
+		status = "\033[31m" + status + "\033[39m"
+	}
+	marker := "▾"
+	if o.collapsed {
+		marker = "▸"
+		noun := "lines"
+		if len(body) == 1 {
+			noun = "line"
+		}
+		status += fmt.Sprintf(", %d %s", len(body), noun)
+	} else if len(body) == 0 {
+		status += ", no output"
+	}
+
+	lines := []string{fmt.Sprintf("%s\033[2m%s output (\033[22m%s\033[2m)\033[22m", indent, marker, status)}
+	if o.collapsed {
+		return lines
+	}
+	for _, l := range body {
+		lines = append(lines, indent+"\033[2m│\033[22m "+l)
+	}
+	return lines
+}
+
+// sanitizeOutput prepares program output for display: escape sequences are
+// removed, carriage returns keep only the text written after them, and the
+// trailing newline is dropped.
+func sanitizeOutput(text string) string {
+	text = ansi.Strip(strings.TrimRight(text, "\n"))
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		l = strings.TrimSuffix(l, "\r")
+		if j := strings.LastIndexByte(l, '\r'); j >= 0 {
+			l = l[j+1:]
+		}
+		lines[i] = strings.Map(func(r rune) rune {
+			if r < ' ' && r != '\t' {
+				return -1
+			}
+			return r
+		}, l)
+	}
+	return strings.Join(lines, "\n")
+}
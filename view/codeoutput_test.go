@@ -0,0 +1,87 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codeOutputDoc = "# Runbook\n\n```sh\necho hello\n```\n\nThen clean up:\n\n```bash\nrm -rf build\n```\n\nDone.\n"
+
+func newCodeOutputModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel()
+	m.SetText("test.md", codeOutputDoc)
+	m.SetSize(80, 30)
+	return m
+}
+
+func TestCodeOutput_FocusedCodeBlock(t *testing.T) {
+	m := newCodeOutputModel(t)
+	_, ok := m.FocusedCodeBlock()
+	assert.False(t, ok)
+
+	require.True(t, m.SelectNext(CodeBlockSelector))
+	require.True(t, m.SelectNext(CodeBlockSelector))
+	block, ok := m.FocusedCodeBlock()
+	require.True(t, ok)
+	assert.Equal(t, CodeBlock{Index: 1, Language: "bash", Code: "rm -rf build\n"}, block)
+}
+
+func TestCodeOutput_ShownUnderBlock(t *testing.T) {
+	m := newCodeOutputModel(t)
+	require.True(t, m.SetCodeOutput(0, CodeOutput{Text: "hello\r\nworld\x1b[31m!\x1b[0m\n", Status: "exit status 0"}))
+	assert.False(t, m.SetCodeOutput(2, CodeOutput{}))
+
+	lines := viewLines(m)
+	i := indexOfLine(lines, "output (exit status 0)")
+	require.GreaterOrEqual(t, i, 0, "missing output header:\n%s", strings.Join(lines, "\n"))
+	assert.Contains(t, lines[i-2], "echo hello")
+	assert.Contains(t, lines[i+1], "│ hello")
+	assert.Contains(t, lines[i+2], "│ world!")
+	assert.Contains(t, lines[i+3], "Then clean up:")
+
+	// Copying across the block does not include the output.
+	last := m.renderedLines[len(m.renderedLines)-1]
+	assert.NotContains(t, m.renderedText(0, last.end), "world")
+
+	m.ClearCodeOutput(0)
+	assert.Equal(t, -1, indexOfLine(viewLines(m), "output ("))
+}
+
+func TestCodeOutput_Collapse(t *testing.T) {
+	m := newCodeOutputModel(t)
+	require.True(t, m.SelectNext(CodeBlockSelector))
+	require.True(t, m.SetCodeOutput(0, CodeOutput{Text: "a\nb\n", Status: "exit status 1", Failed: true}))
+
+	m, _ = m.Update(keyMsg('z'))
+	lines := viewLines(m)
+	i := indexOfLine(lines, "▸ output (exit status 1, 2 lines)")
+	require.GreaterOrEqual(t, i, 0, "expected a collapsed output:\n%s", strings.Join(lines, "\n"))
+	assert.Contains(t, lines[i+1], "Then clean up:")
+
+	// Streaming more output keeps the block collapsed.
+	require.True(t, m.SetCodeOutput(0, CodeOutput{Text: "a\nb\nc\n", Status: "exit status 1", Failed: true}))
+	assert.GreaterOrEqual(t, indexOfLine(viewLines(m), "(exit status 1, 3 lines)"), 0)
+
+	m, _ = m.Update(keyMsg('z'))
+	assert.GreaterOrEqual(t, indexOfLine(viewLines(m), "│ c"), 0)
+}
+
+func TestCodeOutput_SurvivesRerender(t *testing.T) {
+	m := newCodeOutputModel(t)
+	require.True(t, m.SetCodeOutput(1, CodeOutput{Status: "running"}))
+	m.SetSize(60, 30)
+	assert.GreaterOrEqual(t, indexOfLine(viewLines(m), "output (running, no output)"), 0)
+}
+
+func indexOfLine(lines []string, substr string) int {
+	for i, l := range lines {
+		if strings.Contains(l, substr) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,363 @@
+package view
+
+import (
+	"bytes"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/extension"
+	goldmark_renderer "github.com/pgavlin/goldmark/renderer"
+	"github.com/pgavlin/goldmark/renderer/html"
+	"github.com/pgavlin/goldmark/util"
+)
+
+// CopyFormat is a format in which the selection can be copied.
+type CopyFormat int
+
+const (
+	// CopyText is the text of the selection as it is rendered.
+	CopyText CopyFormat = iota
+	// CopyMarkdown is the Markdown source of the selection.
+	CopyMarkdown
+	// CopyHTML is the selection rendered as HTML.
+	CopyHTML
+	// CopyURL is the destination of the selected link.
+	CopyURL
+	// CopyCode is the contents of the selected code block.
+	CopyCode
+)
+
+// copyFormats lists the formats in the order they are shown in the copy menu.
+var copyFormats = []CopyFormat{CopyText, CopyMarkdown, CopyHTML, CopyURL, CopyCode}
+
+// String returns the name of the format.
+func (f CopyFormat) String() string {
+	switch f {
+	case CopyText:
+		return "text"
+	case CopyMarkdown:
+		return "markdown"
+	case CopyHTML:
+		return "html"
+	case CopyURL:
+		return "url"
+	case CopyCode:
+		return "code"
+	}
+	return ""
+}
+
+// key returns the key that chooses the format in the copy menu.
+func (f CopyFormat) key() string {
+	return f.String()[:1]
+}
+
+// copyRange returns the rendered byte range of the visual selection or of the
+// selected node. Returns false if nothing is selected.
+func (m *Model) copyRange() (start, end int, ok bool) {
+	if m.visualMode && len(m.lines) > 0 {
+		startLine, startCol, endLine, endCol := m.visualSelectionBounds()
+		start = m.offsetAtColumn(startLine, startCol)
+		end = m.offsetAtColumn(endLine, endCol+1) // inclusive
+		return start, max(end, start), true
+	}
+	if m.selection != nil {
+		return m.selection.Start, m.selection.End, true
+	}
+	return 0, 0, false
+}
+
+// copyNodes returns the nodes to copy: the selected node, or the blocks
+// covered by the visual selection. A block is included if the selection
+// covers it entirely or if it has no child blocks.
+func (m *Model) copyNodes() []ast.Node {
+	if !m.visualMode {
+		if m.selection == nil {
+			return nil
+		}
+		return []ast.Node{m.selection.Node}
+	}
+
+	start, end, _ := m.copyRange()
+	var nodes []ast.Node
+	coveredEnd := -1
+	for s := m.spanTree; s != nil && s.Start < end; s = s.Next {
+		n := s.Node
+		if n.Type() != ast.TypeBlock || n.Kind() == ast.KindDocument || s.End <= start || s.Start < coveredEnd {
+			continue
+		}
+		leaf := n.FirstChild() == nil || n.FirstChild().Type() != ast.TypeBlock
+		if leaf || (s.Start >= start && s.End <= end) {
+			nodes = append(nodes, n)
+			coveredEnd = s.End
+		}
+	}
+	return nodes
+}
+
+// copyCodeBlock returns the code block that contains the selection, if any.
+func (m *Model) copyCodeBlock() ast.Node {
+	start, end, ok := m.copyRange()
+	if !ok {
+		return nil
+	}
+	s := m.blockSpanAt(start)
+	if s == nil || s.End < end {
+		return nil
+	}
+	if _, ok := isCodeBlock(s.Node); !ok {
+		return nil
+	}
+	return s.Node
+}
+
+// CopyFormats returns the formats in which the current selection can be
+// copied, or nil if nothing is selected.
+func (m *Model) CopyFormats() []CopyFormat {
+	if _, _, ok := m.copyRange(); !ok {
+		return nil
+	}
+	var formats []CopyFormat
+	for _, f := range copyFormats {
+		switch f {
+		case CopyURL:
+			if m.visualMode || m.FocusedLinkDestination() == "" {
+				continue
+			}
+		case CopyCode:
+			if m.copyCodeBlock() == nil {
+				continue
+			}
+		}
+		formats = append(formats, f)
+	}
+	return formats
+}
+
+// CopyAs returns the current selection in the given format. Returns the
+// empty string if the selection cannot be copied in that format.
+func (m *Model) CopyAs(format CopyFormat) string {
+	switch format {
+	case CopyText:
+		if m.visualMode {
+			return m.yankVisualSelection()
+		}
+		if start, end, ok := m.copyRange(); ok {
+			return m.renderedText(start, end)
+		}
+	case CopyMarkdown:
+		var first, last int
+		found := false
+		for _, n := range m.copyNodes() {
+			start, end, ok := m.nodeSourceRange(n)
+			if !ok {
+				continue
+			}
+			if !found || start < first {
+				first = start
+			}
+			if !found || end > last {
+				last = end
+			}
+			found = true
+		}
+		if found {
+			return string(m.markdown[first:last])
+		}
+	case CopyHTML:
+		var buf bytes.Buffer
+		r := goldmark_renderer.NewRenderer(goldmark_renderer.WithNodeRenderers(
+			util.Prioritized(html.NewRenderer(), 1000),
+			util.Prioritized(extension.NewTableHTMLRenderer(), 500),
+		))
+		for _, n := range m.copyNodes() {
+			if err := r.Render(&buf, m.markdown, n); err != nil {
+				return ""
+			}
+		}
+		return buf.String()
+	case CopyURL:
+		if !m.visualMode {
+			return m.FocusedLinkDestination()
+		}
+	case CopyCode:
+		if n := m.copyCodeBlock(); n != nil {
+			var sb strings.Builder
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				sb.Write(line.Value(m.markdown))
+			}
+			return sb.String()
+		}
+	}
+	return ""
+}
+
+// renderedText returns the plain text rendered between the given byte
+// offsets, without trailing spaces.
+func (m *Model) renderedText(start, end int) string {
+	var lines []string
+	for li := m.renderedLineAt(start); li < len(m.renderedLines); li++ {
+		ln := m.renderedLines[li]
+		if ln.start >= end {
+			break
+		}
+		if ln.output {
+			continue
+		}
+		from, to := max(start, ln.start)-ln.start, min(end, ln.end)-ln.start
+		text := ansi.Strip(ln.content[from:min(to, len(ln.content))])
+		lines = append(lines, strings.TrimRight(text, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nodeSourceRange returns the range of the Markdown source from which the
+// given node was parsed. Blocks span whole lines, including the fences of
+// fenced code blocks; links include their brackets and destination.
+func (m *Model) nodeSourceRange(n ast.Node) (start, end int, ok bool) {
+	src := m.markdown
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		extend := func(s, e int) {
+			if !ok || s < start {
+				start = s
+			}
+			if !ok || e > end {
+				end = e
+			}
+			ok = true
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			extend(c.Segment.Start, c.Segment.Stop)
+		case *ast.RawHTML:
+			for i := 0; i < c.Segments.Len(); i++ {
+				seg := c.Segments.At(i)
+				extend(seg.Start, seg.Stop)
+			}
+		default:
+			if c.Type() == ast.TypeBlock {
+				lines := c.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					seg := lines.At(i)
+					extend(seg.Start, seg.Stop)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if !ok {
+		return 0, 0, false
+	}
+
+	if n.Type() == ast.TypeBlock {
+		// Include the whole of the first and last lines.
+		start = bytes.LastIndexByte(src[:start], '\n') + 1
+		if end > start && src[end-1] == '\n' {
+			end--
+		}
+		if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+			end += i
+		} else {
+			end = len(src)
+		}
+		if n.Kind() == ast.KindFencedCodeBlock {
+			// Include the opening and closing fences.
+			if start > 0 {
+				start = bytes.LastIndexByte(src[:start-1], '\n') + 1
+			}
+			if end < len(src) {
+				if i := bytes.IndexByte(src[end+1:], '\n'); i >= 0 {
+					end += 1 + i
+				} else {
+					end = len(src)
+				}
+			}
+		}
+		return start, end, true
+	}
+
+	if _, isLink := n.(*ast.Link); isLink {
+		// The link text may end in emphasis delimiters, which are not part of
+		// any text segment.
+		if i := bytes.LastIndexByte(src[:start], '['); i >= 0 {
+			start = i
+		}
+		if i := bytes.IndexByte(src[end:], ']'); i >= 0 {
+			end += i + 1
+			if end < len(src) && (src[end] == '(' || src[end] == '[') {
+				closing := byte(')')
+				if src[end] == '[' {
+					closing = ']'
+				}
+				if i := bytes.IndexByte(src[end:], closing); i >= 0 {
+					end += i + 1
+				}
+			}
+		}
+	}
+	return start, end, true
+}
+
+// CopyMenuActive reports whether the Model is showing the copy menu and
+// waiting for the key of a format. Embedders should forward the next key
+// press to the Model.
+func (m *Model) CopyMenuActive() bool {
+	return m.copyMenu
+}
+
+// ShowCopyMenu shows the copy menu, which offers the formats in which the
+// current selection can be copied. It does nothing if nothing is selected.
+func (m *Model) ShowCopyMenu() {
+	m.copyMenu = len(m.CopyFormats()) > 0
+}
+
+// copyMenuPrompt returns the text shown in the status line while the copy
+// menu is active.
+func (m *Model) copyMenuPrompt() string {
+	var sb strings.Builder
+	sb.WriteString("copy as:")
+	for _, f := range m.CopyFormats() {
+		name := f.String()
+		sb.WriteString(" [" + name[:1] + "]" + name[1:])
+	}
+	return sb.String()
+}
+
+// handleCopyMenuKey completes the copy menu with the key of a format.
+func (m *Model) handleCopyMenuKey(msg tea.KeyPressMsg) tea.Cmd {
+	m.copyMenu = false
+
+	for _, f := range m.CopyFormats() {
+		if msg.Text != f.key() {
+			continue
+		}
+		content := m.CopyAs(f)
+		m.exitVisualMode()
+		if content == "" {
+			return nil
+		}
+		m.statusMessage = "copied " + f.String()
+		return m.copy(content)
+	}
+	// Any other key cancels the menu.
+	return nil
+}
+
+// copy returns a command that writes the given text to the clipboard.
+func (m *Model) copy(text string) tea.Cmd {
+	write := tea.SetClipboard(text)
+	if m.clipboard != nil {
+		write = m.clipboard(text)
+	}
+	if m.events&CopyEvents == 0 {
+		return write
+	}
+	return tea.Batch(write, func() tea.Msg { return CopiedMsg{Text: text} })
+}
@@ -0,0 +1,96 @@
+package view
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const copyDoc = "# Title\n\nSee [the *docs*](https://docs.example) for more.\n\n```go\nfmt.Println(1)\n```\n\nLast paragraph.\n"
+
+func newCopyModel(t *testing.T, opts ...Option) Model {
+	t.Helper()
+	m := NewModel(opts...)
+	m.SetText("copy.md", copyDoc)
+	m.SetSize(80, 20)
+	m.View()
+	return m
+}
+
+func TestCopyFormats(t *testing.T) {
+	m := newCopyModel(t)
+	assert.Nil(t, m.CopyFormats())
+
+	m, _ = m.Update(keyMsg(']'))
+	assert.Equal(t, []CopyFormat{CopyText, CopyMarkdown, CopyHTML, CopyURL}, m.CopyFormats())
+
+	m, _ = m.Update(tea.KeyPressMsg{Code: ']', Mod: tea.ModCtrl})
+	assert.Equal(t, []CopyFormat{CopyText, CopyMarkdown, CopyHTML, CopyCode}, m.CopyFormats())
+}
+
+func TestCopyAs_Link(t *testing.T) {
+	m := newCopyModel(t)
+	m, _ = m.Update(keyMsg(']'))
+
+	assert.Equal(t, "the *docs*", m.CopyAs(CopyText))
+	assert.Equal(t, "[the *docs*](https://docs.example)", m.CopyAs(CopyMarkdown))
+	assert.Equal(t, `<a href="https://docs.example">the <em>docs</em></a>`, m.CopyAs(CopyHTML))
+	assert.Equal(t, "https://docs.example", m.CopyAs(CopyURL))
+	assert.Equal(t, "", m.CopyAs(CopyCode))
+}
+
+func TestCopyAs_CodeBlock(t *testing.T) {
+	m := newCopyModel(t)
+	m, _ = m.Update(tea.KeyPressMsg{Code: ']', Mod: tea.ModCtrl})
+
+	assert.Equal(t, "```go\nfmt.Println(1)\n```", m.CopyAs(CopyMarkdown))
+	assert.Equal(t, "fmt.Println(1)\n", m.CopyAs(CopyCode))
+	assert.Contains(t, m.CopyAs(CopyHTML), "<pre><code class=\"language-go\">fmt.Println(1)\n</code></pre>")
+}
+
+func TestCopyAs_VisualRange(t *testing.T) {
+	m := newCopyModel(t)
+	m.cursorLine, m.cursorPositioned = 0, true
+	m, _ = m.Update(keyMsg('v'))
+	for range 2 {
+		m, _ = m.Update(keyMsg('j'))
+	}
+	require.True(t, m.visualMode)
+
+	// The selection covers the heading and the paragraph.
+	assert.Equal(t, []CopyFormat{CopyText, CopyMarkdown, CopyHTML}, m.CopyFormats())
+	assert.Equal(t, "# Title\n\nSee [the *docs*](https://docs.example) for more.", m.CopyAs(CopyMarkdown))
+	html := m.CopyAs(CopyHTML)
+	assert.Contains(t, html, "<h1>Title</h1>")
+	assert.Contains(t, html, "<p>See <a href=")
+}
+
+func TestCopyMenu(t *testing.T) {
+	var copied string
+	m := newCopyModel(t, WithClipboard(func(text string) tea.Cmd {
+		copied = text
+		return nil
+	}))
+
+	// Without a selection there is no menu.
+	m, _ = m.Update(keyMsg('Y'))
+	assert.False(t, m.CopyMenuActive())
+
+	m, _ = m.Update(keyMsg(']'))
+	m, _ = m.Update(keyMsg('Y'))
+	require.True(t, m.CopyMenuActive())
+	assert.Equal(t, "copy as: [t]ext [m]arkdown [h]tml [u]rl", m.copyMenuPrompt())
+
+	m, _ = m.Update(keyMsg('m'))
+	assert.False(t, m.CopyMenuActive())
+	assert.Equal(t, "[the *docs*](https://docs.example)", copied)
+
+	// Other keys cancel the menu.
+	copied = ""
+	m, _ = m.Update(keyMsg('Y'))
+	m, _ = m.Update(specialKeyMsg(tea.KeyEscape))
+	assert.False(t, m.CopyMenuActive())
+	assert.Empty(t, copied)
+}
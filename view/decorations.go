@@ -0,0 +1,246 @@
+package view
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// signColumnWidth is the number of columns of the sign column.
+const signColumnWidth = 2
+
+// Decoration highlights a range of the document on behalf of the embedder,
+// e.g. a lint diagnostic, a changed passage or a misspelled word.
+type Decoration struct {
+	// Start and End are the byte range of the decoration: offsets into the
+	// Markdown source, or into the rendered text if Rendered is true. Rendered
+	// offsets refer to the current rendering of the document.
+	Start, End int
+	Rendered   bool
+
+	// Style is the style of the decorated text: a chroma.StyleEntry, e.g.
+	// from chroma.ParseStyleEntry("underline #ff5f5f"). Decorated text is
+	// underlined if Style is empty.
+	Style chroma.StyleEntry
+
+	// Sign is shown in the sign column next to the first line of the
+	// decoration, in the decoration's foreground color. The sign column is
+	// shown if any decoration has a sign; signs are cut to its width.
+	Sign string
+
+	// Message is shown in the status line when the decoration is navigated
+	// to.
+	Message string
+}
+
+// decoration is a Decoration placed in the rendered document.
+type decoration struct {
+	Decoration
+
+	// The rendered byte range of the decoration, or -1 if it is not rendered.
+	renderedStart, renderedEnd int
+
+	// The SGR sequences that turn the decoration's style on and off.
+	on, off string
+}
+
+// rendered reports whether the decoration is shown in the rendered document.
+func (d *decoration) rendered() bool {
+	return d.renderedStart >= 0 && d.renderedEnd > d.renderedStart
+}
+
+// decorationState holds the decorations of the current document.
+type decorationState struct {
+	// The decorations, in the order they were set.
+	list []*decoration
+
+	// The decoration last navigated to, if any.
+	current *decoration
+}
+
+// SetDecorations replaces the decorations of the current document. The
+// decorations are removed when the text is next set.
+func (m *Model) SetDecorations(decorations []Decoration) {
+	m.decorations = decorationState{}
+	for _, d := range decorations {
+		on, off := "\033[4m", "\033[24m"
+		if !d.Style.IsZero() {
+			on, off = styleSGR(d.Style), "\033[0m"
+		}
+		m.decorations.list = append(m.decorations.list, &decoration{Decoration: d, on: on, off: off})
+	}
+	// Showing or hiding the sign column changes the width of the text.
+	m.ensureRendered()
+	m.placeDecorations()
+}
+
+// Decorations returns the decorations of the current document.
+func (m *Model) Decorations() []Decoration {
+	if len(m.decorations.list) == 0 {
+		return nil
+	}
+	result := make([]Decoration, len(m.decorations.list))
+	for i, d := range m.decorations.list {
+		result[i] = d.Decoration
+	}
+	return result
+}
+
+// NextDecoration scrolls to the next decoration and shows its message in the
+// status line, wrapping around at the end of the document. Returns false if
+// no decoration is shown.
+func (m *Model) NextDecoration() bool {
+	return m.stepDecoration(1)
+}
+
+// PrevDecoration scrolls to the previous decoration and shows its message in
+// the status line, wrapping around at the start of the document. Returns
+// false if no decoration is shown.
+func (m *Model) PrevDecoration() bool {
+	return m.stepDecoration(-1)
+}
+
+func (m *Model) stepDecoration(dir int) bool {
+	shown := m.renderedDecorations()
+	if len(shown) == 0 {
+		return false
+	}
+
+	idx := -1
+	for i, d := range shown {
+		if d == m.decorations.current {
+			idx = (i + dir + len(shown)) % len(shown)
+			break
+		}
+	}
+	if idx < 0 {
+		// Start from the top of the viewport.
+		top := 0
+		if m.lineOffset < len(m.lines) {
+			top = m.lines[m.lineOffset].start
+		}
+		idx = sort.Search(len(shown), func(i int) bool { return shown[i].renderedStart >= top })
+		if dir < 0 {
+			idx--
+		}
+		idx = (idx + len(shown)) % len(shown)
+	}
+
+	d := shown[idx]
+	m.decorations.current = d
+	li := m.renderedLineAt(d.renderedStart)
+	m.revealOffset(d.renderedStart)
+	m.lineOffset = max(m.displayedIndex(li)-m.pageSize/2, 0)
+	m.clampOffsets()
+
+	m.statusMessage = fmt.Sprintf("[%d/%d]", idx+1, len(shown))
+	if d.Message != "" {
+		m.statusMessage += " " + d.Message
+	}
+	return true
+}
+
+// renderedDecorations returns the decorations shown in the rendered document,
+// in document order.
+func (m *Model) renderedDecorations() []*decoration {
+	var shown []*decoration
+	for _, d := range m.decorations.list {
+		if d.rendered() {
+			shown = append(shown, d)
+		}
+	}
+	sort.SliceStable(shown, func(i, j int) bool { return shown[i].renderedStart < shown[j].renderedStart })
+	return shown
+}
+
+// placeDecorations computes the rendered ranges of the decorations. It is
+// called whenever the document is rendered.
+func (m *Model) placeDecorations() {
+	if len(m.decorations.list) == 0 || m.spanTree == nil {
+		return
+	}
+	var segs []textSegment
+	for _, d := range m.decorations.list {
+		d.renderedStart, d.renderedEnd = -1, -1
+		if d.Rendered {
+			d.renderedStart, d.renderedEnd = d.Start, d.End
+			continue
+		}
+		if segs == nil {
+			segs = m.textSegments()
+		}
+		start, ok := m.renderedOffsetForSource(segs, d.Start, false)
+		if !ok {
+			continue
+		}
+		end, ok := m.renderedOffsetForSource(segs, d.End, true)
+		if !ok {
+			continue
+		}
+		d.renderedStart, d.renderedEnd = start, end
+	}
+}
+
+// signWidth returns the number of columns taken by the sign column, or 0 if
+// no decoration has a sign.
+func (m *Model) signWidth() int {
+	for _, d := range m.decorations.list {
+		if d.Sign != "" {
+			return signColumnWidth
+		}
+	}
+	return 0
+}
+
+// decorationColumns returns the column ranges of a displayed line that are
+// covered by each decoration. It must be called with the line's content
+// before any other highlighting is applied.
+func (m *Model) decorationColumns(ln line, content string) map[*decoration][][2]int {
+	var cols map[*decoration][][2]int
+	for _, d := range m.decorations.list {
+		if !d.rendered() {
+			continue
+		}
+		if c, ok := rangeColumns(ln, content, d.renderedStart, d.renderedEnd); ok {
+			if cols == nil {
+				cols = map[*decoration][][2]int{}
+			}
+			cols[d] = append(cols[d], c)
+		}
+	}
+	return cols
+}
+
+// applyDecorations applies the style of each decoration to the given column
+// ranges of a line.
+func (m *Model) applyDecorations(content string, cols map[*decoration][][2]int) string {
+	// Apply in the order the decorations were set, so that later
+	// decorations are drawn over earlier ones.
+	for _, d := range m.decorations.list {
+		if c, ok := cols[d]; ok {
+			content = applyColumns(content, c, d.on, d.off)
+		}
+	}
+	return content
+}
+
+// renderSign returns the sign column of a displayed line: the sign of the
+// first decoration that starts on the line, if any.
+func (m *Model) renderSign(ln line) string {
+	for _, d := range m.decorations.list {
+		if d.Sign == "" || !d.rendered() || d.renderedStart < ln.start || d.renderedStart >= max(ln.end, ln.start+1) {
+			continue
+		}
+		sign := ansi.Truncate(d.Sign, signColumnWidth, "")
+		pad := strings.Repeat(" ", signColumnWidth-ansi.StringWidth(sign))
+		if d.Style.Colour.IsSet() {
+			c := d.Style.Colour
+			return fmt.Sprintf("\033[38;2;%d;%d;%dm%s\033[39m%s", c.Red(), c.Green(), c.Blue(), sign, pad)
+		}
+		return sign + pad
+	}
+	return strings.Repeat(" ", signColumnWidth)
+}
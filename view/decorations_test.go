@@ -0,0 +1,95 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/alecthomas/chroma"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decorationFor returns a decoration of the first occurrence of text in the
+// annotation document.
+func decorationFor(text string, d Decoration) Decoration {
+	d.Start = strings.Index(annotationDoc, text)
+	d.End = d.Start + len(text)
+	return d
+}
+
+func TestDecorations_RenderedRanges(t *testing.T) {
+	m := newAnnotationModel(t)
+	m.SetDecorations([]Decoration{
+		decorationFor("must be fast", Decoration{}),
+		decorationFor("Println", Decoration{}),
+		decorationFor("for more", Decoration{}),
+	})
+
+	var texts []string
+	for _, d := range m.decorations.list {
+		require.True(t, d.rendered())
+		texts = append(texts, m.renderedText(d.renderedStart, d.renderedEnd))
+	}
+	assert.Equal(t, []string{"must be fast", "Println", "for more"}, texts)
+
+	// Rendered offsets are used as-is.
+	d := m.decorations.list[0]
+	m.SetDecorations([]Decoration{{Start: d.renderedStart, End: d.renderedEnd, Rendered: true}})
+	require.Len(t, m.Decorations(), 1)
+	assert.Equal(t, "must be fast", m.renderedText(m.decorations.list[0].renderedStart, m.decorations.list[0].renderedEnd))
+
+	// Decorations are removed with the text.
+	m.SetText("design.md", annotationDoc)
+	assert.Nil(t, m.Decorations())
+}
+
+func TestDecorations_View(t *testing.T) {
+	m := newAnnotationModel(t)
+	width := m.effectiveWidth()
+	style, err := chroma.ParseStyleEntry("underline #ff0000")
+	require.NoError(t, err)
+	m.SetDecorations([]Decoration{decorationFor("fast", Decoration{Style: style})})
+	assert.Equal(t, width, m.effectiveWidth())
+
+	view := m.View()
+	assert.Contains(t, view, "\033[38;2;255;0;0m\033[4mfast\033[0m")
+
+	m.SetDecorations([]Decoration{decorationFor("fast", Decoration{Sign: "E", Message: "too slow"})})
+	assert.Equal(t, width-signColumnWidth, m.effectiveWidth())
+	view = m.View()
+	assert.Contains(t, view, "\033[4mfast\033[24m")
+	for _, l := range strings.Split(ansi.Strip(view), "\n") {
+		if strings.Contains(l, "fast") {
+			assert.True(t, strings.HasPrefix(l, "E "), "%q", l)
+		} else if strings.Contains(l, "Design") {
+			assert.True(t, strings.HasPrefix(l, "  "), "%q", l)
+		}
+	}
+}
+
+func TestDecorations_Navigate(t *testing.T) {
+	m := newAnnotationModel(t)
+	m.SetDecorations([]Decoration{
+		decorationFor("Println", Decoration{Message: "code"}),
+		decorationFor("fast", Decoration{Message: "speed"}),
+		decorationFor("docs", Decoration{}),
+	})
+
+	next := tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl}
+	prev := tea.KeyPressMsg{Code: 'p', Mod: tea.ModCtrl}
+	m, _ = m.Update(next)
+	assert.Equal(t, "[1/3] speed", m.statusMessage)
+	m, _ = m.Update(next)
+	assert.Equal(t, "[2/3] code", m.statusMessage)
+	m, _ = m.Update(next)
+	assert.Equal(t, "[3/3]", m.statusMessage)
+	m, _ = m.Update(next)
+	assert.Equal(t, "[1/3] speed", m.statusMessage)
+	m, _ = m.Update(prev)
+	assert.Equal(t, "[3/3]", m.statusMessage)
+
+	m.SetDecorations(nil)
+	assert.False(t, m.NextDecoration())
+}
@@ -0,0 +1,146 @@
+package view
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// Events selects the event messages that a Model sends from Update to report
+// changes of its state. No event messages are sent by default; see
+// WithEvents.
+//
+// Event messages report changes made while handling a message in Update.
+// Changes made by calling the Model's methods directly are not reported.
+type Events uint
+
+const (
+	// SelectionEvents sends a SelectionChangedMsg when the selection changes.
+	SelectionEvents Events = 1 << iota
+	// ScrollEvents sends a ScrollMsg when the viewport scrolls.
+	ScrollEvents
+	// SectionEvents sends a SectionEnteredMsg when the section at the top of
+	// the viewport changes.
+	SectionEvents
+	// SearchEvents sends a SearchChangedMsg when the search query, prompt, or
+	// current match changes.
+	SearchEvents
+	// CopyEvents sends a CopiedMsg when text is copied to the clipboard.
+	CopyEvents
+	// ModeEvents sends a ModeChangedMsg when cursor or visual mode is entered
+	// or left.
+	ModeEvents
+
+	// AllEvents sends every event message.
+	AllEvents = SelectionEvents | ScrollEvents | SectionEvents | SearchEvents | CopyEvents | ModeEvents
+)
+
+// SelectionChangedMsg is sent when the selection changes.
+type SelectionChangedMsg struct {
+	// Selection is the new selection, or nil if nothing is selected.
+	Selection *renderer.NodeSpan
+	// Node is the selected node, or nil if nothing is selected.
+	Node ast.Node
+}
+
+// ScrollMsg is sent when the viewport scrolls.
+type ScrollMsg struct {
+	// LineOffset is the index of the first visible line.
+	LineOffset int
+	// ColumnOffset is the index of the first visible column.
+	ColumnOffset int
+	// Percent is the scroll position, from 0 at the top to 1 at the bottom.
+	Percent float64
+}
+
+// SectionEnteredMsg is sent when the innermost section containing the first
+// visible line changes.
+type SectionEnteredMsg struct {
+	// Section is the section entered, or nil if the viewport is above the
+	// first heading.
+	Section *indexer.Section
+}
+
+// SearchChangedMsg is sent when the state of the search changes.
+type SearchChangedMsg struct {
+	// Query is the search query, or "" if there is no search.
+	Query string
+	// Prompting is true while the search prompt is showing.
+	Prompting bool
+	// Current is the 1-based index of the current match, or 0 if there is no
+	// current match. Total is the number of matches.
+	Current, Total int
+}
+
+// CopiedMsg is sent when text is copied to the clipboard.
+type CopiedMsg struct {
+	Text string
+}
+
+// ModeChangedMsg is sent when cursor or visual mode is entered or left.
+type ModeChangedMsg struct {
+	CursorMode bool
+	VisualMode bool
+}
+
+// eventState is the state of a Model that is reported by event messages.
+type eventState struct {
+	selection    ast.Node
+	lineOffset   int
+	columnOffset int
+	section      *indexer.Section
+	search       SearchChangedMsg
+	mode         ModeChangedMsg
+}
+
+// eventState captures the state reported by the enabled events.
+func (m *Model) eventState() eventState {
+	var s eventState
+	if m.events&SelectionEvents != 0 && m.selection != nil {
+		s.selection = m.selection.Node
+	}
+	if m.events&ScrollEvents != 0 {
+		s.lineOffset, s.columnOffset = m.lineOffset, m.columnOffset
+	}
+	if m.events&SectionEvents != 0 {
+		s.section = m.CurrentSection()
+	}
+	if m.events&SearchEvents != 0 {
+		current, total := m.SearchPosition()
+		s.search = SearchChangedMsg{Query: m.search.query, Prompting: m.search.active, Current: current, Total: total}
+	}
+	if m.events&ModeEvents != 0 {
+		s.mode = ModeChangedMsg{CursorMode: m.cursorMode, VisualMode: m.visualMode}
+	}
+	return s
+}
+
+// eventCmd returns a command that sends the event messages for the changes
+// from the state before to the current state, batched with cmd.
+func (m *Model) eventCmd(before eventState, cmd tea.Cmd) tea.Cmd {
+	after := m.eventState()
+	var cmds []tea.Cmd
+	send := func(msg tea.Msg) {
+		cmds = append(cmds, func() tea.Msg { return msg })
+	}
+	if after.selection != before.selection {
+		send(SelectionChangedMsg{Selection: m.selection, Node: after.selection})
+	}
+	if after.lineOffset != before.lineOffset || after.columnOffset != before.columnOffset {
+		send(ScrollMsg{LineOffset: after.lineOffset, ColumnOffset: after.columnOffset, Percent: m.ScrollPercent()})
+	}
+	if after.section != before.section {
+		send(SectionEnteredMsg{Section: after.section})
+	}
+	if after.search != before.search {
+		send(after.search)
+	}
+	if after.mode != before.mode {
+		send(after.mode)
+	}
+	if len(cmds) == 0 {
+		return cmd
+	}
+	return tea.Batch(append(cmds, cmd)...)
+}
@@ -0,0 +1,116 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventMsgs runs cmd and returns the event messages it sends.
+func eventMsgs(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	var msgs []tea.Msg
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			msgs = append(msgs, eventMsgs(c)...)
+		}
+	case SelectionChangedMsg, ScrollMsg, SectionEnteredMsg, SearchChangedMsg, CopiedMsg, ModeChangedMsg:
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func newEventModel(t *testing.T, events Events) Model {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("# Top\n\nSee [one](https://one.example).\n\n")
+	for i := 0; i < 40; i++ {
+		sb.WriteString("A line of text.\n\n")
+	}
+	sb.WriteString("## Bottom\n\n")
+	for i := 0; i < 40; i++ {
+		sb.WriteString("More text.\n\n")
+	}
+
+	m := NewModel(WithEvents(events))
+	m.SetText("events.md", sb.String())
+	m.SetSize(80, 24)
+	return m
+}
+
+func TestEvents_OffByDefault(t *testing.T) {
+	m := newEventModel(t, 0)
+	m, cmd := m.Update(keyMsg('j'))
+	assert.Empty(t, eventMsgs(cmd))
+	_, cmd = m.Update(keyMsg(']'))
+	assert.Empty(t, eventMsgs(cmd))
+}
+
+func TestEvents_ScrollAndSection(t *testing.T) {
+	m := newEventModel(t, ScrollEvents|SectionEvents)
+	m, cmd := m.Update(keyMsg('j'))
+	assert.Equal(t, []tea.Msg{ScrollMsg{LineOffset: 1, Percent: m.ScrollPercent()}}, eventMsgs(cmd))
+
+	_, cmd = m.Update(keyMsg('G'))
+	msgs := eventMsgs(cmd)
+	require.Len(t, msgs, 2)
+	assert.IsType(t, ScrollMsg{}, msgs[0])
+	entered, ok := msgs[1].(SectionEnteredMsg)
+	require.True(t, ok)
+	require.NotNil(t, entered.Section)
+	assert.Equal(t, 2, entered.Section.Level)
+}
+
+func TestEvents_Selection(t *testing.T) {
+	m := newEventModel(t, SelectionEvents)
+	_, cmd := m.Update(keyMsg(']'))
+	msgs := eventMsgs(cmd)
+	require.Len(t, msgs, 1)
+	selected, ok := msgs[0].(SelectionChangedMsg)
+	require.True(t, ok)
+	assert.IsType(t, (*ast.Link)(nil), selected.Node)
+	assert.Same(t, selected.Node, selected.Selection.Node)
+}
+
+func TestEvents_Search(t *testing.T) {
+	m := newEventModel(t, SearchEvents)
+	m, cmd := m.Update(keyMsg('/'))
+	assert.Equal(t, []tea.Msg{SearchChangedMsg{Prompting: true}}, eventMsgs(cmd))
+
+	m, _ = m.Update(keyMsg('x'))
+	m, _ = m.Update(keyMsg('t'))
+	_, cmd = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	msgs := eventMsgs(cmd)
+	require.Len(t, msgs, 1)
+	changed := msgs[0].(SearchChangedMsg)
+	assert.Equal(t, "xt", changed.Query)
+	assert.False(t, changed.Prompting)
+	assert.Positive(t, changed.Total)
+}
+
+func TestEvents_ModeAndCopy(t *testing.T) {
+	m := newEventModel(t, ModeEvents|CopyEvents)
+	m, cmd := m.Update(keyMsg('v'))
+	assert.Equal(t, []tea.Msg{ModeChangedMsg{VisualMode: true}}, eventMsgs(cmd))
+
+	m, _ = m.Update(keyMsg('$'))
+	_, cmd = m.Update(keyMsg('y'))
+	msgs := eventMsgs(cmd)
+	require.Len(t, msgs, 2)
+	assert.Contains(t, msgs, tea.Msg(ModeChangedMsg{}))
+	copied := -1
+	for i, msg := range msgs {
+		if _, ok := msg.(CopiedMsg); ok {
+			copied = i
+		}
+	}
+	require.GreaterOrEqual(t, copied, 0)
+	assert.NotEmpty(t, msgs[copied].(CopiedMsg).Text)
+}
@@ -0,0 +1,188 @@
+package view
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark"
+	"github.com/pgavlin/goldmark/ast"
+	goldmark_parser "github.com/pgavlin/goldmark/parser"
+	"github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/goldmark/util"
+	"github.com/pgavlin/markdown-kit/renderer"
+	"github.com/pgavlin/markdown-kit/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This file implements an example extension for custom containers:
+//
+//	::: warning
+//	Contents, which may hold any blocks.
+//	:::
+
+// kindContainer is the kind of container nodes.
+var kindContainer = ast.NewNodeKind("Container")
+
+// container is a custom container block.
+type container struct {
+	ast.BaseBlock
+
+	// Name is the name given after the opening fence, if any.
+	Name string
+}
+
+func (n *container) Kind() ast.NodeKind {
+	return kindContainer
+}
+
+func (n *container) Dump(w io.Writer, source []byte, level int) {
+	ast.DumpHelper(w, n, source, level, map[string]string{"Name": n.Name}, nil)
+}
+
+// containerParser parses custom containers.
+type containerParser struct{}
+
+// containerFence returns the rest of the line after a container fence, or
+// false if the line does not start with a fence.
+func containerFence(reader text.Reader) ([]byte, text.Segment, bool) {
+	line, segment := reader.PeekLine()
+	w, pos := util.IndentWidth(line, reader.LineOffset())
+	if w > 3 || !bytes.HasPrefix(line[pos:], []byte(":::")) {
+		return nil, segment, false
+	}
+	return line[pos+3:], segment, true
+}
+
+func (p containerParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (p containerParser) Open(parent ast.Node, reader text.Reader, pc goldmark_parser.Context) (ast.Node, goldmark_parser.State) {
+	rest, segment, ok := containerFence(reader)
+	if !ok {
+		return nil, goldmark_parser.NoChildren
+	}
+	reader.Advance(segment.Len() - 1)
+	return &container{Name: strings.TrimSpace(string(rest))}, goldmark_parser.HasChildren
+}
+
+func (p containerParser) Continue(node ast.Node, reader text.Reader, pc goldmark_parser.Context) goldmark_parser.State {
+	if rest, segment, ok := containerFence(reader); ok && util.IsBlank(rest) {
+		reader.Advance(segment.Len() - 1)
+		return goldmark_parser.Close
+	}
+	return goldmark_parser.Continue | goldmark_parser.HasChildren
+}
+
+func (p containerParser) Close(node ast.Node, reader text.Reader, pc goldmark_parser.Context) {}
+
+func (p containerParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p containerParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// containerExtension adds custom containers to the parser.
+type containerExtension struct{}
+
+func (containerExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(goldmark_parser.WithBlockParsers(util.Prioritized(containerParser{}, 50)))
+}
+
+// renderContainer renders a container as its name followed by its contents
+// behind a bar, in the style of a block quote.
+func renderContainer(r *renderer.Renderer, w util.BufWriter, source []byte, node ast.Node, enter bool) (ast.WalkStatus, error) {
+	if !enter {
+		r.PopPrefix()
+		if err := r.CloseBlock(w); err != nil {
+			return ast.WalkStop, err
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if err := r.OpenBlock(w, source, node); err != nil {
+		return ast.WalkStop, err
+	}
+	if err := r.PushStyle(w, styles.BlockquoteBar); err != nil {
+		return ast.WalkStop, err
+	}
+	if _, err := r.WriteString(w, "┃ "+strings.ToUpper(node.(*container).Name)+"\n"); err != nil {
+		return ast.WalkStop, err
+	}
+	if err := r.PopStyle(w); err != nil {
+		return ast.WalkStop, err
+	}
+	r.PushStyledPrefix("┃ ", styles.BlockquoteBar)
+	return ast.WalkContinue, nil
+}
+
+const containerDoc = "# Containers\n\nBefore.\n\n::: warning\nMind the *gap*.\n\n- one\n- two\n:::\n\nAfter.\n"
+
+func newContainerModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel(
+		WithTheme(styles.Pulumi),
+		WithParserExtension(containerExtension{}),
+		WithNodeRenderer(kindContainer, renderContainer),
+	)
+	m.SetText("containers.md", containerDoc)
+	m.SetSize(80, 20)
+	require.NotNil(t, m.lines)
+	return m
+}
+
+func TestParserExtension_Parse(t *testing.T) {
+	m := newContainerModel(t)
+
+	c, ok := m.document.FirstChild().NextSibling().NextSibling().(*container)
+	require.True(t, ok)
+	assert.Equal(t, "warning", c.Name)
+	require.Equal(t, 2, c.ChildCount())
+	assert.Equal(t, ast.KindParagraph, c.FirstChild().Kind())
+	assert.Equal(t, ast.KindList, c.LastChild().Kind())
+	assert.Equal(t, ast.KindParagraph, c.NextSibling().Kind())
+}
+
+func TestNodeRenderer_Container(t *testing.T) {
+	m := newContainerModel(t)
+
+	var lines []string
+	for _, l := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if l = strings.TrimRight(l, " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	assert.Equal(t, []string{
+		"# Containers",
+		"Before.",
+		"┃ WARNING",
+		"┃ Mind the gap.",
+		"┃ - one",
+		"┃ - two",
+		"After.",
+	}, lines)
+
+	// The container and its children are in the span tree.
+	var found bool
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node.Kind() == kindContainer {
+			found = true
+			assert.Contains(t, m.renderedText(s.Start, s.End), "Mind the")
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestNodeRenderer_WithoutExtension(t *testing.T) {
+	// Without the extension, the container is parsed as paragraphs.
+	m := NewModel()
+	m.SetText("containers.md", containerDoc)
+	m.SetSize(80, 20)
+	assert.Contains(t, ansi.Strip(m.View()), "::: warning")
+}
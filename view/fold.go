@@ -0,0 +1,307 @@
+package view
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// foldRange describes a folded section as applied to the rendered lines.
+type foldRange struct {
+	section *indexer.Section
+
+	// first and last are the indices of the first and last hidden lines in
+	// m.renderedLines.
+	first, last int
+
+	// start and end are the byte offsets of the hidden text in the rendered
+	// output.
+	start, end int
+}
+
+// Folded reports whether the given section is folded.
+func (m *Model) Folded(s *indexer.Section) bool {
+	return m.folds[s]
+}
+
+// SetFolded folds or unfolds the given section. A folded section is shown as
+// its heading followed by a summary of the number of hidden lines.
+func (m *Model) SetFolded(s *indexer.Section, folded bool) {
+	if s == nil || m.folds[s] == folded {
+		return
+	}
+	if folded {
+		if m.folds == nil {
+			m.folds = map[*indexer.Section]bool{}
+		}
+		m.folds[s] = true
+	} else {
+		delete(m.folds, s)
+	}
+	m.refold()
+}
+
+// FoldAll folds every section, leaving only the top-level headings visible.
+func (m *Model) FoldAll() {
+	m.SetFoldLevel(1)
+}
+
+// UnfoldAll unfolds every section.
+func (m *Model) UnfoldAll() {
+	m.folds = nil
+	m.refold()
+}
+
+// SetFoldLevel folds every section whose heading level is at least level and
+// unfolds the rest, so that only headings up to the given level remain
+// visible. A level of 0 or less unfolds every section.
+func (m *Model) SetFoldLevel(level int) {
+	m.folds = nil
+	if level > 0 && m.index != nil {
+		var walk func(s *indexer.Section)
+		walk = func(s *indexer.Section) {
+			for _, sub := range s.Subsections {
+				if sub.Level >= level {
+					if m.folds == nil {
+						m.folds = map[*indexer.Section]bool{}
+					}
+					m.folds[sub] = true
+				}
+				walk(sub)
+			}
+		}
+		walk(m.index.TableOfContents())
+	}
+	m.refold()
+}
+
+// foldTarget returns the section affected by the fold key bindings: the
+// section of the selected heading if one is selected, otherwise the current
+// section.
+func (m *Model) foldTarget() *indexer.Section {
+	if m.index != nil && m.selection != nil && m.isSelectionVisible() {
+		if _, ok := m.selection.Node.(*ast.Heading); ok {
+			if path := sectionPath(m.index.TableOfContents(), m.selection.Node); len(path) > 0 {
+				return path[len(path)-1]
+			}
+		}
+	}
+	return m.CurrentSection()
+}
+
+// refold re-applies the folds to the rendered lines, keeping the content at
+// the top of the viewport in place.
+func (m *Model) refold() {
+	if m.lines == nil {
+		// Folds are applied when the document is next rendered.
+		return
+	}
+	top := -1
+	if m.lineOffset < len(m.lines) {
+		top = m.lines[m.lineOffset].start
+	}
+	m.applyFolds()
+	if top >= 0 {
+		m.lineOffset = m.findLineForOffset(top)
+	}
+	m.cursorPositioned = false
+	m.clampOffsets()
+}
+
+// applyFolds builds the displayed lines from the rendered lines by replacing
+// the body of each folded section with a summary appended to its heading.
+// Folds nested inside another fold are not applied until the outer fold is
+// opened.
+func (m *Model) applyFolds() {
+	m.foldRanges, m.lineMap = nil, nil
+	if len(m.folds) == 0 || m.index == nil {
+		m.lines = m.renderedLines
+		return
+	}
+
+	headings := map[ast.Node]*renderer.NodeSpan{}
+	for s := m.spanTree; s != nil; s = s.Next {
+		if _, ok := s.Node.(*ast.Heading); ok {
+			headings[s.Node] = s
+		}
+	}
+
+	var walk func(s *indexer.Section)
+	walk = func(s *indexer.Section) {
+		for _, sub := range s.Subsections {
+			if m.folds[sub] {
+				if r, ok := m.foldRange(sub, headings); ok {
+					m.foldRanges = append(m.foldRanges, r)
+					continue
+				}
+			}
+			walk(sub)
+		}
+	}
+	walk(m.index.TableOfContents())
+
+	if len(m.foldRanges) == 0 {
+		m.lines = m.renderedLines
+		return
+	}
+
+	rendered := m.renderedLines
+	lines := make([]line, 0, len(rendered))
+	lineMap := make([]int, 0, len(rendered))
+	next := 0
+	for _, r := range m.foldRanges {
+		for i := next; i < r.first-1; i++ {
+			lines = append(lines, rendered[i])
+			lineMap = append(lineMap, i)
+		}
+
+		hidden := r.last - r.first + 1
+		noun := "lines"
+		if hidden == 1 {
+			noun = "line"
+		}
+		summary := rendered[r.first-1]
+		summary.content += fmt.Sprintf("\033[0m \033[2m(%d %s)\033[22m", hidden, noun)
+		summary.end = rendered[r.last].end
+		if w := ansi.StringWidth(expandTabs(summary.content, 8)); w > m.longestLine {
+			m.longestLine = w
+		}
+		lines = append(lines, summary)
+		lineMap = append(lineMap, r.first-1)
+
+		next = r.last + 1
+	}
+	for i := next; i < len(rendered); i++ {
+		lines = append(lines, rendered[i])
+		lineMap = append(lineMap, i)
+	}
+	m.lines, m.lineMap = lines, lineMap
+}
+
+// foldRange computes the hidden lines of a folded section: everything after
+// its heading up to the next section, excluding trailing blank lines.
+func (m *Model) foldRange(s *indexer.Section, headings map[ast.Node]*renderer.NodeSpan) (foldRange, bool) {
+	rendered := m.renderedLines
+	span, ok := headings[s.Start]
+	if !ok || len(rendered) == 0 {
+		return foldRange{}, false
+	}
+
+	headingLast := m.findRenderedLine(span.Start)
+	if headingLast >= len(rendered) {
+		return foldRange{}, false
+	}
+	for headingLast+1 < len(rendered) && rendered[headingLast+1].start < span.End {
+		headingLast++
+	}
+
+	last := len(rendered) - 1
+	if s.End != nil {
+		if end, ok := headings[s.End]; ok {
+			last = m.findRenderedLine(end.Start) - 1
+		}
+	}
+	for last > headingLast && isBlankLine(rendered[last].content) {
+		last--
+	}
+	if last <= headingLast {
+		return foldRange{}, false
+	}
+
+	first := headingLast + 1
+	return foldRange{
+		section: s,
+		first:   first,
+		last:    last,
+		start:   rendered[first].start,
+		end:     rendered[last].end,
+	}, true
+}
+
+// isBlankLine reports whether a rendered line has no visible content.
+func isBlankLine(content string) bool {
+	for _, r := range ansi.Strip(content) {
+		if r != ' ' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// findRenderedLine returns the index of the rendered line containing the
+// given byte offset.
+func (m *Model) findRenderedLine(offset int) int {
+	return sort.Search(len(m.renderedLines), func(i int) bool {
+		return m.renderedLines[i].end > offset
+	})
+}
+
+// renderedIndex maps an index into the displayed lines to the corresponding
+// index into the rendered lines. A folded section's summary line maps to its
+// heading.
+func (m *Model) renderedIndex(li int) int {
+	if m.lineMap == nil || li < 0 || li >= len(m.lineMap) {
+		return li
+	}
+	return m.lineMap[li]
+}
+
+// displayedIndex maps an index into the rendered lines to the displayed line
+// that shows it. Hidden lines map to the summary line of their fold.
+func (m *Model) displayedIndex(ri int) int {
+	if m.lineMap == nil {
+		return ri
+	}
+	// Find the last displayed line at or before ri.
+	li := sort.Search(len(m.lineMap), func(i int) bool {
+		return m.lineMap[i] > ri
+	})
+	return max(li-1, 0)
+}
+
+// hiddenOffset reports whether the given rendered byte offset lies inside a
+// folded section.
+func (m *Model) hiddenOffset(offset int) bool {
+	for _, r := range m.foldRanges {
+		if offset >= r.start && offset < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// revealOffset unfolds every section that hides the given rendered byte
+// offset.
+func (m *Model) revealOffset(offset int) {
+	for {
+		var hiding *indexer.Section
+		for _, r := range m.foldRanges {
+			if offset >= r.start && offset < r.end {
+				hiding = r.section
+				break
+			}
+		}
+		if hiding == nil {
+			return
+		}
+		delete(m.folds, hiding)
+		m.refold()
+	}
+}
+
+// revealNode unfolds every section that hides the given node.
+func (m *Model) revealNode(n ast.Node) {
+	if len(m.foldRanges) == 0 {
+		return
+	}
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node == n {
+			m.revealOffset(s.Start)
+			return
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const foldDoc = `# Intro
+
+intro text
+
+## Setup
+
+setup text with [setup link](https://setup.example.com)
+
+### Install
+
+install needle
+
+## Usage
+
+usage text with [usage link](https://usage.example.com)
+
+# Reference
+
+reference text
+`
+
+func newFoldModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel()
+	m.SetText("test.md", foldDoc)
+	m.SetSize(80, 40)
+	return m
+}
+
+func foldSection(t *testing.T, m *Model, anchor string) *indexer.Section {
+	t.Helper()
+	sections, ok := m.index.Lookup(anchor)
+	require.True(t, ok, "missing section %q", anchor)
+	return sections[0]
+}
+
+func viewLines(m Model) []string {
+	var lines []string
+	for _, l := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if l = strings.TrimRight(l, " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func TestFold_CollapsesSection(t *testing.T) {
+	m := newFoldModel(t)
+	total := m.TotalLineCount()
+
+	setup := foldSection(t, &m, "setup")
+	m.SetFolded(setup, true)
+	assert.True(t, m.Folded(setup))
+
+	assert.Equal(t, []string{
+		"# Intro",
+		"intro text",
+		"## Setup (6 lines)",
+		"## Usage",
+		"usage text with usage link",
+		"# Reference",
+		"reference text",
+	}, viewLines(m))
+	assert.Equal(t, total-6, m.TotalLineCount())
+
+	m.SetFolded(setup, false)
+	assert.Equal(t, total, m.TotalLineCount())
+	assert.Contains(t, viewLines(m), "install needle")
+}
+
+func TestFold_SummaryLineCoversHiddenOffsets(t *testing.T) {
+	m := newFoldModel(t)
+	m.SetFolded(foldSection(t, &m, "setup"), true)
+
+	// Offsets inside the fold resolve to the summary line.
+	summary := -1
+	for i, ln := range m.lines {
+		if strings.Contains(ansi.Strip(ln.content), "## Setup") {
+			summary = i
+		}
+	}
+	require.GreaterOrEqual(t, summary, 0)
+	for s := m.spanTree; s != nil; s = s.Next {
+		if _, ok := s.Node.(*ast.Link); ok && m.hiddenOffset(s.Start) {
+			assert.Equal(t, summary, m.findLineForOffset(s.Start))
+		}
+	}
+}
+
+func TestFold_Levels(t *testing.T) {
+	m := newFoldModel(t)
+
+	m.FoldAll()
+	assert.Equal(t, []string{"# Intro (14 lines)", "# Reference (2 lines)"}, viewLines(m))
+
+	m.SetFoldLevel(2)
+	assert.Equal(t, []string{
+		"# Intro",
+		"intro text",
+		"## Setup (6 lines)",
+		"## Usage (2 lines)",
+		"# Reference",
+		"reference text",
+	}, viewLines(m))
+
+	m.SetFoldLevel(0)
+	assert.Contains(t, viewLines(m), "install needle")
+}
+
+func TestFold_NestedFoldsOpenOneAtATime(t *testing.T) {
+	m := newFoldModel(t)
+	m.FoldAll()
+
+	m.SetFolded(foldSection(t, &m, "intro"), false)
+	assert.Equal(t, []string{
+		"# Intro",
+		"intro text",
+		"## Setup (6 lines)",
+		"## Usage (2 lines)",
+		"# Reference (2 lines)",
+	}, viewLines(m))
+}
+
+func TestFold_SearchRevealsCurrentMatch(t *testing.T) {
+	m := newFoldModel(t)
+	m.FoldAll()
+
+	m.search.query = "needle"
+	m.executeSearch()
+	require.Len(t, m.search.matches, 1)
+
+	lines := viewLines(m)
+	assert.Contains(t, lines, "install needle")
+	assert.Contains(t, lines, "# Reference (2 lines)", "unrelated folds should stay closed")
+	assert.Contains(t, m.applySearchHighlights(m.displayedIndex(m.search.matches[0].lineIndex), "install needle"), "\033[7m")
+}
+
+func TestFold_LinkNavigationSkipsHiddenLinks(t *testing.T) {
+	m := newFoldModel(t)
+	m.SetFolded(foldSection(t, &m, "setup"), true)
+
+	m, _ = m.Update(keyMsg(']'))
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, "https://usage.example.com", m.FocusedLinkDestination())
+	assert.True(t, m.Folded(foldSection(t, &m, "setup")))
+}
+
+func TestFold_SelectAnchorReveals(t *testing.T) {
+	m := newFoldModel(t)
+	m.FoldAll()
+
+	require.True(t, m.SelectAnchor("install"))
+	heading, ok := m.Selection().Node.(*ast.Heading)
+	require.True(t, ok)
+	assert.Equal(t, "Install", string(heading.Text(m.markdown)))
+	assert.False(t, m.Folded(foldSection(t, &m, "intro")))
+	assert.False(t, m.Folded(foldSection(t, &m, "setup")))
+	assert.True(t, m.Folded(foldSection(t, &m, "reference")))
+}
+
+func TestFold_Keys(t *testing.T) {
+	m := newFoldModel(t)
+
+	// Fold the section of the selected heading.
+	m, _ = m.Update(keyMsg('}'))
+	m, _ = m.Update(keyMsg('}'))
+	m, _ = m.Update(keyMsg('z'))
+	setup := foldSection(t, &m, "setup")
+	assert.True(t, m.Folded(setup))
+	m, _ = m.Update(keyMsg('z'))
+	assert.False(t, m.Folded(setup))
+
+	m, _ = m.Update(keyMsg('<'))
+	assert.True(t, m.Folded(setup))
+	m, _ = m.Update(keyMsg('>'))
+	assert.False(t, m.Folded(setup))
+
+	m, _ = m.Update(keyMsg('Z'))
+	assert.Len(t, viewLines(m), 2)
+	m, _ = m.Update(keyMsg('Z'))
+	assert.Contains(t, viewLines(m), "install needle")
+
+	m, _ = m.Update(keyMsg('2'))
+	assert.True(t, m.Folded(setup))
+	assert.False(t, m.Folded(foldSection(t, &m, "intro")))
+}
+
+func TestFold_SurvivesRewrap(t *testing.T) {
+	m := newFoldModel(t)
+	m.SetFolded(foldSection(t, &m, "setup"), true)
+
+	m.SetSize(60, 40)
+	assert.Contains(t, viewLines(m), "## Setup (6 lines)")
+}
@@ -0,0 +1,230 @@
+package view
+
+import (
+	"strings"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// hintAlphabet is the set of characters used to build hint labels, ordered so
+// that the easiest keys to reach come first.
+const hintAlphabet = "sadfjklewcmpgh"
+
+// Selectors for the node kinds the Model navigates between. They can be passed
+// to WithHintSelectors.
+var (
+	LinkSelector      Selector = isLink
+	CodeBlockSelector Selector = isCodeBlock
+	HeadingSelector   Selector = isHeading
+)
+
+// hint is a labeled target in hint mode.
+type hint struct {
+	label     string
+	span      *renderer.NodeSpan
+	highlight bool
+	offset    int // rendered byte offset at which the label is drawn
+}
+
+type hintState struct {
+	active    bool
+	typed     string
+	newTab    bool // a label character was typed in uppercase
+	hints     []hint
+	selectors []Selector // nil means links only
+}
+
+// Hinting reports whether the Model is showing hint labels. Embedders should
+// forward key presses to the Model while it is.
+func (m *Model) Hinting() bool {
+	return m.hints.active
+}
+
+// ShowHints labels every visible node matched by the hint selectors. Typing a
+// label selects its node; links are followed as if activated with FollowLink.
+// Returns false if there is nothing to label.
+func (m *Model) ShowHints() bool {
+	m.hints.active = false
+	m.hints.typed = ""
+	m.hints.newTab = false
+	m.hints.hints = nil
+	if m.spanTree == nil || len(m.lines) == 0 || m.lineOffset >= len(m.lines) {
+		return false
+	}
+
+	selectors := m.hints.selectors
+	if selectors == nil {
+		selectors = []Selector{isLink}
+	}
+
+	vpStart := m.lines[m.lineOffset].start
+	endLine := min(m.lineOffset+m.pageSize, len(m.lines))
+	vpEnd := m.lines[endLine-1].end
+
+	var hints []hint
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Start >= vpEnd {
+			break
+		}
+		if s.Start < vpStart || m.hiddenOffset(s.Start) {
+			continue
+		}
+		for _, selector := range selectors {
+			if highlight, ok := selector(s.Node); ok {
+				offset := s.Start
+				for offset < s.End && m.isOffsetWhitespace(offset) {
+					offset++
+				}
+				hints = append(hints, hint{span: s, highlight: highlight, offset: offset})
+				break
+			}
+		}
+	}
+	if len(hints) == 0 {
+		return false
+	}
+
+	for i, label := range hintLabels(len(hints)) {
+		hints[i].label = label
+	}
+	m.hints.active = true
+	m.hints.hints = hints
+	return true
+}
+
+// HideHints leaves hint mode.
+func (m *Model) HideHints() {
+	m.hints.active = false
+	m.hints.typed = ""
+	m.hints.newTab = false
+	m.hints.hints = nil
+}
+
+// hintLabels returns n distinct labels of equal length, so that no label is a
+// prefix of another.
+func hintLabels(n int) []string {
+	length := 1
+	for count := len(hintAlphabet); count < n; count *= len(hintAlphabet) {
+		length++
+	}
+
+	labels := make([]string, n)
+	for i := range labels {
+		b := make([]byte, length)
+		for j, v := length-1, i; j >= 0; j-- {
+			b[j] = hintAlphabet[v%len(hintAlphabet)]
+			v /= len(hintAlphabet)
+		}
+		labels[i] = string(b)
+	}
+	return labels
+}
+
+// handleHintKey handles a key press in hint mode. Any key that does not extend
+// a label leaves hint mode.
+func (m *Model) handleHintKey(msg tea.KeyPressMsg) tea.Cmd {
+	switch k := msg.String(); k {
+	case "backspace":
+		if m.hints.typed == "" {
+			m.HideHints()
+		} else {
+			m.hints.typed = m.hints.typed[:len(m.hints.typed)-1]
+		}
+		return nil
+	default:
+		r := []rune(k)
+		if len(r) != 1 || !strings.ContainsRune(hintAlphabet, unicode.ToLower(r[0])) {
+			m.HideHints()
+			return nil
+		}
+		if unicode.IsUpper(r[0]) {
+			m.hints.newTab = true
+		}
+		m.hints.typed += string(unicode.ToLower(r[0]))
+	}
+
+	var match *hint
+	for i := range m.hints.hints {
+		h := &m.hints.hints[i]
+		if strings.HasPrefix(h.label, m.hints.typed) {
+			if h.label != m.hints.typed {
+				return nil
+			}
+			match = h
+			break
+		}
+	}
+	newTab := m.hints.newTab
+	m.HideHints()
+	if match == nil {
+		return nil
+	}
+	return m.activateHint(*match, newTab)
+}
+
+// activateHint selects a hinted node and follows it if it is a link. External
+// links are reported with an OpenLinkMsg.
+func (m *Model) activateHint(h hint, newTab bool) tea.Cmd {
+	m.SelectSpan(h.span, h.highlight)
+	if url := m.FocusedLinkDestination(); url != "" {
+		if newTab || !m.FollowLink() {
+			return func() tea.Msg { return OpenLinkMsg{URL: url, NewTab: newTab} }
+		}
+	}
+	return nil
+}
+
+// hintLabel is the label of a hint and the column of a line at which it is
+// drawn.
+type hintLabel struct {
+	col  int
+	text string
+}
+
+// hintColumns returns the labels of the hints that start on a displayed line.
+// It must be called with the line's content before any highlighting is
+// applied.
+func (m *Model) hintColumns(ln line, content string) []hintLabel {
+	var labels []hintLabel
+	for _, h := range m.hints.hints {
+		if h.offset < ln.start || h.offset >= ln.end || !strings.HasPrefix(h.label, m.hints.typed) {
+			continue
+		}
+		byteOffset := min(h.offset-ln.start, len(content))
+		labels = append(labels, hintLabel{col: ansi.StringWidth(content[:byteOffset]), text: h.label})
+	}
+	return labels
+}
+
+// applyHints draws the given hint labels over a line's content.
+func (m *Model) applyHints(content string, labels []hintLabel) string {
+	lineWidth := ansi.StringWidth(content)
+
+	// Labels replace the columns they cover, so a label that overlaps the
+	// next one is cut short.
+	var result strings.Builder
+	pos := 0
+	for i, l := range labels {
+		if l.col < pos {
+			continue
+		}
+		if l.col > pos {
+			result.WriteString(ansiSlice(content, pos, l.col))
+		}
+		text := l.text
+		if i+1 < len(labels) && l.col+len(text) > labels[i+1].col {
+			text = text[:labels[i+1].col-l.col]
+		}
+		result.WriteString("\033[1;30;43m")
+		result.WriteString(text)
+		result.WriteString("\033[22;39;49m")
+		pos = l.col + len(text)
+	}
+	if pos < lineWidth {
+		result.WriteString(ansiSlice(content, pos, lineWidth))
+	}
+	return result.String()
+}
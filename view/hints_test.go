@@ -0,0 +1,133 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const hintDoc = "# Links\n\nSee [one](https://one.example) and [two](https://two.example).\n\nJump to [the end](#end).\n\n```sh\necho hi\n```\n\n## End\n\nDone.\n"
+
+func newHintModel(t *testing.T, opts ...Option) Model {
+	t.Helper()
+	m := NewModel(opts...)
+	m.SetText("hints.md", hintDoc)
+	m.SetSize(80, 24)
+	return m
+}
+
+func TestHintLabels(t *testing.T) {
+	assert.Equal(t, []string{"s", "a", "d"}, hintLabels(3))
+
+	labels := hintLabels(len(hintAlphabet) + 1)
+	seen := map[string]bool{}
+	for _, l := range labels {
+		assert.Len(t, l, 2)
+		assert.False(t, seen[l], "duplicate label %q", l)
+		seen[l] = true
+	}
+}
+
+func TestHints_LabelsVisibleLinks(t *testing.T) {
+	m := newHintModel(t, WithTheme(styles.Pulumi), WithGutter(true))
+	m, _ = m.Update(keyMsg('f'))
+	require.True(t, m.Hinting())
+	require.Len(t, m.hints.hints, 3)
+	for _, h := range m.hints.hints {
+		kind := h.span.Node.Kind()
+		assert.True(t, kind == ast.KindLink || kind == ast.KindAutoLink)
+	}
+
+	view := ansi.Strip(m.View())
+	assert.Contains(t, view, "See sne and awo.")
+	assert.Contains(t, view, "-- HINT --")
+}
+
+func TestHints_LabelsWithSelectionAndSearch(t *testing.T) {
+	m := newHintModel(t, WithTheme(styles.Pulumi), WithGutter(true))
+	m.ShowSearchResult("See", 0)
+	require.Len(t, m.search.matches, 1)
+	m, _ = m.Update(keyMsg('f'))
+	m.SelectSpan(m.hints.hints[0].span, true)
+	require.True(t, m.Hinting())
+
+	view := ansi.Strip(m.View())
+	assert.Contains(t, view, "See sne and awo.")
+}
+
+func TestHints_FollowExternalLink(t *testing.T) {
+	m := newHintModel(t)
+	m, _ = m.Update(keyMsg('f'))
+	m, cmd := m.Update(keyMsg('a'))
+	assert.False(t, m.Hinting())
+	require.NotNil(t, cmd)
+	assert.Equal(t, OpenLinkMsg{URL: "https://two.example"}, cmd())
+}
+
+func TestHints_UppercaseOpensNewTab(t *testing.T) {
+	m := newHintModel(t)
+	m, _ = m.Update(keyMsg('f'))
+	m, cmd := m.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModShift, Text: "S"})
+	require.NotNil(t, cmd)
+	assert.Equal(t, OpenLinkMsg{URL: "https://one.example", NewTab: true}, cmd())
+}
+
+func TestHints_FollowInternalLink(t *testing.T) {
+	m := newHintModel(t)
+	m, _ = m.Update(keyMsg('f'))
+	m, cmd := m.Update(keyMsg('d'))
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, ast.KindHeading, m.Selection().Node.Kind())
+}
+
+func TestHints_OtherKeyCancels(t *testing.T) {
+	m := newHintModel(t, WithTheme(styles.Pulumi), WithGutter(true))
+	m, _ = m.Update(keyMsg('f'))
+	m, cmd := m.Update(keyMsg('z'))
+	assert.Nil(t, cmd)
+	assert.False(t, m.Hinting())
+	assert.Nil(t, m.Selection())
+	assert.NotContains(t, ansi.Strip(m.View()), "-- HINT --")
+}
+
+func TestHints_MultiCharacterLabels(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < len(hintAlphabet)+2; i++ {
+		b.WriteString("[link](https://example.com) ")
+	}
+	m := NewModel()
+	m.SetText("many.md", b.String())
+	m.SetSize(80, 24)
+
+	m, _ = m.Update(keyMsg('f'))
+	require.Len(t, m.hints.hints, len(hintAlphabet)+2)
+	last := m.hints.hints[len(m.hints.hints)-1]
+
+	// The first character narrows the labels; the second selects one.
+	m, cmd := m.Update(keyMsg(rune(last.label[0])))
+	assert.Nil(t, cmd)
+	assert.True(t, m.Hinting())
+	m, cmd = m.Update(keyMsg(rune(last.label[1])))
+	assert.False(t, m.Hinting())
+	require.NotNil(t, cmd)
+	assert.Same(t, last.span, m.Selection())
+}
+
+func TestHints_CustomSelectors(t *testing.T) {
+	m := newHintModel(t, WithHintSelectors(CodeBlockSelector, HeadingSelector))
+	m, _ = m.Update(keyMsg('f'))
+	require.Len(t, m.hints.hints, 3)
+
+	// Selecting a code block focuses it without following anything.
+	m, cmd := m.Update(keyMsg('a'))
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, ast.KindFencedCodeBlock, m.Selection().Node.Kind())
+}
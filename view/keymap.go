@@ -33,6 +33,7 @@ type KeyMap struct {
 	GoBack     key.Binding
 
 	CopySelection key.Binding
+	CopyAs        key.Binding
 
 	CursorMode  key.Binding
 	VisualMode  key.Binding
@@ -46,6 +47,31 @@ type KeyMap struct {
 	NextMatch   key.Binding
 	PrevMatch   key.Binding
 	ClearSearch key.Binding
+
+	ToggleTOC   key.Binding
+	FocusTOC    key.Binding
+	TOCSelect   key.Binding
+	TOCExpand   key.Binding
+	TOCCollapse key.Binding
+
+	ToggleFold     key.Binding
+	Fold           key.Binding
+	Unfold         key.Binding
+	ToggleAllFolds key.Binding
+	FoldLevel      key.Binding
+
+	SetMark    key.Binding
+	JumpToMark key.Binding
+
+	Hint key.Binding
+
+	Annotate         key.Binding
+	NextAnnotation   key.Binding
+	PrevAnnotation   key.Binding
+	DeleteAnnotation key.Binding
+
+	NextDecoration key.Binding
+	PrevDecoration key.Binding
 }
 
 // DefaultKeyMap returns a KeyMap with the default key bindings matching the
@@ -141,6 +167,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("y"),
 			key.WithHelp("y", "copy current selection"),
 		),
+		CopyAs: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy selection as..."),
+		),
 		CursorMode: key.NewBinding(
 			key.WithKeys("c"),
 			key.WithHelp("c", "cursor positioning mode"),
@@ -185,6 +215,82 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "clear search"),
 		),
+		ToggleTOC: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "toggle table of contents"),
+		),
+		FocusTOC: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "focus table of contents"),
+		),
+		TOCSelect: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "go to section"),
+		),
+		TOCExpand: key.NewBinding(
+			key.WithKeys("l", "right"),
+			key.WithHelp("l/right", "expand section"),
+		),
+		TOCCollapse: key.NewBinding(
+			key.WithKeys("h", "left"),
+			key.WithHelp("h/left", "collapse section"),
+		),
+		ToggleFold: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "toggle fold"),
+		),
+		Fold: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "fold section"),
+		),
+		Unfold: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "unfold section"),
+		),
+		ToggleAllFolds: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "fold/unfold all"),
+		),
+		FoldLevel: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6"),
+			key.WithHelp("1-6", "show headings up to level"),
+		),
+		SetMark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m{a-z}", "set mark"),
+		),
+		JumpToMark: key.NewBinding(
+			key.WithKeys("'", "`"),
+			key.WithHelp("'{a-z}", "jump to mark"),
+		),
+		Hint: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "show link hints"),
+		),
+		Annotate: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "annotate selection"),
+		),
+		NextAnnotation: key.NewBinding(
+			key.WithKeys(")"),
+			key.WithHelp(")", "next annotation"),
+		),
+		PrevAnnotation: key.NewBinding(
+			key.WithKeys("("),
+			key.WithHelp("(", "previous annotation"),
+		),
+		DeleteAnnotation: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "delete annotation"),
+		),
+		NextDecoration: key.NewBinding(
+			key.WithKeys("ctrl+n"),
+			key.WithHelp("ctrl+n", "next decoration"),
+		),
+		PrevDecoration: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "previous decoration"),
+		),
 	}
 }
 
@@ -202,8 +308,13 @@ func (km KeyMap) FullHelp() [][]key.Binding {
 		{km.Left, km.Right, km.Home, km.End},
 		{km.NextLink, km.PrevLink, km.NextHeading, km.PrevHeading, km.NextCodeBlock, km.PrevCodeBlock},
 		{km.DecreaseWidth, km.IncreaseWidth},
-		{km.FollowLink, km.GoBack, km.CopySelection, km.CursorMode, km.VisualMode},
+		{km.FollowLink, km.GoBack, km.CopySelection, km.CopyAs, km.CursorMode, km.VisualMode},
 		{km.Search, km.NextMatch, km.PrevMatch, km.ClearSearch},
+		{km.ToggleTOC, km.FocusTOC, km.TOCSelect, km.TOCExpand, km.TOCCollapse},
+		{km.ToggleFold, km.Fold, km.Unfold, km.ToggleAllFolds, km.FoldLevel},
+		{km.SetMark, km.JumpToMark, km.Hint},
+		{km.Annotate, km.NextAnnotation, km.PrevAnnotation, km.DeleteAnnotation},
+		{km.NextDecoration, km.PrevDecoration},
 	}
 }
 
@@ -218,10 +329,15 @@ func (km *KeyMap) SetEnabled(enabled bool) {
 		&km.NextLink, &km.PrevLink,
 		&km.NextHeading, &km.PrevHeading,
 		&km.DecreaseWidth, &km.IncreaseWidth,
-		&km.FollowLink, &km.GoBack,
+		&km.FollowLink, &km.GoBack, &km.CopyAs,
 		&km.CursorMode, &km.VisualMode, &km.WordForward, &km.WordBack, &km.WordEnd,
 		&km.LineStart, &km.LineEnd,
 		&km.Search, &km.NextMatch, &km.PrevMatch, &km.ClearSearch,
+		&km.ToggleTOC, &km.FocusTOC, &km.TOCSelect, &km.TOCExpand, &km.TOCCollapse,
+		&km.ToggleFold, &km.Fold, &km.Unfold, &km.ToggleAllFolds, &km.FoldLevel,
+		&km.SetMark, &km.JumpToMark, &km.Hint,
+		&km.Annotate, &km.NextAnnotation, &km.PrevAnnotation, &km.DeleteAnnotation,
+		&km.NextDecoration, &km.PrevDecoration,
 	}
 	for _, b := range bindings {
 		b.SetEnabled(enabled)
@@ -0,0 +1,111 @@
+package view
+
+import (
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/styles"
+)
+
+// Link is a link in the document.
+type Link struct {
+	// Destination is the link's URL as written in the document.
+	Destination string
+	// Text is the link's text.
+	Text string
+}
+
+// Links returns the links of the document in document order. A link's index
+// in the list identifies it to SelectLink and SetBrokenLinks until the text is
+// next set.
+func (m *Model) Links() []Link {
+	var links []Link
+	for _, n := range m.linkNodes() {
+		switch n := n.(type) {
+		case *ast.AutoLink:
+			links = append(links, Link{Destination: string(n.URL(m.markdown)), Text: string(n.Label(m.markdown))})
+		case *ast.Link:
+			links = append(links, Link{Destination: string(n.Destination), Text: string(n.Text(m.markdown))})
+		}
+	}
+	return links
+}
+
+// linkNodes returns the link nodes of the document in document order.
+func (m *Model) linkNodes() []ast.Node {
+	if m.document == nil {
+		return nil
+	}
+	var nodes []ast.Node
+	ast.Walk(m.document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			if _, ok := isLink(n); ok {
+				nodes = append(nodes, n)
+				return ast.WalkSkipChildren, nil
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return nodes
+}
+
+// SelectLink selects the link with the given index in the list returned by
+// Links, expanding any folded section that hides it. Returns false if there is
+// no such link or the document has not been rendered.
+func (m *Model) SelectLink(index int) bool {
+	nodes := m.linkNodes()
+	if index < 0 || index >= len(nodes) {
+		return false
+	}
+	m.revealNode(nodes[index])
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node == nodes[index] {
+			m.SelectSpan(s, true)
+			return true
+		}
+	}
+	return false
+}
+
+// SetBrokenLinks marks the links with the given indices in the list returned
+// by Links as broken. Broken links are drawn with the styles.BrokenLink token,
+// or in red and struck through if the theme does not style it. Passing nil
+// clears the marks.
+func (m *Model) SetBrokenLinks(indices []int) {
+	m.brokenLinks = nil
+	nodes := m.linkNodes()
+	for _, i := range indices {
+		if i < 0 || i >= len(nodes) {
+			continue
+		}
+		if m.brokenLinks == nil {
+			m.brokenLinks = map[ast.Node]bool{}
+		}
+		m.brokenLinks[nodes[i]] = true
+	}
+}
+
+// brokenLinkRanges returns the rendered ranges of the broken links.
+func (m *Model) brokenLinkRanges() [][2]int {
+	var ranges [][2]int
+	for s := m.spanTree; s != nil; s = s.Next {
+		if m.brokenLinks[s.Node] {
+			ranges = append(ranges, [2]int{s.Start, s.End})
+		}
+	}
+	return ranges
+}
+
+// applyBrokenLinks applies the broken link style to the parts of a displayed
+// line covered by the given rendered ranges.
+func (m *Model) applyBrokenLinks(ln line, content string, ranges [][2]int) string {
+	var cols [][2]int
+	for _, r := range ranges {
+		if c, ok := rangeColumns(ln, content, r[0], r[1]); ok {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == 0 {
+		return content
+	}
+	on, off := m.tokenStyle(styles.BrokenLink, "\033[31;9m", "\033[39;29m")
+	return applyColumns(content, cols, on, off)
+}
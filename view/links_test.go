@@ -0,0 +1,61 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const linksDoc = "# Links\n\nSee [the *guide*](guide.md#setup) and <https://example.com>.\n\n" +
+	"Some filler.\n\nSome filler.\n\nSome filler.\n\nSome filler.\n\nAlso [a section](#links).\n"
+
+func TestLinks(t *testing.T) {
+	m := NewModel()
+	m.SetText("links.md", linksDoc)
+	m.SetSize(80, 5)
+
+	assert.Equal(t, []Link{
+		{Destination: "guide.md#setup", Text: "the guide"},
+		{Destination: "https://example.com", Text: "https://example.com"},
+		{Destination: "#links", Text: "a section"},
+	}, m.Links())
+}
+
+func TestSelectLink(t *testing.T) {
+	m := NewModel()
+	m.SetText("links.md", linksDoc)
+	m.SetSize(80, 5)
+	m.View()
+
+	require.True(t, m.SelectLink(2))
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, ast.KindLink, m.Selection().Node.Kind())
+	assert.Equal(t, "#links", m.FocusedLinkDestination())
+	assert.Contains(t, ansi.Strip(m.View()), "Also a section.")
+
+	assert.False(t, m.SelectLink(3))
+}
+
+func TestSetBrokenLinks(t *testing.T) {
+	m := NewModel()
+	m.SetText("links.md", linksDoc)
+	m.SetSize(80, 10)
+
+	m.SetBrokenLinks([]int{0, 7})
+	view := m.View()
+	assert.Contains(t, view, "\033[31;9m")
+	assert.Equal(t, 1, strings.Count(view, "\033[31;9m"))
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "\033[31;9m") {
+			assert.Contains(t, ansi.Strip(line), "See the")
+		}
+	}
+
+	// Setting the text clears the marks.
+	m.SetText("links.md", linksDoc)
+	assert.NotContains(t, m.View(), "\033[31;9m")
+}
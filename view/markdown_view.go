@@ -13,12 +13,10 @@ import (
 	"github.com/charmbracelet/x/ansi"
 	"github.com/pgavlin/goldmark"
 	"github.com/pgavlin/goldmark/ast"
-	"github.com/pgavlin/goldmark/extension"
-	goldmark_parser "github.com/pgavlin/goldmark/parser"
 	goldmark_renderer "github.com/pgavlin/goldmark/renderer"
-	"github.com/pgavlin/goldmark/text"
 	"github.com/pgavlin/goldmark/util"
 	"github.com/pgavlin/markdown-kit/indexer"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/renderer"
 )
 
@@ -27,6 +25,7 @@ type line struct {
 	end        int    // byte offset of end in rendered output
 	content    string // raw content including ANSI codes
 	ansiPrefix string // ANSI SGR state to prepend for standalone rendering
+	output     bool   // true for the lines of a code block's output
 }
 
 type lineWriter struct {
@@ -334,9 +333,40 @@ type Model struct {
 	// True if the selected span should be highlighted.
 	highlightSelection bool
 
-	// The processed lines.
+	// The displayed lines, after folding.
 	lines []line
 
+	// The rendered lines, before folding.
+	renderedLines []line
+
+	// The lines rendered from the document, before code block outputs are
+	// inserted.
+	documentLines []line
+
+	// The outputs shown under code blocks.
+	outputs map[ast.Node]*codeOutput
+
+	// Maps each displayed line to its index in renderedLines. nil if no
+	// sections are folded.
+	lineMap []int
+
+	// The folded sections and the ranges they hide in the rendered lines.
+	folds      map[*indexer.Section]bool
+	foldRanges []foldRange
+
+	// The marks set in the document, keyed by name.
+	marks map[rune]ast.Node
+
+	// The pending action of a two-key mark command.
+	pendingMark markAction
+
+	// A node to scroll to the top of the viewport once the document is
+	// rendered.
+	pendingTop ast.Node
+
+	// A snapshot to restore once the document is rendered.
+	pendingRestore *Snapshot
+
 	// The last width for which the content was rendered.
 	lastWidth int
 
@@ -379,19 +409,52 @@ type Model struct {
 	// Search state.
 	search searchState
 
+	// Table of contents sidebar state.
+	toc tocState
+
+	// Hint mode state.
+	hints hintState
+
+	// Annotation state.
+	annotations annotationState
+	decorations decorationState
+
+	// Links marked as broken by SetBrokenLinks.
+	brokenLinks map[ast.Node]bool
+
+	// Mouse state.
+	mouse mouseState
+
+	// If true, the copy menu is shown and the next key chooses a format.
+	copyMenu bool
+
+	// Writes copied text to the clipboard. If nil, tea.SetClipboard is used.
+	clipboard func(text string) tea.Cmd
+
+	// The event messages sent from Update.
+	events Events
+
 	// Document transformers to apply after parsing.
 	documentTransformers []DocumentTransformer
 
 	// Diagram renderer for converting diagram code blocks to text.
 	diagramRenderer renderer.DiagramRenderer
+
+	// The parser, parser extensions, and custom node renderers. docParser is
+	// the parser with the extensions added, built by SetText.
+	parser           *markdown.Parser
+	parserExtensions []goldmark.Extender
+	docParser        *markdown.Parser
+	nodeRenderers    map[ast.NodeKind]renderer.NodeRenderer
 }
 
 // effectiveWidth returns the width to use for rendering content.
 func (m *Model) effectiveWidth() int {
-	if m.contentWidth > 0 && m.contentWidth < m.width {
+	width := m.textWidth()
+	if m.contentWidth > 0 && m.contentWidth < width {
 		return m.contentWidth
 	}
-	return m.width
+	return width
 }
 
 // NewModel creates a new Model with the given options.
@@ -410,6 +473,20 @@ func NewModel(opts ...Option) Model {
 // state including selections, the navigation backstack, and the span tree.
 func (m *Model) Clear() {
 	m.lines = nil
+	m.renderedLines = nil
+	m.documentLines = nil
+	m.outputs = nil
+	m.lineMap = nil
+	m.folds = nil
+	m.foldRanges = nil
+	m.marks = nil
+	m.pendingMark = markNone
+	m.pendingTop = nil
+	m.pendingRestore = nil
+	m.copyMenu = false
+	m.annotations = annotationState{}
+	m.decorations = decorationState{}
+	m.brokenLinks = nil
 	m.markdown = nil
 	m.document = nil
 	m.spanTree = nil
@@ -422,10 +499,16 @@ func (m *Model) Clear() {
 	m.highlightSelection = false
 	m.lineOffset = 0
 	m.columnOffset = 0
+	m.mouse.pressed = false
 	m.cursorMode = false
 	m.visualMode = false
 	m.cursorPositioned = false
 	m.search = searchState{}
+	m.HideHints()
+	m.toc.collapsed = nil
+	m.toc.focused = false
+	m.toc.cursor = 0
+	m.toc.offset = 0
 }
 
 // GetName returns the document name.
@@ -438,16 +521,27 @@ func (m *Model) GetMarkdown() []byte {
 	return m.markdown
 }
 
+// documentParser returns the parser used by SetText: the configured parser
+// with the parser extensions added.
+func (m *Model) documentParser() *markdown.Parser {
+	if m.docParser == nil {
+		m.docParser = m.parser
+		if m.docParser == nil {
+			m.docParser = markdown.NewParser()
+		}
+		if len(m.parserExtensions) > 0 {
+			m.docParser = m.docParser.With(markdown.WithExtensions(m.parserExtensions...))
+		}
+	}
+	return m.docParser
+}
+
 // SetText sets the text of this view. Previously contained text will be removed.
 // If name is empty, the name is inferred from the first heading in the document.
 func (m *Model) SetText(name, markdown string) {
 	m.Clear()
 	m.markdown = []byte(markdown)
-	parser := goldmark.DefaultParser()
-	parser.AddOptions(goldmark_parser.WithParagraphTransformers(
-		util.Prioritized(extension.NewTableParagraphTransformer(), 200),
-	))
-	m.document = parser.Parse(text.NewReader(m.markdown))
+	m.document = m.documentParser().Parse(m.markdown)
 	for _, t := range m.documentTransformers {
 		t(m.document, m.markdown)
 	}
@@ -478,6 +572,23 @@ func (m *Model) SetContentWidth(width int) {
 	m.search.stale = true
 }
 
+// Theme returns the color theme used for rendering.
+func (m *Model) Theme() *chroma.Style {
+	return m.theme
+}
+
+// SetTheme sets the color theme used for rendering. The document is re-rendered
+// with the new theme; the scroll position is preserved.
+func (m *Model) SetTheme(theme *chroma.Style) {
+	if m.theme == theme {
+		return
+	}
+	m.theme = theme
+	m.lines = nil
+	m.search.stale = true
+	m.ensureRendered()
+}
+
 // SetGutter sets whether to show the gutter with document name and position.
 func (m *Model) SetGutter(showGutter bool) {
 	m.showGutter = showGutter
@@ -530,6 +641,9 @@ func (m *Model) render(width int) {
 	if m.diagramRenderer != nil {
 		opts = append(opts, renderer.WithDiagramRenderer(m.diagramRenderer))
 	}
+	for kind, render := range m.nodeRenderers {
+		opts = append(opts, renderer.WithNodeRenderer(kind, render))
+	}
 	r := renderer.New(opts...)
 
 	w := lineWriter{}
@@ -542,15 +656,32 @@ func (m *Model) render(width int) {
 		w.flushLine()
 	}
 
-	m.spanTree, m.lines, m.longestLine = r.SpanTree(), w.lines, w.longestLine
-	if m.lines == nil {
-		m.lines = []line{}
+	m.spanTree, m.documentLines, m.longestLine = r.SpanTree(), w.lines, w.longestLine
+	if m.documentLines == nil {
+		m.documentLines = []line{}
+	}
+	m.renderedLines = m.placeOutputs(m.documentLines)
+	m.applyFolds()
+	m.placeAnnotations()
+	m.placeDecorations()
+
+	// Hint labels refer to the previous rendering.
+	m.HideHints()
+
+	if n := m.pendingTop; n != nil {
+		m.pendingTop = nil
+		m.gotoNode(n)
 	}
 
 	// Re-execute search after re-render if stale.
 	if m.search.stale && m.search.query != "" {
 		m.executeSearch()
 	}
+
+	if s := m.pendingRestore; s != nil {
+		m.pendingRestore = nil
+		m.restore(*s)
+	}
 }
 
 // Init implements tea.Model.
@@ -563,10 +694,20 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		m.ensureRendered()
+		before := m.eventState()
 		cmd := m.handleKey(msg)
 		m.ensureRendered()
 		m.clampOffsets()
-		return m, cmd
+		return m, m.eventCmd(before, cmd)
+	case tea.MouseMsg:
+		if !m.mouse.enabled {
+			break
+		}
+		m.ensureRendered()
+		before := m.eventState()
+		cmd := m.handleMouse(msg)
+		m.clampOffsets()
+		return m, m.eventCmd(before, cmd)
 	}
 	m.clampOffsets()
 	return m, nil
@@ -665,7 +806,7 @@ func (m *Model) EffectiveWidth() int {
 // DecreaseContentWidth reduces the content width by 10 columns (minimum 40).
 func (m *Model) DecreaseContentWidth() {
 	if m.contentWidth == 0 {
-		m.contentWidth = m.width - 10
+		m.contentWidth = m.textWidth() - 10
 	} else {
 		m.contentWidth -= 10
 	}
@@ -681,7 +822,7 @@ func (m *Model) DecreaseContentWidth() {
 func (m *Model) IncreaseContentWidth() {
 	if m.contentWidth > 0 {
 		m.contentWidth += 10
-		if m.contentWidth+10 >= m.width {
+		if m.contentWidth+10 >= m.textWidth() {
 			m.contentWidth = 0
 		}
 	}
@@ -804,6 +945,9 @@ func (m *Model) ensureRendered() {
 // open the link in a browser or otherwise.
 type OpenLinkMsg struct {
 	URL string
+	// NewTab is true if the user asked to open the link in a new tab or
+	// window.
+	NewTab bool
 }
 
 // GoBackMsg is sent when the user presses the back key and the internal
@@ -816,6 +960,36 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) tea.Cmd {
 		return m.handleSearchKey(msg)
 	}
 
+	// Handle hint mode keys.
+	if m.hints.active {
+		return m.handleHintKey(msg)
+	}
+
+	// Complete a pending mark command.
+	if m.pendingMark != markNone {
+		return m.handleMarkKey(msg)
+	}
+
+	// Choose a format from the copy menu.
+	if m.copyMenu {
+		return m.handleCopyMenuKey(msg)
+	}
+
+	// Edit the note of an annotation.
+	if m.annotations.editing {
+		return m.handleAnnotationKey(msg)
+	}
+
+	// Handle table of contents keys.
+	if m.toc.focused && m.tocWidth() > 0 {
+		cmd, handled := m.handleTOCKey(msg)
+		if handled {
+			return cmd
+		}
+		// Unhandled keys return focus to the document and fall through.
+		m.toc.focused = false
+	}
+
 	// Handle visual mode keys.
 	if m.visualMode {
 		cmd, handled := m.handleVisualKey(msg)
@@ -864,6 +1038,81 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) tea.Cmd {
 		m.search = searchState{active: true}
 		return nil
 
+	case key.Matches(msg, m.KeyMap.ToggleTOC):
+		if m.toc.visible {
+			m.SetTOC(false)
+		} else {
+			m.FocusTOC(true)
+		}
+		return nil
+
+	case key.Matches(msg, m.KeyMap.FocusTOC):
+		m.FocusTOC(true)
+		return nil
+
+	case key.Matches(msg, m.KeyMap.ToggleFold):
+		if m.toggleFocusedOutput() {
+			return nil
+		}
+		if s := m.foldTarget(); s != nil {
+			m.SetFolded(s, !m.Folded(s))
+		}
+		return nil
+	case key.Matches(msg, m.KeyMap.Fold):
+		m.SetFolded(m.foldTarget(), true)
+		return nil
+	case key.Matches(msg, m.KeyMap.Unfold):
+		m.SetFolded(m.foldTarget(), false)
+		return nil
+	case key.Matches(msg, m.KeyMap.ToggleAllFolds):
+		if len(m.folds) > 0 {
+			m.UnfoldAll()
+		} else {
+			m.FoldAll()
+		}
+		return nil
+	case key.Matches(msg, m.KeyMap.FoldLevel):
+		if k := msg.String(); len(k) == 1 && k[0] >= '0' && k[0] <= '9' {
+			m.SetFoldLevel(int(k[0] - '0'))
+		}
+		return nil
+
+	case key.Matches(msg, m.KeyMap.Hint):
+		m.ShowHints()
+		return nil
+
+	case key.Matches(msg, m.KeyMap.CopyAs):
+		m.ShowCopyMenu()
+		return nil
+
+	case key.Matches(msg, m.KeyMap.Annotate):
+		m.Annotate()
+		return nil
+	case key.Matches(msg, m.KeyMap.NextAnnotation):
+		m.NextAnnotation()
+		return nil
+	case key.Matches(msg, m.KeyMap.PrevAnnotation):
+		m.PrevAnnotation()
+		return nil
+	case key.Matches(msg, m.KeyMap.NextDecoration):
+		m.NextDecoration()
+		return nil
+	case key.Matches(msg, m.KeyMap.PrevDecoration):
+		m.PrevDecoration()
+		return nil
+	case key.Matches(msg, m.KeyMap.DeleteAnnotation):
+		if m.DeleteAnnotation() {
+			return func() tea.Msg { return AnnotationsChangedMsg{} }
+		}
+		return nil
+
+	case key.Matches(msg, m.KeyMap.SetMark):
+		m.pendingMark = markSet
+		return nil
+	case key.Matches(msg, m.KeyMap.JumpToMark):
+		m.pendingMark = markJump
+		return nil
+
 	case key.Matches(msg, m.KeyMap.GotoTop):
 		m.GotoTop()
 	case key.Matches(msg, m.KeyMap.GotoEnd):
@@ -920,7 +1169,7 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) tea.Cmd {
 		}
 	case key.Matches(msg, m.KeyMap.CopySelection):
 		if content := m.focusedContent(); content != "" {
-			return tea.SetClipboard(content)
+			return m.copy(content)
 		}
 	}
 	return nil
@@ -928,7 +1177,7 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) tea.Cmd {
 
 // View implements tea.Model.
 func (m Model) View() string {
-	width := m.width
+	width := m.textWidth()
 	height := m.height
 	if width == 0 || height == 0 {
 		return ""
@@ -983,6 +1232,12 @@ func (m Model) View() string {
 	}
 	rightMarginPad := strings.Repeat(" ", rightMargin)
 
+	// Render the table of contents sidebar, if shown.
+	var sidebar []string
+	if m.tocWidth() > 0 {
+		sidebar = m.renderTOC(textHeight)
+	}
+
 	// Compute theme background SGR sequence for the content area.
 	// Content lines carry their own background via ansiPrefix, but
 	// short lines need padding within the effective width to fill
@@ -994,6 +1249,17 @@ func (m Model) View() string {
 		}
 	}
 
+	// Blank sign column, for lines without a sign.
+	var blankSign string
+	if m.signWidth() > 0 {
+		blankSign = strings.Repeat(" ", signColumnWidth)
+	}
+
+	var broken [][2]int
+	if len(m.brokenLinks) > 0 {
+		broken = m.brokenLinkRanges()
+	}
+
 	for i, ln := range m.lines[lineOffset:lastLine] {
 		if i > 0 {
 			buf.WriteByte('\n')
@@ -1001,6 +1267,20 @@ func (m Model) View() string {
 
 		content := expandTabs(ln.content, 8)
 
+		// Annotations are drawn last, at the columns they cover before any
+		// other highlighting is applied.
+		annotated := m.annotationColumns(ln, content)
+		decorated := m.decorationColumns(ln, content)
+		var labels []hintLabel
+		if m.hints.active {
+			labels = m.hintColumns(ln, content)
+		}
+
+		// Mark broken links.
+		if len(broken) > 0 {
+			content = m.applyBrokenLinks(ln, content, broken)
+		}
+
 		// Apply selection highlighting if needed.
 		if m.selection != nil && m.highlightSelection {
 			content = m.applySelection(ln, content)
@@ -1016,11 +1296,26 @@ func (m Model) View() string {
 			content = m.applyCursorHighlight(content)
 		}
 
+		// Apply decorations beneath the search highlighting.
+		if len(decorated) > 0 {
+			content = m.applyDecorations(content, decorated)
+		}
+
 		// Apply search match highlighting.
 		if len(m.search.matches) > 0 {
 			content = m.applySearchHighlights(lineOffset+i, content)
 		}
 
+		// Draw hint labels.
+		if len(labels) > 0 {
+			content = m.applyHints(content, labels)
+		}
+
+		// Apply annotation highlighting.
+		if len(annotated) > 0 {
+			content = m.applyAnnotations(content, annotated)
+		}
+
 		// Handle horizontal scrolling and width truncation.
 		if columnOffset > 0 {
 			content = ansiCut(content, columnOffset, columnOffset+ew)
@@ -1030,6 +1325,13 @@ func (m Model) View() string {
 
 		lineWidth := ansi.StringWidth(content)
 
+		if sidebar != nil {
+			buf.WriteString(sidebar[i])
+		}
+		if blankSign != "" {
+			buf.WriteString(m.renderSign(ln))
+		}
+
 		// Left margin for centering (unstyled).
 		buf.WriteString(leftPad)
 
@@ -1064,6 +1366,10 @@ func (m Model) View() string {
 		if i > 0 || lastLine > lineOffset {
 			buf.WriteByte('\n')
 		}
+		if sidebar != nil {
+			buf.WriteString(sidebar[i])
+		}
+		buf.WriteString(blankSign)
 		buf.WriteString(leftPad)
 		buf.WriteString(bgSeq)
 		buf.WriteString(strings.Repeat(" ", ew))
@@ -1077,9 +1383,9 @@ func (m Model) View() string {
 	if m.showGutter && m.theme != nil {
 		buf.WriteByte('\n')
 		if m.search.active {
-			buf.WriteString(m.renderSearchGutter(width))
+			buf.WriteString(m.renderSearchGutter(m.width))
 		} else {
-			buf.WriteString(m.renderGutter(width, lineOffset, lastLine))
+			buf.WriteString(m.renderGutter(m.width, lineOffset, lastLine))
 		}
 	}
 
@@ -1142,9 +1448,12 @@ func (m *Model) headingBreadcrumbs(lineOffset int) []string {
 	if m.spanTree == nil || len(m.lines) == 0 || lineOffset >= len(m.lines) {
 		return nil
 	}
+	return m.breadcrumbsAt(m.lines[lineOffset].start)
+}
 
-	topOffset := m.lines[lineOffset].start
-
+// breadcrumbsAt returns the heading hierarchy at the given rendered byte
+// offset.
+func (m *Model) breadcrumbsAt(topOffset int) []string {
 	type heading struct {
 		level int
 		text  string
@@ -1198,10 +1507,29 @@ func (m *Model) renderGutter(width, lineOffset, lastLine int) string {
 	var name string
 	var nameVisWidth int
 
-	if m.visualMode {
+	if m.annotations.editing {
+		// Show the note prompt, keeping the end of long notes in view.
+		name = "note: " + m.annotations.input + "_"
+		if w := ansi.StringWidth(name); w > nameWidth {
+			name = ansi.TruncateLeft(name, w-nameWidth, "")
+		}
+		nameVisWidth = ansi.StringWidth(name)
+	} else if m.copyMenu {
+		// Show the copy menu.
+		name = m.copyMenuPrompt()
+		nameVisWidth = ansi.StringWidth(name)
+	} else if m.hints.active {
+		// Show hint mode indicator.
+		name = "-- HINT --"
+		nameVisWidth = ansi.StringWidth(name)
+	} else if m.visualMode {
 		// Show visual mode indicator.
 		name = "-- VISUAL --"
 		nameVisWidth = ansi.StringWidth(name)
+	} else if m.toc.focused {
+		// Show table of contents focus indicator.
+		name = "-- CONTENTS --"
+		nameVisWidth = ansi.StringWidth(name)
 	} else if m.cursorMode {
 		// Show cursor mode indicator.
 		name = "-- CURSOR --"
@@ -1394,7 +1722,7 @@ func (m *Model) SelectFirstVisible(selector Selector) bool {
 		if s.Start >= vpEnd {
 			break
 		}
-		if s.Start >= vpStart {
+		if s.Start >= vpStart && !m.hiddenOffset(s.Start) {
 			if highlight, ok := selector(s.Node); ok {
 				m.SelectSpan(s, highlight)
 				return true
@@ -1422,7 +1750,7 @@ func (m *Model) SelectLastVisible(selector Selector) bool {
 		if s.Start >= vpEnd {
 			break
 		}
-		if s.Start >= vpStart {
+		if s.Start >= vpStart && !m.hiddenOffset(s.Start) {
 			if highlight, ok := selector(s.Node); ok {
 				last = s
 				lastHighlight = highlight
@@ -1451,6 +1779,9 @@ func (m *Model) SelectPrevious(selector Selector) bool {
 	}
 
 	for ; cursor != nil; cursor = cursor.Prev {
+		if m.hiddenOffset(cursor.Start) {
+			continue
+		}
 		if highlight, ok := selector(cursor.Node); ok {
 			m.SelectSpan(cursor, highlight)
 			return true
@@ -1475,6 +1806,9 @@ func (m *Model) SelectNext(selector Selector) bool {
 	}
 
 	for ; cursor != nil; cursor = cursor.Next {
+		if m.hiddenOffset(cursor.Start) {
+			continue
+		}
 		if highlight, ok := selector(cursor.Node); ok {
 			m.SelectSpan(cursor, highlight)
 			return true
@@ -1494,6 +1828,9 @@ func (m *Model) SelectAnchor(anchor string) bool {
 
 	// Prefer navigating to the HTML anchor node if one exists.
 	if nodes, ok := m.index.LookupNode(anchor); ok {
+		for _, n := range nodes {
+			m.revealNode(n)
+		}
 		selector := func(node ast.Node) (bool, bool) {
 			for _, n := range nodes {
 				if n == node {
@@ -1514,6 +1851,9 @@ func (m *Model) SelectAnchor(anchor string) bool {
 	if !ok {
 		return false
 	}
+	for _, s := range sections {
+		m.revealNode(s.Start)
+	}
 	selector := func(node ast.Node) (bool, bool) {
 		for _, s := range sections {
 			if s.Start == node {
@@ -1588,6 +1928,7 @@ func (m *Model) GoBack() bool {
 
 // SelectSpan selects the given node span.
 func (m *Model) SelectSpan(span *renderer.NodeSpan, highlight bool) {
+	m.revealOffset(span.Start)
 	m.highlightSelection = highlight
 	m.selection = span
 	m.calculateSelectionSpan(span)
@@ -2224,7 +2565,18 @@ func (m *Model) handleVisualKey(msg tea.KeyPressMsg) (tea.Cmd, bool) {
 		m.exitVisualMode()
 		if content != "" {
 			m.statusMessage = "yanked"
-			return tea.SetClipboard(content), true
+			return m.copy(content), true
+		}
+		return nil, true
+	case key.Matches(msg, m.KeyMap.CopyAs):
+		m.ShowCopyMenu()
+		return nil, true
+	case key.Matches(msg, m.KeyMap.Annotate):
+		m.Annotate()
+		return nil, true
+	case key.Matches(msg, m.KeyMap.DeleteAnnotation):
+		if m.DeleteAnnotation() {
+			return func() tea.Msg { return AnnotationsChangedMsg{} }, true
 		}
 		return nil, true
 	case key.Matches(msg, m.KeyMap.VisualMode): // v again exits
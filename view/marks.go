@@ -0,0 +1,218 @@
+package view
+
+import (
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// markAction is the pending action of a two-key mark command.
+type markAction int
+
+const (
+	markNone markAction = iota
+	markSet
+	markJump
+)
+
+// MarkSetMsg is sent when the user sets a mark. Lowercase marks are kept by
+// the Model and are reported so that embedders can persist them; uppercase
+// marks name positions across documents and are only reported, so embedders
+// must keep them.
+type MarkSetMsg struct {
+	Name rune
+	// Offset is the source byte offset of the marked position. It can be
+	// passed to GotoSourceOffset or SetMarkOffset.
+	Offset int
+}
+
+// JumpToMarkMsg is sent when the user jumps to an uppercase mark. Embedders
+// should handle this message to open the marked document and position.
+type JumpToMarkMsg struct {
+	Name rune
+}
+
+// MarkPending reports whether the Model is waiting for the name of a mark to
+// set or jump to. Embedders should forward the next key press to the Model.
+func (m *Model) MarkPending() bool {
+	return m.pendingMark != markNone
+}
+
+// SetMark sets the named mark to the position at the top of the viewport. The
+// mark refers to the document node shown there, so it survives re-wrapping.
+func (m *Model) SetMark(name rune) {
+	span := m.topSpan()
+	if span == nil {
+		return
+	}
+	if m.marks == nil {
+		m.marks = map[rune]ast.Node{}
+	}
+	m.marks[name] = span.Node
+}
+
+// JumpToMark scrolls the named mark to the top of the viewport. Returns false
+// if the mark is not set.
+func (m *Model) JumpToMark(name rune) bool {
+	n, ok := m.marks[name]
+	if !ok {
+		return false
+	}
+	return m.gotoNode(n)
+}
+
+// MarkOffsets returns the source byte offsets of the marks set in the current
+// document, keyed by mark name.
+func (m *Model) MarkOffsets() map[rune]int {
+	if len(m.marks) == 0 {
+		return nil
+	}
+	offsets := make(map[rune]int, len(m.marks))
+	for name, n := range m.marks {
+		if offset, ok := nodeSourceOffset(n); ok {
+			offsets[name] = offset
+		}
+	}
+	return offsets
+}
+
+// SetMarkOffset sets the named mark to the node at the given source byte
+// offset. It is used to restore marks saved from MarkOffsets or MarkSetMsg.
+func (m *Model) SetMarkOffset(name rune, offset int) {
+	n := m.nodeAtSourceOffset(offset)
+	if n == nil {
+		return
+	}
+	if m.marks == nil {
+		m.marks = map[rune]ast.Node{}
+	}
+	m.marks[name] = n
+}
+
+// SourceOffset returns the source byte offset of the text at the top of the
+// viewport: the start of the node shown there or, within a code block, of the
+// line shown there.
+func (m *Model) SourceOffset() int {
+	if m.spanTree == nil || m.lineOffset < 0 || m.lineOffset >= len(m.lines) {
+		return 0
+	}
+	if offset, ok := m.sourceOffsetAt(m.lines[m.lineOffset].start); ok {
+		return offset
+	}
+	return 0
+}
+
+// GotoSourceOffset scrolls the node at the given source byte offset to the top
+// of the viewport. It may be called before the document is first rendered.
+// Returns false if the document has no such node.
+func (m *Model) GotoSourceOffset(offset int) bool {
+	n := m.nodeAtSourceOffset(offset)
+	if n == nil {
+		return false
+	}
+	return m.gotoNode(n)
+}
+
+// topSpan returns the span of the innermost block at the top of the viewport,
+// or of the first block below it if the top line is between blocks.
+func (m *Model) topSpan() *renderer.NodeSpan {
+	if m.spanTree == nil || m.lineOffset < 0 || m.lineOffset >= len(m.lines) {
+		return nil
+	}
+	return m.blockSpanAt(m.lines[m.lineOffset].start)
+}
+
+// gotoNode scrolls the given node's span to the top of the viewport, unfolding
+// any section that hides it. If the document has not been rendered yet, the
+// node is scrolled to once it is.
+func (m *Model) gotoNode(n ast.Node) bool {
+	if m.spanTree == nil {
+		m.pendingTop = n
+		return true
+	}
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node == n {
+			m.revealOffset(s.Start)
+			m.scrollToOffset(s.Start)
+			m.clampOffsets()
+			return true
+		}
+	}
+	return false
+}
+
+// nodeAtSourceOffset returns the innermost block node that starts at or
+// before the given source byte offset, or the first block if the offset
+// precedes every block.
+func (m *Model) nodeAtSourceOffset(offset int) ast.Node {
+	if m.document == nil {
+		return nil
+	}
+	var found ast.Node
+	ast.Walk(m.document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock || n.Kind() == ast.KindDocument {
+			return ast.WalkContinue, nil
+		}
+		start, ok := nodeSourceOffset(n)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if start > offset {
+			if found == nil {
+				found = n
+			}
+			return ast.WalkStop, nil
+		}
+		found = n
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// nodeSourceOffset returns the source byte offset at which the given node's
+// content begins.
+func nodeSourceOffset(n ast.Node) (int, bool) {
+	for ; n != nil; n = n.FirstChild() {
+		if n.Type() == ast.TypeBlock {
+			if lines := n.Lines(); lines.Len() > 0 {
+				return lines.At(0).Start, true
+			}
+		} else if t, ok := n.(*ast.Text); ok {
+			return t.Segment.Start, true
+		}
+	}
+	return 0, false
+}
+
+// handleMarkKey completes a two-key mark command with the mark name.
+func (m *Model) handleMarkKey(msg tea.KeyPressMsg) tea.Cmd {
+	action := m.pendingMark
+	m.pendingMark = markNone
+
+	runes := []rune(msg.Text)
+	if len(runes) != 1 || runes[0] > unicode.MaxASCII || !unicode.IsLetter(runes[0]) {
+		// Any other key cancels the command.
+		return nil
+	}
+	name := runes[0]
+
+	switch action {
+	case markSet:
+		if m.topSpan() == nil {
+			return nil
+		}
+		if unicode.IsLower(name) {
+			m.SetMark(name)
+		}
+		offset := m.SourceOffset()
+		return func() tea.Msg { return MarkSetMsg{Name: name, Offset: offset} }
+	case markJump:
+		if unicode.IsUpper(name) {
+			return func() tea.Msg { return JumpToMarkMsg{Name: name} }
+		}
+		m.JumpToMark(name)
+	}
+	return nil
+}
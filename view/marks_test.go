@@ -0,0 +1,134 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMarksModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel()
+	m.SetText("test.md", tocDoc())
+	m.SetSize(80, 10)
+	return m
+}
+
+func topLine(m Model) string {
+	return strings.TrimSpace(ansi.Strip(m.lines[m.lineOffset].content))
+}
+
+func scrollToAnchor(t *testing.T, m *Model, anchor string) {
+	t.Helper()
+	require.True(t, m.SelectAnchor(anchor))
+	m.scrollToOffset(m.selection.Start)
+}
+
+func TestMarks_SetAndJump(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "install")
+	m.lineOffset += 2
+	marked := topLine(m)
+
+	m, cmd := m.Update(keyMsg('m'))
+	assert.Nil(t, cmd)
+	m, cmd = m.Update(keyMsg('a'))
+	require.NotNil(t, cmd)
+	msg, ok := cmd().(MarkSetMsg)
+	require.True(t, ok)
+	assert.Equal(t, 'a', msg.Name)
+
+	m.GotoTop()
+	m, _ = m.Update(keyMsg('\''))
+	m, _ = m.Update(keyMsg('a'))
+	assert.Equal(t, marked, topLine(m))
+
+	m.GotoTop()
+	m, _ = m.Update(keyMsg('`'))
+	m, _ = m.Update(keyMsg('a'))
+	assert.Equal(t, marked, topLine(m))
+}
+
+func TestMarks_SurviveRewrap(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "usage")
+	m.SetMark('a')
+	marked := topLine(m)
+
+	m.SetSize(40, 10)
+	m.GotoTop()
+	require.True(t, m.JumpToMark('a'))
+	assert.Equal(t, marked, topLine(m))
+}
+
+func TestMarks_UnsetAndCancelled(t *testing.T) {
+	m := newMarksModel(t)
+	assert.False(t, m.JumpToMark('q'))
+
+	// A non-letter cancels the command without acting on the key.
+	m, _ = m.Update(keyMsg('m'))
+	m, cmd := m.Update(keyMsg('1'))
+	assert.Nil(t, cmd)
+	assert.Empty(t, m.MarkOffsets())
+
+	m, _ = m.Update(keyMsg('j'))
+	assert.Equal(t, 1, m.LineOffset())
+}
+
+func TestMarks_UppercaseReported(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "setup")
+
+	m, _ = m.Update(keyMsg('m'))
+	m, cmd := m.Update(keyMsg('A'))
+	require.NotNil(t, cmd)
+	set, ok := cmd().(MarkSetMsg)
+	require.True(t, ok)
+	assert.Equal(t, 'A', set.Name)
+	assert.Equal(t, strings.Index(tocDoc(), "Setup"), set.Offset)
+	assert.Empty(t, m.MarkOffsets(), "uppercase marks are kept by the embedder")
+
+	m, _ = m.Update(keyMsg('\''))
+	m, cmd = m.Update(keyMsg('A'))
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Msg(JumpToMarkMsg{Name: 'A'}), cmd())
+}
+
+func TestMarks_RestoreFromOffsets(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "reference")
+	m.SetMark('r')
+	marked := topLine(m)
+	offsets := m.MarkOffsets()
+	require.Contains(t, offsets, 'r')
+
+	// Marks can be restored before the document is rendered.
+	n := NewModel()
+	n.SetText("test.md", tocDoc())
+	n.SetMarkOffset('r', offsets['r'])
+	n.SetSize(80, 10)
+	require.True(t, n.JumpToMark('r'))
+	assert.Equal(t, marked, topLine(n))
+
+	n.GotoTop()
+	require.True(t, n.GotoSourceOffset(offsets['r']))
+	assert.Equal(t, marked, topLine(n))
+	assert.Equal(t, offsets['r'], n.SourceOffset())
+}
+
+func TestMarks_JumpRevealsFold(t *testing.T) {
+	m := newFoldModel(t)
+	m.SetSize(80, 3)
+	require.True(t, m.SelectAnchor("install"))
+	m.scrollToOffset(m.selection.Start)
+	m.SetMark('a')
+
+	m.FoldAll()
+	require.True(t, m.JumpToMark('a'))
+	assert.Equal(t, "### Install (2 lines)", topLine(m), "only the folds hiding the mark open")
+	assert.False(t, m.Folded(foldSection(t, &m, "setup")))
+}
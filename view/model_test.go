@@ -1337,3 +1337,26 @@ func TestCursorMode_GutterIndicator(t *testing.T) {
 	lastLine := ansi.Strip(lines[len(lines)-1])
 	assert.Contains(t, lastLine, "-- CURSOR --", "gutter should show cursor mode indicator")
 }
+
+// ---------------------------------------------------------------------------
+// SetTheme
+// ---------------------------------------------------------------------------
+
+func TestSetTheme_Rerenders(t *testing.T) {
+	doc := "# Hello\n\n" + strings.Repeat("Some text.\n\n", 40)
+	m := NewModel(WithTheme(styles.Pulumi))
+	m.SetText("test.md", doc)
+	m.SetSize(80, 10)
+	m.SetLineOffset(5)
+	before := m.View()
+
+	m.SetTheme(styles.GlamourLight)
+	assert.Equal(t, styles.GlamourLight, m.Theme())
+	after := m.View()
+	assert.NotEqual(t, before, after, "view should reflect the new theme")
+	assert.Equal(t, ansi.Strip(before), ansi.Strip(after), "text should be unchanged")
+	assert.Equal(t, 5, m.LineOffset(), "scroll position should be preserved")
+
+	m.SetTheme(nil)
+	assert.NotContains(t, m.View(), "\033[38;", "nil theme should render without color")
+}
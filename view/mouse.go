@@ -0,0 +1,165 @@
+package view
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// doubleClickInterval is the longest time between two clicks on the same cell
+// that counts as a double click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// wheelLines is the number of lines or columns scrolled per wheel step.
+const wheelLines = 3
+
+type mouseState struct {
+	enabled bool
+
+	// The last click, for detecting double clicks.
+	lastClick        time.Time
+	lastX, lastY     int
+	pressed          bool // the left button is down
+	pressLine        int  // position of the press in m.lines
+	pressCol         int
+	dragging         bool // the pointer has moved since the press
+	doubleClickArmed bool
+}
+
+// cellAt returns the line index and visible column of the document text at the
+// given position relative to the Model's top-left corner. Returns false if the
+// position is outside the text area.
+func (m *Model) cellAt(x, y int) (li, col int, ok bool) {
+	if y < 0 || y >= m.pageSize || x < 0 || x >= m.width {
+		return 0, 0, false
+	}
+	x -= m.tocWidth() + m.signWidth()
+	if ew := m.effectiveWidth(); ew < m.textWidth() {
+		x -= (m.textWidth() - ew) / 2
+	}
+	li = m.lineOffset + y
+	if x < 0 || li >= len(m.lines) {
+		return 0, 0, false
+	}
+	return li, x + m.columnOffset, true
+}
+
+// offsetAtColumn returns the rendered byte offset of the character shown at
+// the given visible column of a line.
+func (m *Model) offsetAtColumn(li, col int) int {
+	ln := m.lines[li]
+	content := ln.content
+
+	var state byte
+	width := 0
+	for i := 0; i < len(content); {
+		_, w, n, newState := ansi.DecodeSequence(content[i:], state, nil)
+		if w > 0 && width+w > col {
+			return ln.start + i
+		}
+		width += w
+		state = newState
+		i += n
+	}
+	return ln.end
+}
+
+// linkAt returns the span of the innermost link that contains the given
+// rendered byte offset.
+func (m *Model) linkAt(offset int) *renderer.NodeSpan {
+	var found *renderer.NodeSpan
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Start > offset {
+			break
+		}
+		if _, ok := isLink(s.Node); ok && s.Contains(offset) {
+			found = s
+		}
+	}
+	return found
+}
+
+// handleMouse handles a mouse event. Positions are relative to the Model's
+// top-left corner.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	mouse := msg.Mouse()
+
+	switch msg.(type) {
+	case tea.MouseWheelMsg:
+		switch mouse.Button {
+		case tea.MouseWheelUp:
+			m.ScrollUp(wheelLines)
+		case tea.MouseWheelDown:
+			m.ScrollDown(wheelLines)
+		case tea.MouseWheelLeft:
+			m.ScrollLeft(wheelLines)
+		case tea.MouseWheelRight:
+			m.ScrollRight(wheelLines)
+		}
+		return nil
+
+	case tea.MouseClickMsg:
+		if mouse.Button != tea.MouseLeft {
+			return nil
+		}
+		li, col, ok := m.cellAt(mouse.X, mouse.Y)
+		if !ok {
+			return nil
+		}
+
+		double := m.mouse.doubleClickArmed && mouse.X == m.mouse.lastX && mouse.Y == m.mouse.lastY &&
+			time.Since(m.mouse.lastClick) <= doubleClickInterval
+		m.mouse.lastClick, m.mouse.lastX, m.mouse.lastY = time.Now(), mouse.X, mouse.Y
+		m.mouse.doubleClickArmed = !double
+		m.mouse.pressed, m.mouse.dragging = true, false
+		m.mouse.pressLine, m.mouse.pressCol = li, col
+
+		m.exitCursorMode()
+		m.exitVisualMode()
+
+		link := m.linkAt(m.offsetAtColumn(li, col))
+		if link == nil {
+			m.selection = nil
+			m.highlightSelection = false
+			return nil
+		}
+		m.SelectSpan(link, true)
+		if double {
+			if url := m.FocusedLinkDestination(); url != "" && !m.FollowLink() {
+				return func() tea.Msg { return OpenLinkMsg{URL: url} }
+			}
+		}
+		return nil
+
+	case tea.MouseMotionMsg:
+		if !m.mouse.pressed {
+			return nil
+		}
+		li, col, ok := m.cellAt(mouse.X, mouse.Y)
+		if !ok {
+			return nil
+		}
+		if !m.mouse.dragging {
+			if li == m.mouse.pressLine && col == m.mouse.pressCol {
+				return nil
+			}
+			// Start a visual selection at the press.
+			m.mouse.dragging = true
+			m.mouse.doubleClickArmed = false
+			m.cursorLine, m.cursorCol = m.mouse.pressLine, m.mouse.pressCol
+			m.clampCursorCol()
+			m.cursorPositioned = true
+			m.enterVisualMode()
+		}
+		m.cursorLine, m.cursorCol = li, col
+		m.clampCursorCol()
+		return nil
+
+	case tea.MouseReleaseMsg:
+		m.mouse.pressed = false
+		return nil
+	}
+	return nil
+}
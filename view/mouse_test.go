@@ -0,0 +1,103 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMouseModel(t *testing.T, doc string, opts ...Option) (Model, []string) {
+	t.Helper()
+	m := NewModel(append([]Option{WithMouse(true)}, opts...)...)
+	m.SetText("mouse.md", doc)
+	m.SetSize(80, 10)
+	return m, splitLines(ansi.Strip(m.View()))
+}
+
+// cellOf returns the screen position of the first occurrence of s.
+func cellOf(t *testing.T, lines []string, s string) (int, int) {
+	t.Helper()
+	for y, line := range lines {
+		if i := strings.Index(line, s); i >= 0 {
+			return ansi.StringWidth(line[:i]), y
+		}
+	}
+	t.Fatalf("%q not found", s)
+	return 0, 0
+}
+
+func click(x, y int) tea.MouseClickMsg {
+	return tea.MouseClickMsg{X: x, Y: y, Button: tea.MouseLeft}
+}
+
+func TestMouse_Disabled(t *testing.T) {
+	m := NewModel()
+	m.SetText("mouse.md", strings.Repeat("line\n\n", 40))
+	m.SetSize(80, 10)
+	m, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	assert.Equal(t, 0, m.LineOffset())
+}
+
+func TestMouse_Wheel(t *testing.T) {
+	m, _ := newMouseModel(t, strings.Repeat("line\n\n", 40))
+	m, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	assert.Equal(t, wheelLines, m.LineOffset())
+	m, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp})
+	assert.Equal(t, 0, m.LineOffset())
+}
+
+func TestMouse_ClickFocusesLink(t *testing.T) {
+	m, lines := newMouseModel(t, "See [one](https://one.example) and [two](https://two.example).\n")
+	x, y := cellOf(t, lines, "two")
+
+	m, cmd := m.Update(click(x+1, y))
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, "https://two.example", m.FocusedLinkDestination())
+
+	// Clicking elsewhere clears the selection.
+	m, _ = m.Update(click(0, y))
+	assert.Nil(t, m.Selection())
+}
+
+func TestMouse_DoubleClickFollowsLink(t *testing.T) {
+	m, lines := newMouseModel(t, "See [one](https://one.example).\n")
+	x, y := cellOf(t, lines, "one")
+
+	m, _ = m.Update(click(x, y))
+	m, _ = m.Update(tea.MouseReleaseMsg{X: x, Y: y, Button: tea.MouseLeft})
+	m, cmd := m.Update(click(x, y))
+	require.NotNil(t, cmd)
+	assert.Equal(t, OpenLinkMsg{URL: "https://one.example"}, cmd())
+
+	// A third click starts over.
+	_, cmd = m.Update(click(x, y))
+	assert.Nil(t, cmd)
+}
+
+func TestMouse_DoubleClickInternalLink(t *testing.T) {
+	m, lines := newMouseModel(t, "Jump to [the end](#end).\n\n"+strings.Repeat("Filler.\n\n", 20)+"## End\n\nDone.\n")
+	x, y := cellOf(t, lines, "the end")
+
+	m, _ = m.Update(click(x, y))
+	m, cmd := m.Update(click(x, y))
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, ast.KindHeading, m.Selection().Node.Kind())
+}
+
+func TestMouse_DragSelects(t *testing.T) {
+	m, lines := newMouseModel(t, "Hello brave new world.\n")
+	x, y := cellOf(t, lines, "brave")
+
+	m, _ = m.Update(click(x, y))
+	m, _ = m.Update(tea.MouseMotionMsg{X: x + 8, Y: y, Button: tea.MouseLeft})
+	m, _ = m.Update(tea.MouseReleaseMsg{X: x + 8, Y: y, Button: tea.MouseLeft})
+	require.True(t, m.VisualMode())
+	assert.Equal(t, "brave new", m.yankVisualSelection())
+}
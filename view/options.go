@@ -1,8 +1,11 @@
 package view
 
 import (
+	tea "charm.land/bubbletea/v2"
 	"github.com/alecthomas/chroma"
+	"github.com/pgavlin/goldmark"
 	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/renderer"
 )
 
@@ -41,6 +44,41 @@ func WithGutter(showGutter bool) Option {
 	}
 }
 
+// WithTOC sets whether to show the table of contents sidebar.
+func WithTOC(show bool) Option {
+	return func(m *Model) {
+		m.toc.visible = show
+	}
+}
+
+// WithTOCWidth sets the width of the table of contents sidebar, including its
+// separator column. 0 uses the default width.
+func WithTOCWidth(width int) Option {
+	return func(m *Model) {
+		m.toc.width = width
+	}
+}
+
+// WithMouse sets whether the Model handles mouse events: the wheel scrolls,
+// clicking a link focuses it, double-clicking follows it, and dragging selects
+// text. Embedders must enable mouse reporting and translate event positions
+// so that they are relative to the Model's top-left corner. Capturing the
+// mouse disables the terminal's own text selection.
+func WithMouse(enabled bool) Option {
+	return func(m *Model) {
+		m.mouse.enabled = enabled
+	}
+}
+
+// WithClipboard sets the function that writes copied text to the clipboard.
+// By default, text is copied with tea.SetClipboard, which uses the terminal's
+// OSC 52 escape sequence.
+func WithClipboard(write func(text string) tea.Cmd) Option {
+	return func(m *Model) {
+		m.clipboard = write
+	}
+}
+
 // WithContentWidth sets the desired content width. 0 means use full viewport width.
 func WithContentWidth(width int) Option {
 	return func(m *Model) {
@@ -70,6 +108,35 @@ func WithDiagramRenderer(dr renderer.DiagramRenderer) Option {
 	}
 }
 
+// WithParser sets the parser used by SetText. By default, documents are
+// parsed with markdown.NewParser().
+func WithParser(p *markdown.Parser) Option {
+	return func(m *Model) {
+		m.parser = p
+	}
+}
+
+// WithParserExtension adds a goldmark extension to the parser used by SetText,
+// e.g. to parse custom block or inline syntax. Use WithNodeRenderer to render
+// the nodes that the extension adds; only the children of nodes without a
+// renderer are rendered.
+func WithParserExtension(ext goldmark.Extender) Option {
+	return func(m *Model) {
+		m.parserExtensions = append(m.parserExtensions, ext)
+	}
+}
+
+// WithNodeRenderer sets the function used to render nodes of the given kind,
+// e.g. the nodes added by a parser extension.
+func WithNodeRenderer(kind ast.NodeKind, render renderer.NodeRenderer) Option {
+	return func(m *Model) {
+		if m.nodeRenderers == nil {
+			m.nodeRenderers = map[ast.NodeKind]renderer.NodeRenderer{}
+		}
+		m.nodeRenderers[kind] = render
+	}
+}
+
 // WithDocumentTransformer adds a document transformer that will be applied
 // to the parsed AST before rendering.
 func WithDocumentTransformer(t DocumentTransformer) Option {
@@ -77,3 +144,19 @@ func WithDocumentTransformer(t DocumentTransformer) Option {
 		m.documentTransformers = append(m.documentTransformers, t)
 	}
 }
+
+// WithEvents sets the event messages that the Model sends to report changes
+// of its state, e.g. WithEvents(SelectionEvents|SearchEvents).
+func WithEvents(events Events) Option {
+	return func(m *Model) {
+		m.events = events
+	}
+}
+
+// WithHintSelectors sets the selectors that choose the nodes labeled in hint
+// mode. By default, only links are labeled.
+func WithHintSelectors(selectors ...Selector) Option {
+	return func(m *Model) {
+		m.hints.selectors = selectors
+	}
+}
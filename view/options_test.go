@@ -1,9 +1,12 @@
 package view
 
 import (
+	"strings"
 	"testing"
 
 	"charm.land/bubbles/v2/key"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/markdown-kit/markdown"
 	"github.com/pgavlin/markdown-kit/styles"
 	"github.com/stretchr/testify/assert"
 )
@@ -98,3 +101,19 @@ func TestOptionOrder_LastWins(t *testing.T) {
 	m := NewModel(WithContentWidth(80), WithContentWidth(120))
 	assert.Equal(t, 120, m.contentWidth)
 }
+
+func TestWithParser(t *testing.T) {
+	const doc = "---\ntitle: Notes\n---\n\n# Notes\n\nBody.\n"
+
+	m := NewModel()
+	m.SetText("notes.md", doc)
+	m.SetSize(80, 10)
+	assert.Contains(t, ansi.Strip(m.View()), "title: Notes")
+
+	m = NewModel(WithParser(markdown.NewParser(markdown.WithFrontMatter(true))))
+	m.SetText("notes.md", doc)
+	m.SetSize(80, 10)
+	view := ansi.Strip(m.View())
+	assert.NotContains(t, view, "title: Notes")
+	assert.True(t, strings.HasPrefix(strings.TrimLeft(view, " \n"), "# Notes"), "%q", view)
+}
@@ -0,0 +1,154 @@
+package view
+
+import (
+	"bytes"
+
+	"github.com/pgavlin/goldmark/ast"
+)
+
+// ReadingPosition returns the position at the top of the viewport in a form
+// that survives edits elsewhere in the document: the anchor of the current
+// section's heading, and the source byte offset of the top of the viewport
+// relative to the start of that heading. The anchor is empty if the viewport
+// is above the first heading, in which case the offset is relative to the
+// start of the document.
+func (m *Model) ReadingPosition() (anchor string, offset int) {
+	top := m.SourceOffset()
+	if s := m.CurrentSection(); s != nil {
+		if start, ok := nodeSourceOffset(s.Start); ok && start <= top {
+			return s.Anchor, top - start
+		}
+	}
+	return "", top
+}
+
+// SetReadingPosition scrolls to a position returned by ReadingPosition. An
+// offset past the end of the section is clamped to the section's last block.
+// It may be called before the document is first rendered. Returns false if
+// the document has no section with the given anchor.
+func (m *Model) SetReadingPosition(anchor string, offset int) bool {
+	target, ok := m.readingOffset(anchor, offset)
+	if !ok {
+		return false
+	}
+	return m.GotoSourceOffset(target)
+}
+
+// readingOffset returns the source byte offset of a position returned by
+// ReadingPosition.
+func (m *Model) readingOffset(anchor string, offset int) (int, bool) {
+	if anchor == "" {
+		return offset, true
+	}
+	if m.index == nil {
+		return 0, false
+	}
+	sections, ok := m.index.Lookup(anchor)
+	if !ok || len(sections) == 0 {
+		return 0, false
+	}
+	s := sections[0]
+	start, ok := nodeSourceOffset(s.Start)
+	if !ok {
+		return 0, false
+	}
+	target := start + max(offset, 0)
+	if s.End != nil {
+		if end, ok := nodeSourceOffset(s.End); ok && target >= end {
+			target = end - 1
+		}
+	}
+	return target, true
+}
+
+// ReplaceText sets the text of this view like SetText, but keeps the viewport
+// on the same content. The block at the top of the viewport is looked up in
+// the new document by its kind and source text, preferring the match nearest
+// to the old reading position; if the block is gone, the view scrolls to the
+// reading position itself. Sections that were folded stay folded, and the
+// horizontal scroll position is kept.
+func (m *Model) ReplaceText(name, markdown string) {
+	if m.document == nil {
+		m.SetText(name, markdown)
+		return
+	}
+
+	anchor, offset := m.ReadingPosition()
+	top := m.SourceOffset()
+	columnOffset := m.columnOffset
+	folded := m.foldedAnchors()
+
+	var kind ast.NodeKind
+	var block []byte
+	lineDelta := 0
+	if span := m.topSpan(); span != nil {
+		if start, end, ok := m.nodeSourceRange(span.Node); ok {
+			kind, block = span.Node.Kind(), bytes.Clone(m.markdown[start:end])
+			lineDelta = max(m.lineOffset-m.findLineForOffset(span.Start), 0)
+		}
+	}
+
+	m.SetText(name, markdown)
+	m.refoldAnchors(folded)
+
+	target, ok := m.readingOffset(anchor, offset)
+	if !ok {
+		target = top
+	}
+	if n := m.matchBlock(kind, block, target); n != nil && m.gotoNode(n) {
+		if m.pendingTop == nil {
+			m.lineOffset += lineDelta
+		}
+	} else {
+		m.GotoSourceOffset(min(target, len(m.markdown)))
+	}
+	m.columnOffset = columnOffset
+	m.clampOffsets()
+}
+
+// matchBlock returns the block of the given kind whose source text is block,
+// choosing the one that starts nearest to the given source byte offset.
+func (m *Model) matchBlock(kind ast.NodeKind, block []byte, offset int) ast.Node {
+	if m.document == nil || len(block) == 0 {
+		return nil
+	}
+	var found ast.Node
+	distance := 0
+	ast.Walk(m.document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock || n.Kind() != kind {
+			return ast.WalkContinue, nil
+		}
+		start, end, ok := m.nodeSourceRange(n)
+		if !ok || !bytes.Equal(m.markdown[start:end], block) {
+			return ast.WalkContinue, nil
+		}
+		if d := max(start-offset, offset-start); found == nil || d < distance {
+			found, distance = n, d
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// foldedAnchors returns the anchors of the folded sections.
+func (m *Model) foldedAnchors() []string {
+	var anchors []string
+	for s := range m.folds {
+		if s.Anchor != "" {
+			anchors = append(anchors, s.Anchor)
+		}
+	}
+	return anchors
+}
+
+// refoldAnchors folds the sections with the given anchors.
+func (m *Model) refoldAnchors(anchors []string) {
+	if m.index == nil {
+		return
+	}
+	for _, anchor := range anchors {
+		if sections, ok := m.index.Lookup(anchor); ok && len(sections) > 0 {
+			m.SetFolded(sections[0], true)
+		}
+	}
+}
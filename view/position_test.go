@@ -0,0 +1,105 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadingPosition_RoundTrip(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "setup")
+	m.ScrollDown(4)
+	marked := topLine(m)
+
+	anchor, offset := m.ReadingPosition()
+	assert.Equal(t, "setup", anchor)
+	assert.Greater(t, offset, 0)
+
+	// The position can be restored before the document is rendered.
+	n := NewModel()
+	n.SetText("test.md", tocDoc())
+	require.True(t, n.SetReadingPosition(anchor, offset))
+	n.SetSize(80, 10)
+	assert.Equal(t, marked, topLine(n))
+}
+
+func TestReadingPosition_AboveFirstHeading(t *testing.T) {
+	m := NewModel()
+	m.SetText("test.md", "Preface.\n\n"+tocDoc())
+	m.SetSize(80, 10)
+
+	anchor, offset := m.ReadingPosition()
+	assert.Equal(t, "", anchor)
+	assert.Equal(t, 0, offset)
+
+	m.ScrollDown(4)
+	require.True(t, m.SetReadingPosition("", 0))
+	assert.Equal(t, "Preface.", topLine(m))
+}
+
+func TestReadingPosition_SurvivesEdits(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "usage")
+	m.ScrollDown(2)
+	marked := topLine(m)
+	anchor, offset := m.ReadingPosition()
+
+	// Text added before the section does not move the position.
+	edited := strings.Replace(tocDoc(), "# Intro\n\n", "# Intro\n\nA new paragraph.\n\nAnother one.\n\n", 1)
+	n := NewModel()
+	n.SetText("test.md", edited)
+	n.SetSize(80, 10)
+	require.True(t, n.SetReadingPosition(anchor, offset))
+	assert.Equal(t, marked, topLine(n))
+	assert.Equal(t, "usage", n.CurrentSection().Anchor)
+}
+
+func TestReadingPosition_ClampsToSection(t *testing.T) {
+	m := newMarksModel(t)
+	require.True(t, m.SetReadingPosition("install", 1<<20))
+	assert.Equal(t, "install", m.CurrentSection().Anchor)
+
+	assert.False(t, m.SetReadingPosition("missing", 0))
+}
+
+func TestReplaceText_KeepsContent(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "usage")
+	m.ScrollDown(3)
+	anchor, offset := m.ReadingPosition()
+
+	// Text added before the viewport does not move it, even though the
+	// filler paragraphs repeat throughout the document.
+	edited := strings.Replace(tocDoc(), "# Intro\n\n", "# Intro\n\nA new paragraph.\n\nAnother one.\n\n", 1)
+	m.ReplaceText("test.md", edited)
+	assert.Equal(t, "usage", m.CurrentSection().Anchor)
+	gotAnchor, gotOffset := m.ReadingPosition()
+	assert.Equal(t, anchor, gotAnchor)
+	assert.Equal(t, offset, gotOffset)
+	assert.Equal(t, edited, string(m.GetMarkdown()))
+}
+
+func TestReplaceText_EditedBlock(t *testing.T) {
+	m := newMarksModel(t)
+	scrollToAnchor(t, &m, "install")
+
+	// The heading at the top is gone; the view stays at the same position.
+	edited := strings.Replace(tocDoc(), "### Install", "### Installation", 1)
+	m.ReplaceText("test.md", edited)
+	assert.Equal(t, "Installation", strings.TrimLeft(topLine(m), "# "))
+}
+
+func TestReplaceText_KeepsFolds(t *testing.T) {
+	m := newMarksModel(t)
+	sections, ok := m.index.Lookup("setup")
+	require.True(t, ok)
+	m.SetFolded(sections[0], true)
+
+	m.ReplaceText("test.md", tocDoc()+"More text.\n")
+	sections, ok = m.index.Lookup("setup")
+	require.True(t, ok)
+	assert.True(t, m.Folded(sections[0]))
+}
@@ -2,12 +2,16 @@ package view
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
 )
 
 type searchMode int
@@ -15,6 +19,38 @@ type searchMode int
 const (
 	searchModeExact searchMode = iota
 	searchModeRegex
+	searchModeFuzzy
+)
+
+// searchCase controls whether a search distinguishes upper and lower case.
+type searchCase int
+
+const (
+	searchCaseIgnore searchCase = iota
+	// searchCaseSmart ignores case unless the query contains an uppercase
+	// letter.
+	searchCaseSmart
+	searchCaseMatch
+)
+
+// defaultSearchCase returns the case mode of a search in the given mode until
+// the user picks one: regular expressions match case, and other searches
+// ignore it.
+func defaultSearchCase(mode searchMode) searchCase {
+	if mode == searchModeRegex {
+		return searchCaseMatch
+	}
+	return searchCaseIgnore
+}
+
+// searchScope restricts a search to the text of one kind of node.
+type searchScope int
+
+const (
+	searchScopeAll searchScope = iota
+	searchScopeHeadings
+	searchScopeCode
+	searchScopeLinks
 )
 
 // colSpan represents a contiguous span of visible columns.
@@ -23,14 +59,24 @@ type colSpan struct {
 	endCol   int // visible column end (exclusive)
 }
 
+// lineSpan is a span of visible columns on one rendered line.
+type lineSpan struct {
+	lineIndex int // index into m.renderedLines
+	colSpan
+}
+
 type searchMatch struct {
-	lineIndex int       // index into m.lines
-	spans     []colSpan // disjoint highlighted spans
+	lineIndex int        // index into m.renderedLines of the first line
+	spans     []lineSpan // disjoint highlighted spans, which may cover several lines
 }
 
 type searchState struct {
 	active       bool // input prompt is showing
 	mode         searchMode
+	caseMode     searchCase
+	caseChosen   bool // caseMode was picked by the user rather than defaulted
+	wholeWord    bool
+	scope        searchScope
 	query        string
 	confirmed    bool // enter was pressed
 	matches      []searchMatch
@@ -44,89 +90,275 @@ func (m *Model) Searching() bool {
 	return m.search.active
 }
 
-// exactMatchLine finds a case-insensitive exact substring match in text,
-// returning a single colSpan measured in display-width columns.
-func exactMatchLine(text, query string) (colSpan, bool) {
-	idx := strings.Index(strings.ToLower(text), strings.ToLower(query))
-	if idx < 0 {
-		return colSpan{}, false
+// SearchPosition returns the 1-based index of the current search match and the
+// number of matches. current is 0 if there is no current match.
+func (m *Model) SearchPosition() (current, total int) {
+	if len(m.search.matches) == 0 {
+		return 0, 0
 	}
-	startCol := ansi.StringWidth(text[:idx])
-	endCol := ansi.StringWidth(text[:idx+len(query)])
-	return colSpan{startCol: startCol, endCol: endCol}, true
+	return m.search.currentMatch + 1, len(m.search.matches)
 }
 
-// regexMatchLine finds all regex matches in a stripped line, returning column spans.
-func regexMatchLine(stripped string, re *regexp.Regexp) []colSpan {
-	locs := re.FindAllStringIndex(stripped, -1)
-	if len(locs) == 0 {
-		return nil
+// searchPattern compiles a query into a regular expression for the given mode.
+// Exact queries match any run of whitespace, including line breaks, where the
+// query has whitespace. Fuzzy queries match their characters in order within a
+// line, each in its own capture group.
+func searchPattern(query string, mode searchMode, caseMode searchCase) (*regexp.Regexp, error) {
+	var b strings.Builder
+	switch mode {
+	case searchModeExact:
+		inSpace := false
+		for _, r := range query {
+			if unicode.IsSpace(r) {
+				if !inSpace {
+					b.WriteString(`\s+`)
+				}
+				inSpace = true
+				continue
+			}
+			inSpace = false
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	case searchModeRegex:
+		b.WriteString(query)
+	case searchModeFuzzy:
+		for i, r := range query {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			if i > 0 {
+				b.WriteString(`[^\n]*?`)
+			}
+			b.WriteString("(" + regexp.QuoteMeta(string(r)) + ")")
+		}
 	}
 
-	spans := make([]colSpan, 0, len(locs))
-	for _, loc := range locs {
-		if loc[0] == loc[1] {
-			continue // skip zero-width matches
+	pattern := b.String()
+	ignoreCase := caseMode == searchCaseIgnore ||
+		caseMode == searchCaseSmart && strings.ToLower(query) == query
+	// Leave the flags alone if the user specified their own.
+	if ignoreCase && !(mode == searchModeRegex && strings.HasPrefix(pattern, "(?")) {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile("(?m)" + pattern)
+}
+
+// isWordRune reports whether r is part of a word for whole-word matching.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWholeWord reports whether text[start:end] is neither preceded nor followed
+// by a word character.
+func isWholeWord(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(r) {
+		return false
+	}
+	return true
+}
+
+// wrappedLinePrefixes returns, for each rendered line that continues a
+// paragraph from the line before it, the width of the prefix that the
+// renderer wrote before its text: the indentation, block quote bars, and list
+// item indentation that the paragraph's first line has before the paragraph
+// starts. Other lines have no prefix.
+func (m *Model) wrappedLinePrefixes() []int {
+	prefixes := make([]int, len(m.renderedLines))
+	for s := m.spanTree; s != nil; s = s.Next {
+		if k := s.Node.Kind(); k != ast.KindParagraph && k != ast.KindTextBlock || s.End <= s.Start {
+			continue
+		}
+		first, last := m.findRenderedLine(s.Start), m.findRenderedLine(s.End-1)
+		if first >= len(m.renderedLines) {
+			continue
+		}
+		ln := m.renderedLines[first]
+		width := ansi.StringWidth(ansi.Strip(expandTabs(ln.content[:min(max(s.Start-ln.start, 0), len(ln.content))], 8)))
+		for li := first + 1; li <= last && li < len(prefixes); li++ {
+			prefixes[li] = width
+		}
+	}
+	return prefixes
+}
+
+// searchText is the plain text of the rendered lines, joined by newlines, with
+// the offsets at which each line starts. The prefix of a line that continues
+// a wrapped paragraph is left out of the text, so that a phrase that wraps
+// inside a block quote or list item is found.
+type searchText struct {
+	text       string
+	lines      []string
+	prefixes   []int
+	lineStarts []int
+}
+
+// newSearchText returns the search text of the given rendered lines. prefixes
+// holds the width of each line's prefix, as returned by wrappedLinePrefixes;
+// it may be nil if no line has a prefix.
+func newSearchText(rendered []line, prefixes []int) searchText {
+	var b strings.Builder
+	st := searchText{
+		lines:      make([]string, len(rendered)),
+		prefixes:   make([]int, len(rendered)),
+		lineStarts: make([]int, len(rendered)),
+	}
+	for i, ln := range rendered {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		st.lines[i] = ansi.Strip(expandTabs(ln.content, 8))
+		if i < len(prefixes) && prefixes[i] > 0 {
+			st.prefixes[i] = len(ansi.Truncate(st.lines[i], prefixes[i], ""))
+		}
+		st.lineStarts[i] = b.Len()
+		b.WriteString(st.lines[i][st.prefixes[i]:])
+	}
+	st.text = b.String()
+	return st
+}
+
+// spans converts the text range [start, end) into per-line column spans.
+func (st *searchText) spans(start, end int) []lineSpan {
+	first := sort.SearchInts(st.lineStarts, start+1) - 1
+	var spans []lineSpan
+	for li := max(first, 0); li < len(st.lines) && st.lineStarts[li] < end; li++ {
+		ls, prefix, content := st.lineStarts[li], st.lines[li][:st.prefixes[li]], st.lines[li][st.prefixes[li]:]
+		sc, ec := ansi.StringWidth(prefix), ansi.StringWidth(st.lines[li])
+		if start > ls {
+			sc += ansi.StringWidth(content[:start-ls])
+		}
+		if end < ls+len(content) {
+			ec = ansi.StringWidth(prefix) + ansi.StringWidth(content[:end-ls])
+		}
+		if sc < ec {
+			spans = append(spans, lineSpan{lineIndex: li, colSpan: colSpan{startCol: sc, endCol: ec}})
 		}
-		startCol := ansi.StringWidth(stripped[:loc[0]])
-		endCol := ansi.StringWidth(stripped[:loc[1]])
-		spans = append(spans, colSpan{
-			startCol: startCol,
-			endCol:   endCol,
-		})
 	}
 	return spans
 }
 
-// executeSearch runs the current search query against all lines.
-func (m *Model) executeSearch() {
-	m.search.matches = nil
-	m.search.currentMatch = -1
-	m.search.regexError = ""
-	m.search.stale = false
+// scopeRanges returns, for each rendered line, the column ranges covered by
+// nodes in the given scope. It returns nil if the search is not scoped.
+func (m *Model) scopeRanges(scope searchScope) map[int][]colSpan {
+	var selector Selector
+	switch scope {
+	case searchScopeHeadings:
+		selector = isHeading
+	case searchScopeCode:
+		selector = isCodeBlock
+	case searchScopeLinks:
+		selector = isLink
+	default:
+		return nil
+	}
 
-	if m.search.query == "" || m.lines == nil {
-		return
+	colAt := func(li, offset int) int {
+		ln := m.renderedLines[li]
+		return ansi.StringWidth(ansi.Strip(expandTabs(ln.content[:min(max(offset-ln.start, 0), len(ln.content))], 8)))
+	}
+	lineAt := func(offset int) int {
+		return sort.Search(len(m.renderedLines), func(i int) bool { return m.renderedLines[i].end > offset })
 	}
 
-	var re *regexp.Regexp
-	if m.search.mode == searchModeRegex {
-		pattern := m.search.query
-		// Auto-prepend case-insensitive flag unless user specified flags.
-		if !strings.HasPrefix(pattern, "(?") {
-			pattern = "(?i)" + pattern
+	ranges := map[int][]colSpan{}
+	for s := m.spanTree; s != nil; s = s.Next {
+		if _, ok := selector(s.Node); !ok || s.End <= s.Start {
+			continue
+		}
+		first, last := lineAt(s.Start), lineAt(s.End-1)
+		for li := first; li <= last && li < len(m.renderedLines); li++ {
+			span := colSpan{startCol: 0, endCol: math.MaxInt}
+			if li == first {
+				span.startCol = colAt(li, s.Start)
+			}
+			if li == last {
+				span.endCol = colAt(li, s.End)
+			}
+			ranges[li] = append(ranges[li], span)
 		}
-		var err error
-		re, err = regexp.Compile(pattern)
-		if err != nil {
-			m.search.regexError = err.Error()
-			return
+	}
+	return ranges
+}
+
+// inScope reports whether a match starting at the given span lies within the
+// search scope.
+func inScope(ranges map[int][]colSpan, span lineSpan) bool {
+	if ranges == nil {
+		return true
+	}
+	for _, r := range ranges[span.lineIndex] {
+		if span.startCol >= r.startCol && span.startCol < r.endCol {
+			return true
 		}
 	}
+	return false
+}
+
+// findMatches returns the matches for the query and options of a search state
+// in the rendered lines.
+func (m *Model) findMatches(search *searchState) ([]searchMatch, error) {
+	if search.query == "" || m.renderedLines == nil {
+		return nil, nil
+	}
 
-	for i, ln := range m.lines {
-		stripped := ansi.Strip(expandTabs(ln.content, 8))
+	re, err := searchPattern(search.query, search.mode, search.caseMode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Search the rendered lines so that matches inside folded sections are
+	// found; moving to such a match unfolds its section. The lines are
+	// searched as one text so that matches can cross line breaks.
+	st := newSearchText(m.renderedLines, m.wrappedLinePrefixes())
+	scope := m.scopeRanges(search.scope)
+
+	var matches []searchMatch
+	for _, loc := range re.FindAllStringSubmatchIndex(st.text, -1) {
+		if loc[0] == loc[1] {
+			continue // skip zero-width matches
+		}
+		if search.wholeWord && search.mode != searchModeFuzzy && !isWholeWord(st.text, loc[0], loc[1]) {
+			continue
+		}
 
-		if m.search.mode == searchModeExact {
-			if span, ok := exactMatchLine(stripped, m.search.query); ok {
-				m.search.matches = append(m.search.matches, searchMatch{lineIndex: i, spans: []colSpan{span}})
+		var spans []lineSpan
+		if search.mode == searchModeFuzzy {
+			for g := 2; g+1 < len(loc); g += 2 {
+				spans = append(spans, st.spans(loc[g], loc[g+1])...)
 			}
 		} else {
-			spans := regexMatchLine(stripped, re)
-			for _, span := range spans {
-				m.search.matches = append(m.search.matches, searchMatch{
-					lineIndex: i,
-					spans:     []colSpan{span},
-				})
-			}
+			spans = st.spans(loc[0], loc[1])
 		}
+		if len(spans) == 0 || !inScope(scope, spans[0]) {
+			continue
+		}
+		matches = append(matches, searchMatch{lineIndex: spans[0].lineIndex, spans: spans})
+	}
+	return matches, nil
+}
+
+// executeSearch runs the current search query against all lines.
+func (m *Model) executeSearch() {
+	m.search.currentMatch = -1
+	m.search.regexError = ""
+	m.search.stale = false
+
+	matches, err := m.findMatches(&m.search)
+	m.search.matches = matches
+	if err != nil {
+		m.search.regexError = err.Error()
+		return
 	}
 
 	if len(m.search.matches) > 0 {
 		// Find the first match at or after the current viewport.
 		m.search.currentMatch = 0
+		top := m.renderedIndex(m.lineOffset)
 		for i, match := range m.search.matches {
-			if match.lineIndex >= m.lineOffset {
+			if match.lineIndex >= top {
 				m.search.currentMatch = i
 				break
 			}
@@ -135,13 +367,64 @@ func (m *Model) executeSearch() {
 	}
 }
 
+// SearchResult is a match found by FindAll.
+type SearchResult struct {
+	// Match is the index of the match, for use with ShowSearchResult.
+	Match int
+	// Breadcrumbs are the titles of the headings that enclose the match,
+	// outermost first.
+	Breadcrumbs []string
+	// Line is the plain text of the rendered line on which the match starts.
+	Line string
+	// StartCol and EndCol are the visible columns of the match within Line.
+	// EndCol is the end of Line if the match continues onto the next line.
+	StartCol, EndCol int
+}
+
+// FindAll returns the matches for an exact, case-insensitive query in the document
+// without changing the Model's search. The Model must have a size.
+func (m *Model) FindAll(query string) []SearchResult {
+	m.ensureRendered()
+
+	matches, _ := m.findMatches(&searchState{query: query})
+	results := make([]SearchResult, len(matches))
+	for i, match := range matches {
+		first := match.spans[0]
+		results[i] = SearchResult{
+			Match:       i,
+			Breadcrumbs: m.breadcrumbsAt(m.renderedLines[first.lineIndex].start),
+			Line:        ansi.Strip(expandTabs(m.renderedLines[first.lineIndex].content, 8)),
+			StartCol:    first.startCol,
+			EndCol:      first.endCol,
+		}
+	}
+	return results
+}
+
+// ShowSearchResult searches for an exact, case-insensitive query as if it had been
+// entered at the search prompt and moves to the given match, so that the
+// NextMatch and PrevMatch keys continue from it.
+func (m *Model) ShowSearchResult(query string, match int) {
+	m.ensureRendered()
+
+	m.search = searchState{query: query, confirmed: true}
+	m.executeSearch()
+	if match >= 0 && match < len(m.search.matches) {
+		m.search.currentMatch = match
+		m.scrollToMatch(match)
+	}
+}
+
 // scrollToMatch centers the viewport on the given match index.
 func (m *Model) scrollToMatch(idx int) {
 	if idx < 0 || idx >= len(m.search.matches) {
 		return
 	}
 	match := m.search.matches[idx]
-	target := match.lineIndex - m.pageSize/2
+	if match.lineIndex < len(m.renderedLines) {
+		m.revealOffset(m.renderedLines[match.lineIndex].start)
+	}
+	target := m.displayedIndex(match.lineIndex) - m.pageSize/2
 	if target < 0 {
 		target = 0
 	}
@@ -183,14 +466,28 @@ func (m *Model) handleSearchKey(msg tea.KeyPressMsg) tea.Cmd {
 		m.search.confirmed = true
 		return nil
 	case "tab":
-		// Toggle mode.
-		if m.search.mode == searchModeExact {
-			m.search.mode = searchModeRegex
-		} else {
-			m.search.mode = searchModeExact
+		// Cycle between exact, regex, and fuzzy matching.
+		m.search.mode = (m.search.mode + 1) % (searchModeFuzzy + 1)
+		if !m.search.caseChosen {
+			m.search.caseMode = defaultSearchCase(m.search.mode)
 		}
 		m.executeSearch()
 		return nil
+	case "alt+c":
+		// Cycle between ignoring case, smart case, and matching case.
+		m.search.caseMode = (m.search.caseMode + 1) % (searchCaseMatch + 1)
+		m.search.caseChosen = true
+		m.executeSearch()
+		return nil
+	case "alt+w":
+		m.search.wholeWord = !m.search.wholeWord
+		m.executeSearch()
+		return nil
+	case "alt+s":
+		// Cycle the node kinds the search is restricted to.
+		m.search.scope = (m.search.scope + 1) % (searchScopeLinks + 1)
+		m.executeSearch()
+		return nil
 	case "backspace":
 		if len(m.search.query) > 0 {
 			_, size := utf8.DecodeLastRuneInString(m.search.query)
@@ -216,10 +513,11 @@ func (m *Model) applySearchHighlights(lineIdx int, content string) string {
 		current  bool
 	}
 	var lineSpans []spanInfo
+	renderedIdx := m.renderedIndex(lineIdx)
 	for i, match := range m.search.matches {
-		if match.lineIndex == lineIdx {
-			isCurrent := i == m.search.currentMatch
-			for _, s := range match.spans {
+		isCurrent := i == m.search.currentMatch
+		for _, s := range match.spans {
+			if s.lineIndex == renderedIdx {
 				lineSpans = append(lineSpans, spanInfo{
 					startCol: s.startCol,
 					endCol:   s.endCol,
@@ -283,14 +581,21 @@ func (m *Model) applySearchHighlights(lineIdx int, content string) string {
 
 // renderSearchGutter renders the gutter line during search input.
 func (m *Model) renderSearchGutter(width int) string {
-	var modeStr string
-	if m.search.mode == searchModeExact {
-		modeStr = "exact"
-	} else {
-		modeStr = "regex"
+	flags := []string{[...]string{"exact", "regex", "fuzzy"}[m.search.mode]}
+	switch m.search.caseMode {
+	case searchCaseSmart:
+		flags = append(flags, "smart-case")
+	case searchCaseMatch:
+		flags = append(flags, "match-case")
+	}
+	if m.search.wholeWord {
+		flags = append(flags, "word")
+	}
+	if m.search.scope != searchScopeAll {
+		flags = append(flags, [...]string{"", "headings", "code", "links"}[m.search.scope])
 	}
 
-	prompt := fmt.Sprintf("/%s: %s", modeStr, m.search.query)
+	prompt := fmt.Sprintf("/%s: %s", strings.Join(flags, ","), m.search.query)
 
 	// Match count.
 	var info string
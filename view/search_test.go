@@ -1,7 +1,7 @@
 package view
 
 import (
-	"regexp"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
@@ -12,58 +12,71 @@ import (
 )
 
 // ---------------------------------------------------------------------------
-// exactMatchLine
+// searchPattern
 // ---------------------------------------------------------------------------
 
-func TestExactMatchLine_Basic(t *testing.T) {
-	span, ok := exactMatchLine("hello world", "world")
-	assert.True(t, ok)
-	assert.Equal(t, 6, span.startCol)
-	assert.Equal(t, 11, span.endCol)
+func TestSearchPattern_Exact(t *testing.T) {
+	re, err := searchPattern("a.b  c", searchModeExact, searchCaseSmart)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("A.B\n  c"), "whitespace should match line breaks")
+	assert.False(t, re.MatchString("axb c"), "punctuation should be literal")
 }
 
-func TestExactMatchLine_CaseInsensitive(t *testing.T) {
-	span, ok := exactMatchLine("Hello World", "hello")
-	assert.True(t, ok)
-	assert.Equal(t, 0, span.startCol)
-	assert.Equal(t, 5, span.endCol)
-}
+func TestSearchPattern_SmartCase(t *testing.T) {
+	re, err := searchPattern("hello", searchModeExact, searchCaseSmart)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("Hello"))
 
-func TestExactMatchLine_NoMatch(t *testing.T) {
-	_, ok := exactMatchLine("hello", "xyz")
-	assert.False(t, ok)
-}
+	re, err = searchPattern("Hello", searchModeExact, searchCaseSmart)
+	require.NoError(t, err)
+	assert.False(t, re.MatchString("hello"), "an uppercase letter should make the search case-sensitive")
+
+	re, err = searchPattern("Hello", searchModeExact, searchCaseIgnore)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("hello"))
 
-func TestExactMatchLine_EmptyQuery(t *testing.T) {
-	// Empty query matches at position 0 with zero width.
-	span, ok := exactMatchLine("hello", "")
-	assert.True(t, ok)
-	assert.Equal(t, 0, span.startCol)
-	assert.Equal(t, 0, span.endCol)
+	re, err = searchPattern("hello", searchModeExact, searchCaseMatch)
+	require.NoError(t, err)
+	assert.False(t, re.MatchString("Hello"))
 }
 
-// ---------------------------------------------------------------------------
-// regexMatchLine
-// ---------------------------------------------------------------------------
+func TestSearchPattern_Regex(t *testing.T) {
+	re, err := searchPattern("^wor", searchModeRegex, searchCaseSmart)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("hello\nWorld"), "^ should match at line starts")
 
-func TestRegexMatchLine_Basic(t *testing.T) {
-	re := regexp.MustCompile("(?i)world")
-	spans := regexMatchLine("hello world", re)
-	require.Len(t, spans, 1)
-	assert.Equal(t, 6, spans[0].startCol)
-	assert.Equal(t, 11, spans[0].endCol)
+	_, err = searchPattern("[invalid", searchModeRegex, searchCaseSmart)
+	assert.Error(t, err)
 }
 
-func TestRegexMatchLine_Multiple(t *testing.T) {
-	re := regexp.MustCompile("o")
-	spans := regexMatchLine("foo boo", re)
-	require.Len(t, spans, 4) // two o's in foo, two in boo
+func TestSearchPattern_Fuzzy(t *testing.T) {
+	re, err := searchPattern("gst", searchModeFuzzy, searchCaseSmart)
+	require.NoError(t, err)
+	loc := re.FindStringSubmatchIndex("Getting Started")
+	require.NotNil(t, loc)
+	assert.Equal(t, []int{0, 10, 0, 1, 8, 9, 9, 10}, loc)
+	assert.False(t, re.MatchString("g\nst"), "fuzzy matches should not cross lines")
 }
 
-func TestRegexMatchLine_NoMatch(t *testing.T) {
-	re := regexp.MustCompile("xyz")
-	spans := regexMatchLine("hello", re)
-	assert.Empty(t, spans)
+func TestSearchText_Spans(t *testing.T) {
+	st := newSearchText([]line{{content: "one two"}, {content: "  three"}}, nil)
+	start := strings.Index(st.text, "two")
+	end := strings.Index(st.text, "three") + len("three")
+	assert.Equal(t, []lineSpan{
+		{lineIndex: 0, colSpan: colSpan{startCol: 4, endCol: 7}},
+		{lineIndex: 1, colSpan: colSpan{startCol: 0, endCol: 7}},
+	}, st.spans(start, end))
+}
+
+func TestSearchText_SpansWithPrefix(t *testing.T) {
+	st := newSearchText([]line{{content: "> one two"}, {content: "> three"}}, []int{0, 2})
+	assert.Equal(t, "> one two\nthree", st.text)
+	start := strings.Index(st.text, "two")
+	end := strings.Index(st.text, "three") + len("three")
+	assert.Equal(t, []lineSpan{
+		{lineIndex: 0, colSpan: colSpan{startCol: 6, endCol: 9}},
+		{lineIndex: 1, colSpan: colSpan{startCol: 2, endCol: 7}},
+	}, st.spans(start, end))
 }
 
 // ---------------------------------------------------------------------------
@@ -203,6 +216,10 @@ func TestSearch_HandleSearchKey_Tab(t *testing.T) {
 
 	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
 
+	assert.Equal(t, searchModeFuzzy, m.search.mode)
+
+	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
+
 	assert.Equal(t, searchModeExact, m.search.mode)
 }
 
@@ -298,3 +315,175 @@ func splitLines(s string) []string {
 	}
 	return lines
 }
+
+func TestSearch_IgnoresCaseByDefault(t *testing.T) {
+	m := newTestModelWithSearch("hello world")
+
+	m.search.query = "Hello World"
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 1)
+
+	m.search.mode = searchModeFuzzy
+	m.search.query = "HW"
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 1)
+}
+
+func TestSearch_RegexMatchesCaseByDefault(t *testing.T) {
+	m := newTestModelWithSearch("hello world")
+	m.search.active = true
+	m.search.query = "W.rld"
+
+	// Tab switches from exact to regex matching, which matches case.
+	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
+	assert.Equal(t, searchModeRegex, m.search.mode)
+	assert.Equal(t, searchCaseMatch, m.search.caseMode)
+	assert.Empty(t, m.search.matches)
+
+	m.search.query = "(?i)W.rld"
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 1)
+
+	// A case mode picked by the user is kept across modes.
+	m.search.query = "W.rld"
+	m.handleSearchKey(tea.KeyPressMsg{Code: 'c', Mod: tea.ModAlt})
+	assert.Equal(t, searchCaseIgnore, m.search.caseMode)
+	assert.Len(t, m.search.matches, 1)
+	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
+	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
+	m.handleSearchKey(tea.KeyPressMsg{Code: tea.KeyTab})
+	assert.Equal(t, searchModeRegex, m.search.mode)
+	assert.Equal(t, searchCaseIgnore, m.search.caseMode)
+}
+
+func TestSearch_WrappedPhraseInBlockquoteAndList(t *testing.T) {
+	for _, source := range []string{
+		"> - The quick brown fox jumps over the lazy dog.",
+		"> 1. The quick brown fox jumps over the lazy dog.",
+		"- The quick brown fox jumps over the lazy dog.",
+	} {
+		m := NewModel(WithWidth(20), WithHeight(10))
+		m.SetText("test.md", source)
+		require.Greater(t, len(m.renderedLines), 1, "the paragraph should wrap")
+
+		m.search.query = "quick brown fox jumps over"
+		m.executeSearch()
+		require.Len(t, m.search.matches, 1, source)
+		require.Greater(t, len(m.search.matches[0].spans), 1, source)
+	}
+}
+
+func TestSearch_MatchesLinePrefixes(t *testing.T) {
+	for _, tt := range []struct{ source, query string }{
+		{"1. First item\n2. Second item", "1. First"},
+		{"- [ ] todo\n- [x] done", "- [ ] todo"},
+		{"> quoted text", "> quoted"},
+		{"```sh\n- dash\n```", "- dash"},
+	} {
+		m := NewModel(WithWidth(40), WithHeight(10))
+		m.SetText("test.md", tt.source)
+		assert.Len(t, m.FindAll(tt.query), 1, tt.source)
+	}
+}
+
+func TestSearch_WrappedPhrase(t *testing.T) {
+	m := NewModel(WithWidth(20), WithHeight(10))
+	m.SetText("test.md", "The quick brown fox jumps over the lazy dog.")
+	require.Greater(t, len(m.renderedLines), 1, "the paragraph should wrap")
+
+	m.search.query = "fox jumps over"
+	m.executeSearch()
+	require.Len(t, m.search.matches, 1)
+	spans := m.search.matches[0].spans
+	require.Len(t, spans, 2)
+	assert.NotEqual(t, spans[0].lineIndex, spans[1].lineIndex)
+}
+
+func TestSearch_WholeWord(t *testing.T) {
+	m := newTestModelWithSearch("cat concat cat_1 cats (cat)")
+
+	m.search.query = "cat"
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 5)
+
+	m.search.wholeWord = true
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 2)
+}
+
+func TestSearch_Scoped(t *testing.T) {
+	m := newTestModelWithSearch("# Install\n\nTo install, run:\n\n```sh\nmake install\n```\n\nSee [install docs](https://example.com).\n")
+
+	m.search.query = "install"
+	m.executeSearch()
+	assert.Len(t, m.search.matches, 4)
+
+	for scope, want := range map[searchScope]int{searchScopeHeadings: 1, searchScopeCode: 1, searchScopeLinks: 1} {
+		m.search.scope = scope
+		m.executeSearch()
+		assert.Len(t, m.search.matches, want, "scope %d", scope)
+	}
+}
+
+func TestSearch_FuzzyHighlightsCharacters(t *testing.T) {
+	m := newTestModelWithSearch("Getting Started")
+
+	m.search.mode = searchModeFuzzy
+	m.search.query = "gs"
+	m.executeSearch()
+	require.Len(t, m.search.matches, 1)
+	assert.Len(t, m.search.matches[0].spans, 2)
+}
+
+func TestSearch_OptionKeys(t *testing.T) {
+	m := newTestModelWithSearch("Hello world")
+	m.search.active = true
+	m.search.query = "hello"
+
+	m.handleSearchKey(tea.KeyPressMsg{Code: 'c', Mod: tea.ModAlt})
+	m.handleSearchKey(tea.KeyPressMsg{Code: 'w', Mod: tea.ModAlt})
+	m.handleSearchKey(tea.KeyPressMsg{Code: 's', Mod: tea.ModAlt})
+	assert.Equal(t, searchCaseSmart, m.search.caseMode)
+	assert.True(t, m.search.wholeWord)
+	assert.Equal(t, searchScopeHeadings, m.search.scope)
+
+	lines := splitLines(m.View())
+	gutter := ansi.Strip(lines[len(lines)-1])
+	assert.Contains(t, gutter, "/exact,smart-case,word,headings: hello")
+}
+
+func TestSearch_Position(t *testing.T) {
+	m := newTestModelWithSearch("Hello world\n\nHello again")
+
+	current, total := m.SearchPosition()
+	assert.Equal(t, 0, current)
+	assert.Equal(t, 0, total)
+
+	m.search.query = "hello"
+	m.executeSearch()
+	m.nextMatch()
+	current, total = m.SearchPosition()
+	assert.Equal(t, 2, current)
+	assert.Equal(t, 2, total)
+}
+
+func TestSearch_FindAll(t *testing.T) {
+	m := newTestModelWithSearch("# Guide\n\n## Install\n\nRun the installer.\n\n## Usage\n\nRun it again.\n")
+
+	results := m.FindAll("run")
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"Guide", "Install"}, results[0].Breadcrumbs)
+	assert.Equal(t, []string{"Guide", "Usage"}, results[1].Breadcrumbs)
+	assert.Equal(t, "Run", results[1].Line[results[1].StartCol:results[1].EndCol])
+
+	// FindAll leaves the Model's search alone.
+	current, total := m.SearchPosition()
+	assert.Equal(t, 0, current)
+	assert.Equal(t, 0, total)
+
+	m.ShowSearchResult("run", 1)
+	current, total = m.SearchPosition()
+	assert.Equal(t, 2, current)
+	assert.Equal(t, 2, total)
+	assert.True(t, m.search.confirmed)
+}
@@ -0,0 +1,147 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/pgavlin/goldmark/ast"
+)
+
+// SlideBreak selects where Slides splits the document into slides.
+type SlideBreak int
+
+const (
+	// BreakOnRule starts a new slide at each thematic break.
+	BreakOnRule SlideBreak = iota
+	// BreakOnH1 starts a new slide at each thematic break and each level 1
+	// heading.
+	BreakOnH1
+	// BreakOnH2 starts a new slide at each thematic break and each level 1
+	// or 2 heading.
+	BreakOnH2
+)
+
+// Slide is one slide of the document, as split by Slides.
+type Slide struct {
+	// Markdown is the Markdown source of the slide, without its notes. It
+	// starts with the document's link reference definitions, so that
+	// reference links resolve on every slide.
+	Markdown string
+	// Notes are the speaker notes of the slide: the text of the HTML comments
+	// at its top level.
+	Notes string
+	// Reveal holds the lengths of the prefixes of Markdown that reveal the
+	// items of the slide's top-level lists one at a time. The whole slide is
+	// the last step of the reveal and is not included.
+	Reveal []int
+}
+
+// Slides splits the document into slides. Thematic breaks, and headings if
+// brk asks for them, start new slides; slides without content are dropped.
+func (m *Model) Slides(brk SlideBreak) []Slide {
+	if m.document == nil {
+		return nil
+	}
+
+	definitions := m.linkReferenceDefinitions()
+
+	var slides []Slide
+	var b strings.Builder
+	var notes []string
+	var reveal []int
+	var listed bool // whether the slide has a list item yet
+	flush := func() {
+		if b.Len() > 0 {
+			markdown := b.String()
+			if definitions != "" {
+				markdown = definitions + "\n\n" + markdown
+				for i := range reveal {
+					reveal[i] += len(definitions) + 2
+				}
+			}
+			slides = append(slides, Slide{Markdown: markdown, Notes: strings.Join(notes, "\n\n"), Reveal: reveal})
+		}
+		b.Reset()
+		notes, reveal, listed = nil, nil, false
+	}
+
+	for n := m.document.FirstChild(); n != nil; n = n.NextSibling() {
+		switch n := n.(type) {
+		case *ast.ThematicBreak:
+			flush()
+			continue
+		case *ast.LinkReferenceDefinition:
+			// Definitions are added to every slide.
+			continue
+		case *ast.Heading:
+			if (brk == BreakOnH1 && n.Level == 1) || (brk == BreakOnH2 && n.Level <= 2) {
+				flush()
+			}
+		case *ast.HTMLBlock:
+			if n.HTMLBlockType == ast.HTMLBlockType2 {
+				if note, ok := m.slideNote(n); ok {
+					notes = append(notes, note)
+				}
+				continue
+			}
+		}
+
+		start, end, ok := m.nodeSourceRange(n)
+		if !ok {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		if list, ok := n.(*ast.List); ok {
+			// Each item after the first starts a new step of the reveal.
+			for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+				if itemStart, _, ok := m.nodeSourceRange(item); ok {
+					if listed {
+						reveal = append(reveal, b.Len()+itemStart-start)
+					}
+					listed = true
+				}
+			}
+		}
+		b.Write(m.markdown[start:end])
+	}
+	flush()
+	return slides
+}
+
+// linkReferenceDefinitions returns the source of the document's link reference
+// definitions, one per line.
+func (m *Model) linkReferenceDefinitions() string {
+	var definitions []string
+	ast.Walk(m.document, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if d, ok := n.(*ast.LinkReferenceDefinition); ok && entering {
+			var b strings.Builder
+			lines := d.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				b.Write(seg.Value(m.markdown))
+			}
+			definitions = append(definitions, strings.TrimSpace(b.String()))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(definitions, "\n")
+}
+
+// slideNote returns the text of an HTML comment block.
+func (m *Model) slideNote(n *ast.HTMLBlock) (string, bool) {
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		b.Write(seg.Value(m.markdown))
+	}
+	if n.HasClosure() {
+		b.Write(n.ClosureLine.Value(m.markdown))
+	}
+	text := strings.TrimSpace(b.String())
+	text = strings.TrimPrefix(text, "<!--")
+	text = strings.TrimSuffix(text, "-->")
+	text = strings.TrimSpace(text)
+	return text, text != ""
+}
@@ -0,0 +1,98 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const slidesDoc = `# Talk
+
+By me.
+
+---
+
+## Plan
+
+Three steps:
+
+- One
+- Two
+- Three
+
+<!--
+Mention the schedule.
+-->
+
+---
+
+---
+
+## Code
+
+` + "```go\nfmt.Println(\"hi\")\n```" + `
+
+## Questions
+`
+
+func slidesModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel()
+	m.SetText("talk.md", slidesDoc)
+	return m
+}
+
+func TestSlides_Rules(t *testing.T) {
+	m := slidesModel(t)
+	slides := m.Slides(BreakOnRule)
+	require.Len(t, slides, 3, "empty slides are dropped")
+
+	assert.Equal(t, "# Talk\n\nBy me.", slides[0].Markdown)
+	assert.Equal(t, "## Plan\n\nThree steps:\n\n- One\n- Two\n- Three", slides[1].Markdown)
+	assert.Equal(t, "Mention the schedule.", slides[1].Notes)
+	assert.Equal(t, "## Code\n\n```go\nfmt.Println(\"hi\")\n```\n\n## Questions", slides[2].Markdown)
+}
+
+func TestSlides_Headings(t *testing.T) {
+	m := slidesModel(t)
+	slides := m.Slides(BreakOnH2)
+	require.Len(t, slides, 4)
+	assert.Equal(t, "## Questions", slides[3].Markdown)
+
+	assert.Len(t, m.Slides(BreakOnH1), 3)
+}
+
+func TestSlides_Reveal(t *testing.T) {
+	m := slidesModel(t)
+	s := m.Slides(BreakOnRule)[1]
+	var steps []string
+	for _, n := range s.Reveal {
+		steps = append(steps, s.Markdown[:n])
+	}
+	assert.Equal(t, []string{
+		"## Plan\n\nThree steps:\n\n- One\n",
+		"## Plan\n\nThree steps:\n\n- One\n- Two\n",
+	}, steps)
+	assert.Empty(t, m.Slides(BreakOnRule)[0].Reveal)
+}
+
+func TestSlides_LinkReferenceDefinitions(t *testing.T) {
+	m := NewModel()
+	m.SetText("talk.md", "# One\n\nSee [docs][d].\n\n- [first][d]\n- second\n\n---\n\n# Two\n\n[d]: https://example.com \"Docs\"\n")
+	slides := m.Slides(BreakOnRule)
+	require.Len(t, slides, 2, "a slide with only definitions is dropped")
+
+	const definitions = "[d]: https://example.com \"Docs\"\n\n"
+	assert.Equal(t, definitions+"# One\n\nSee [docs][d].\n\n- [first][d]\n- second", slides[0].Markdown)
+	assert.Equal(t, definitions+"# Two", slides[1].Markdown)
+	require.Len(t, slides[0].Reveal, 1)
+	assert.Equal(t, definitions+"# One\n\nSee [docs][d].\n\n- [first][d]\n", slides[0].Markdown[:slides[0].Reveal[0]])
+
+	// The reference link resolves on the slide.
+	s := NewModel(WithWidth(40))
+	s.SetText("slide.md", slides[0].Markdown)
+	s.SetSize(40, 10)
+	assert.NotContains(t, ansi.Strip(s.View()), "[docs][d]")
+}
@@ -0,0 +1,260 @@
+package view
+
+import (
+	"slices"
+
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// Snapshot is the state of a Model's view of its document, as returned by
+// Snapshot: the scroll position, content width, folds, selection, navigation
+// backstack, search, and cursor. Positions are recorded as source offsets of
+// the document's nodes rather than as rendered lines, so a snapshot restores
+// the same view at a different width. Snapshots can be encoded as JSON.
+type Snapshot struct {
+	// Top is the position of the first visible line.
+	Top          Position `json:"top"`
+	ColumnOffset int      `json:"columnOffset,omitempty"`
+	ContentWidth int      `json:"contentWidth,omitempty"`
+
+	// Folds holds the anchors of the folded sections.
+	Folds []string `json:"folds,omitempty"`
+
+	// Selection is the selected node, if any.
+	Selection          *NodeRef `json:"selection,omitempty"`
+	HighlightSelection bool     `json:"highlightSelection,omitempty"`
+	// Backstack holds the selections to return to with GoBack, oldest first.
+	Backstack []NodeRef `json:"backstack,omitempty"`
+
+	// Search is the search, if any.
+	Search *SearchSnapshot `json:"search,omitempty"`
+
+	// CursorMode and VisualMode are true in cursor and visual mode. Cursor is
+	// the position of the cursor, and VisualAnchor the position where the
+	// visual selection started.
+	CursorMode   bool      `json:"cursorMode,omitempty"`
+	VisualMode   bool      `json:"visualMode,omitempty"`
+	Cursor       *Position `json:"cursor,omitempty"`
+	VisualAnchor *Position `json:"visualAnchor,omitempty"`
+}
+
+// Position is a position in the rendered document: a line of the block that
+// starts at a source byte offset.
+type Position struct {
+	// Offset is the source byte offset of the block.
+	Offset int `json:"offset"`
+	// Line is the index of the line among the block's rendered lines. Lines
+	// between blocks are before the next block, at negative indices.
+	Line int `json:"line,omitempty"`
+	// Column is the visible column on the line.
+	Column int `json:"column,omitempty"`
+}
+
+// NodeRef identifies a node of the document by its kind and source range.
+type NodeRef struct {
+	Kind  string `json:"kind"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// SearchSnapshot is the state of a search.
+type SearchSnapshot struct {
+	Query     string `json:"query"`
+	Mode      int    `json:"mode,omitempty"`
+	Case      int    `json:"case,omitempty"`
+	WholeWord bool   `json:"wholeWord,omitempty"`
+	Scope     int    `json:"scope,omitempty"`
+	// Prompting is true if the search prompt is showing, and Confirmed is
+	// true once the query has been entered.
+	Prompting bool `json:"prompting,omitempty"`
+	Confirmed bool `json:"confirmed,omitempty"`
+	// Match is the index of the current match, or -1 if there is none.
+	Match int `json:"match"`
+}
+
+// Snapshot returns the state of the view of the document, for Restore.
+func (m *Model) Snapshot() Snapshot {
+	m.ensureRendered()
+
+	s := Snapshot{
+		ColumnOffset: m.columnOffset,
+		ContentWidth: m.contentWidth,
+		Folds:        m.foldedAnchors(),
+		CursorMode:   m.cursorMode,
+		VisualMode:   m.visualMode,
+	}
+	slices.Sort(s.Folds)
+	if p, ok := m.positionAt(m.lineOffset, 0); ok {
+		s.Top = p
+	} else {
+		s.Top.Offset = m.SourceOffset()
+	}
+
+	if m.selection != nil {
+		if ref, ok := m.nodeRef(m.selection.Node); ok {
+			s.Selection, s.HighlightSelection = &ref, m.highlightSelection
+		}
+	}
+	for _, span := range m.backstack {
+		if ref, ok := m.nodeRef(span.Node); ok {
+			s.Backstack = append(s.Backstack, ref)
+		}
+	}
+
+	if m.search.active || m.search.query != "" {
+		s.Search = &SearchSnapshot{
+			Query:     m.search.query,
+			Mode:      int(m.search.mode),
+			Case:      int(m.search.caseMode),
+			WholeWord: m.search.wholeWord,
+			Scope:     int(m.search.scope),
+			Prompting: m.search.active,
+			Confirmed: m.search.confirmed,
+			Match:     m.search.currentMatch,
+		}
+	}
+
+	if m.cursorPositioned {
+		if p, ok := m.positionAt(m.cursorLine, m.cursorCol); ok {
+			s.Cursor = &p
+		}
+		if m.visualMode {
+			if p, ok := m.positionAt(m.visualAnchorLine, m.visualAnchorCol); ok {
+				s.VisualAnchor = &p
+			}
+		}
+	}
+	return s
+}
+
+// Restore re-applies a snapshot returned by Snapshot, usually after setting
+// the same text with SetText. Nodes that are no longer in the document are
+// skipped. If the document has not been rendered yet, the snapshot is
+// restored once it is.
+func (m *Model) Restore(s Snapshot) {
+	if s.ContentWidth != m.contentWidth {
+		m.SetContentWidth(s.ContentWidth)
+	}
+	m.UnfoldAll()
+	m.refoldAnchors(s.Folds)
+
+	m.ensureRendered()
+	if m.lines == nil {
+		m.pendingRestore = &s
+		return
+	}
+	m.restore(s)
+}
+
+// restore re-applies a snapshot to the rendered document.
+func (m *Model) restore(s Snapshot) {
+	m.search = searchState{currentMatch: -1}
+	if q := s.Search; q != nil {
+		m.search.query = q.Query
+		m.search.mode = searchMode(q.Mode)
+		m.search.caseMode = searchCase(q.Case)
+		m.search.caseChosen = m.search.caseMode != defaultSearchCase(m.search.mode)
+		m.search.wholeWord = q.WholeWord
+		m.search.scope = searchScope(q.Scope)
+		if m.search.query != "" {
+			m.executeSearch()
+		}
+		m.search.active, m.search.confirmed = q.Prompting, q.Confirmed
+		if q.Match >= -1 && q.Match < len(m.search.matches) {
+			m.search.currentMatch = q.Match
+		}
+	}
+
+	m.backstack = nil
+	for _, ref := range s.Backstack {
+		if span := m.spanForRef(ref); span != nil {
+			m.backstack = append(m.backstack, span)
+		}
+	}
+	m.selection, m.highlightSelection = nil, false
+	if s.Selection != nil {
+		if span := m.spanForRef(*s.Selection); span != nil {
+			m.selection, m.highlightSelection = span, s.HighlightSelection
+			m.calculateSelectionSpan(span)
+		}
+	}
+
+	if li, ok := m.lineAt(s.Top); ok {
+		m.lineOffset = li
+	}
+	m.columnOffset = s.ColumnOffset
+
+	m.cursorMode, m.visualMode, m.cursorPositioned = s.CursorMode, s.VisualMode, false
+	if s.Cursor != nil {
+		if li, ok := m.lineAt(*s.Cursor); ok {
+			m.cursorLine, m.cursorCol, m.cursorPositioned = li, s.Cursor.Column, true
+		}
+	}
+	if m.cursorMode || m.visualMode {
+		if !m.cursorPositioned {
+			m.positionCursor()
+		}
+		m.visualAnchorLine, m.visualAnchorCol = m.cursorLine, m.cursorCol
+		if s.VisualAnchor != nil {
+			if li, ok := m.lineAt(*s.VisualAnchor); ok {
+				m.visualAnchorLine, m.visualAnchorCol = li, s.VisualAnchor.Column
+			}
+		}
+	}
+	m.clampOffsets()
+}
+
+// positionAt returns the position of the given column of the given displayed
+// line.
+func (m *Model) positionAt(li, col int) (Position, bool) {
+	if li < 0 || li >= len(m.lines) {
+		return Position{}, false
+	}
+	ri := m.renderedIndex(li)
+	if ri < 0 || ri >= len(m.renderedLines) {
+		return Position{}, false
+	}
+	offset, ok := m.sourceOffsetAt(m.renderedLines[ri].start)
+	if !ok {
+		return Position{}, false
+	}
+	first, ok := m.renderedLineForSource(offset)
+	if !ok {
+		return Position{}, false
+	}
+	return Position{Offset: offset, Line: ri - first, Column: col}, true
+}
+
+// lineAt returns the index of the displayed line at the given position.
+func (m *Model) lineAt(p Position) (int, bool) {
+	first, ok := m.renderedLineForSource(p.Offset)
+	if !ok || len(m.renderedLines) == 0 {
+		return 0, false
+	}
+	return m.displayedIndex(min(max(first+p.Line, 0), len(m.renderedLines)-1)), true
+}
+
+// nodeRef returns a reference to the given node. Returns false if the node has
+// no source text.
+func (m *Model) nodeRef(n ast.Node) (NodeRef, bool) {
+	start, end, ok := m.nodeSourceRange(n)
+	if !ok {
+		return NodeRef{}, false
+	}
+	return NodeRef{Kind: n.Kind().String(), Start: start, End: end}, true
+}
+
+// spanForRef returns the span of the node that the given reference refers to,
+// or nil if there is no such node.
+func (m *Model) spanForRef(ref NodeRef) *renderer.NodeSpan {
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node.Kind().String() != ref.Kind {
+			continue
+		}
+		if start, end, ok := m.nodeSourceRange(s.Node); ok && start == ref.Start && end == ref.End {
+			return s
+		}
+	}
+	return nil
+}
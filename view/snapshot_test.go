@@ -0,0 +1,118 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotDoc() string {
+	var sb strings.Builder
+	sb.WriteString("# Snapshot\n\nSee [one](https://one.example) and [two](https://two.example).\n\n")
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(&sb, "## Part %d\n\n", i)
+		for j := 0; j < 15; j++ {
+			sb.WriteString("Some words of text that wrap at narrow widths, repeated to fill the page.\n\n")
+		}
+	}
+	return sb.String()
+}
+
+func newSnapshotModel(t *testing.T, width int) Model {
+	t.Helper()
+	m := NewModel()
+	m.SetText("snapshot.md", snapshotDoc())
+	m.SetSize(width, 24)
+	require.NotNil(t, m.lines)
+	return m
+}
+
+func TestSnapshot_RestoresState(t *testing.T) {
+	m := newSnapshotModel(t, 80)
+	m, _ = m.Update(keyMsg(']'))
+	m, _ = m.Update(keyMsg(']'))
+	require.NotNil(t, m.Selection())
+	m, _ = m.Update(keyMsg('/'))
+	for _, r := range "words" {
+		m, _ = m.Update(keyMsg(r))
+	}
+	m.search.active, m.search.confirmed = false, true
+	sections, ok := m.index.Lookup("part-2")
+	require.True(t, ok)
+	m.SetFolded(sections[0], true)
+	m.ScrollDown(5)
+	m.SetContentWidth(60)
+	m.ensureRendered()
+	m.search.currentMatch = 3
+
+	s := m.Snapshot()
+	top, selection := m.SourceOffset(), m.Selection().Node
+
+	m.SetText("snapshot.md", snapshotDoc())
+	m.SetContentWidth(0)
+	m.Restore(s)
+
+	assert.Equal(t, top, m.SourceOffset())
+	require.NotNil(t, m.Selection())
+	assert.Equal(t, selection.Kind(), m.Selection().Node.Kind())
+	ref, _ := m.nodeRef(m.Selection().Node)
+	assert.Equal(t, *s.Selection, ref)
+	assert.Equal(t, "words", m.search.query)
+	assert.True(t, m.search.confirmed)
+	assert.Equal(t, 3, m.search.currentMatch)
+	assert.Equal(t, 60, m.ContentWidth())
+	sections, _ = m.index.Lookup("part-2")
+	assert.True(t, m.Folded(sections[0]))
+}
+
+func TestSnapshot_SurvivesWidthChange(t *testing.T) {
+	m := newSnapshotModel(t, 100)
+	m.ScrollDown(30)
+	s := m.Snapshot()
+	top := m.SourceOffset()
+
+	narrow := NewModel()
+	narrow.SetText("snapshot.md", snapshotDoc())
+	narrow.SetSize(40, 24)
+	narrow.Restore(s)
+	assert.Equal(t, top, narrow.SourceOffset())
+}
+
+func TestSnapshot_RestoreBeforeRender(t *testing.T) {
+	m := newSnapshotModel(t, 80)
+	m, _ = m.Update(keyMsg(']'))
+	m.ScrollDown(12)
+	s := m.Snapshot()
+
+	other := NewModel()
+	other.SetText("snapshot.md", snapshotDoc())
+	other.Restore(s)
+	other.SetSize(80, 24)
+	other.ensureRendered()
+	assert.Equal(t, m.SourceOffset(), other.SourceOffset())
+	require.NotNil(t, other.Selection())
+}
+
+func TestSnapshot_CursorAndJSON(t *testing.T) {
+	m := newSnapshotModel(t, 80)
+	m, _ = m.Update(keyMsg('v'))
+	m, _ = m.Update(keyMsg('j'))
+	m, _ = m.Update(keyMsg('$'))
+	line, col := m.cursorLine, m.cursorCol
+
+	data, err := json.Marshal(m.Snapshot())
+	require.NoError(t, err)
+	var s Snapshot
+	require.NoError(t, json.Unmarshal(data, &s))
+
+	m.SetText("snapshot.md", snapshotDoc())
+	assert.False(t, m.VisualMode())
+	m.Restore(s)
+	assert.True(t, m.VisualMode())
+	assert.Equal(t, line, m.cursorLine)
+	assert.Equal(t, col, m.cursorCol)
+}
@@ -0,0 +1,116 @@
+package view
+
+import (
+	"sort"
+
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/goldmark/text"
+	"github.com/pgavlin/markdown-kit/renderer"
+)
+
+// The Model maps between offsets in the rendered text and offsets in the
+// Markdown source by way of the span tree. Most blocks are mapped as a whole,
+// but code blocks render each source line as one line of text, so lines
+// within a code block are mapped exactly.
+
+// blockSpanAt returns the span of the innermost block that contains the given
+// rendered byte offset, or of the first block after it if the offset is
+// between blocks.
+func (m *Model) blockSpanAt(offset int) *renderer.NodeSpan {
+	var found *renderer.NodeSpan
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node.Type() != ast.TypeBlock || s.Node.Kind() == ast.KindDocument {
+			continue
+		}
+		if s.Start > offset {
+			if found == nil {
+				found = s
+			}
+			break
+		}
+		// Spans are in preorder, so later containing spans are deeper.
+		if s.Contains(offset) {
+			found = s
+		}
+	}
+	return found
+}
+
+// renderedLineAt returns the index of the rendered line that contains the
+// given rendered byte offset.
+func (m *Model) renderedLineAt(offset int) int {
+	return sort.Search(len(m.renderedLines), func(i int) bool {
+		return m.renderedLines[i].end > offset
+	})
+}
+
+// codeLines returns the source lines of a code block span and the index of
+// the rendered line that shows the first of them. It returns false if the
+// span is not a code block.
+func (m *Model) codeLines(s *renderer.NodeSpan) (*text.Segments, int, bool) {
+	var first int
+	switch s.Node.Kind() {
+	case ast.KindFencedCodeBlock:
+		first = m.renderedLineAt(s.Start) + 1 // skip the opening fence
+	case ast.KindCodeBlock:
+		first = m.renderedLineAt(s.Start)
+	default:
+		return nil, 0, false
+	}
+	lines := s.Node.Lines()
+	if lines.Len() == 0 {
+		return nil, 0, false
+	}
+	return lines, first, true
+}
+
+// sourceOffsetAt returns the source byte offset of the text rendered at the
+// given rendered byte offset.
+func (m *Model) sourceOffsetAt(offset int) (int, bool) {
+	s := m.blockSpanAt(offset)
+	if s == nil {
+		return 0, false
+	}
+	if lines, first, ok := m.codeLines(s); ok {
+		i := min(max(m.renderedLineAt(offset)-first, 0), lines.Len()-1)
+		return lines.At(i).Start, true
+	}
+	return nodeSourceOffset(s.Node)
+}
+
+// renderedLineForSource returns the index of the rendered line that shows the
+// text at the given source byte offset.
+func (m *Model) renderedLineForSource(offset int) (int, bool) {
+	n := m.nodeAtSourceOffset(offset)
+	if n == nil {
+		return 0, false
+	}
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Node != n {
+			continue
+		}
+		if lines, first, ok := m.codeLines(s); ok {
+			i := sort.Search(lines.Len(), func(i int) bool { return lines.At(i).Start > offset }) - 1
+			return first + max(i, 0), true
+		}
+		return m.renderedLineAt(s.Start), true
+	}
+	return 0, false
+}
+
+// ScrollToSourceOffset scrolls the text rendered from the given source byte
+// offset to the top of the viewport, unfolding any section that hides it.
+// Unlike GotoSourceOffset, lines within code blocks are matched exactly, but
+// the document must already be rendered. Returns false if it is not.
+func (m *Model) ScrollToSourceOffset(offset int) bool {
+	m.ensureRendered()
+
+	li, ok := m.renderedLineForSource(offset)
+	if !ok || li >= len(m.renderedLines) {
+		return false
+	}
+	m.revealOffset(m.renderedLines[li].start)
+	m.lineOffset = m.displayedIndex(li)
+	m.clampOffsets()
+	return true
+}
@@ -0,0 +1,52 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sourceMapDoc() string {
+	var b strings.Builder
+	b.WriteString("# Title\n\nIntro.\n\n```go\n")
+	for i := 0; i < 30; i++ {
+		b.WriteString("line()\n")
+	}
+	b.WriteString("```\n\n## After\n\n")
+	b.WriteString(strings.Repeat("Done.\n\n", 10))
+	return b.String()
+}
+
+func TestSourceMap_CodeLines(t *testing.T) {
+	src := sourceMapDoc()
+	m := NewModel()
+	m.SetText("map.md", src)
+	m.SetSize(80, 10)
+
+	// The tenth line of the code block.
+	offset := strings.Index(src, "line()") + 9*len("line()\n")
+	require.True(t, m.ScrollToSourceOffset(offset))
+	assert.Equal(t, offset, m.SourceOffset())
+	assert.Equal(t, "line()", strings.TrimSpace(ansi.Strip(m.lines[m.lineOffset].content)))
+
+	// A block is mapped to its start.
+	after := strings.Index(src, "## After")
+	require.True(t, m.ScrollToSourceOffset(after+3))
+	assert.Equal(t, after+3, m.SourceOffset())
+	assert.Contains(t, ansi.Strip(m.lines[m.lineOffset].content), "After")
+}
+
+func TestSourceMap_RevealsFold(t *testing.T) {
+	src := sourceMapDoc()
+	m := NewModel()
+	m.SetText("map.md", src)
+	m.SetSize(80, 10)
+	m.FoldAll()
+
+	offset := strings.Index(src, "line()") + 20*len("line()\n")
+	require.True(t, m.ScrollToSourceOffset(offset))
+	assert.Equal(t, offset, m.SourceOffset())
+}
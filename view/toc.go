@@ -0,0 +1,426 @@
+package view
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/alecthomas/chroma"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/indexer"
+)
+
+// defaultTOCWidth is the width of the table of contents sidebar, including
+// its separator column, when no width has been configured.
+const defaultTOCWidth = 30
+
+// minTOCTextWidth is the minimum width left for document text when the
+// sidebar is shown. Viewports too narrow to fit both hide the sidebar.
+const minTOCTextWidth = 20
+
+// tocEntry is a single visible row of the table of contents.
+type tocEntry struct {
+	section     *indexer.Section
+	depth       int // nesting depth, 0 for top-level sections
+	title       string
+	hasChildren bool
+	collapsed   bool
+}
+
+type tocState struct {
+	visible   bool
+	focused   bool // sidebar has keyboard focus
+	width     int  // configured width; 0 means defaultTOCWidth
+	collapsed map[*indexer.Section]bool
+	cursor    int // index into the visible entries
+	offset    int // index of the first entry shown
+}
+
+// TOCVisible reports whether the table of contents sidebar is shown.
+func (m *Model) TOCVisible() bool {
+	return m.toc.visible
+}
+
+// SetTOC shows or hides the table of contents sidebar. Hiding the sidebar
+// also returns keyboard focus to the document.
+func (m *Model) SetTOC(show bool) {
+	m.toc.visible = show
+	if !show {
+		m.toc.focused = false
+	}
+	m.ensureRendered()
+}
+
+// TOCFocused reports whether the table of contents sidebar has keyboard focus.
+func (m *Model) TOCFocused() bool {
+	return m.toc.focused
+}
+
+// FocusTOC gives keyboard focus to the table of contents sidebar, or returns
+// it to the document. Focusing the sidebar shows it and places its cursor on
+// the current section.
+func (m *Model) FocusTOC(focus bool) {
+	if focus {
+		if !m.toc.visible {
+			m.SetTOC(true)
+		}
+		if idx := m.currentTOCEntry(m.tocEntries()); idx >= 0 {
+			m.toc.cursor = idx
+		}
+	}
+	m.toc.focused = focus
+}
+
+// SetTOCWidth sets the width of the table of contents sidebar, including its
+// separator column. 0 restores the default width.
+func (m *Model) SetTOCWidth(width int) {
+	m.toc.width = width
+	m.ensureRendered()
+}
+
+// tocWidth returns the number of columns taken by the sidebar, or 0 if the
+// sidebar is hidden or does not fit.
+func (m *Model) tocWidth() int {
+	if !m.toc.visible {
+		return 0
+	}
+	w := m.toc.width
+	if w <= 0 {
+		w = defaultTOCWidth
+	}
+	if w > m.width-minTOCTextWidth {
+		w = m.width - minTOCTextWidth
+	}
+	if w < 3 {
+		return 0
+	}
+	return w
+}
+
+// textWidth returns the viewport width available to document text.
+func (m *Model) textWidth() int {
+	return m.width - m.tocWidth() - m.signWidth()
+}
+
+// CurrentSection returns the innermost section containing the first visible
+// line, or nil if the viewport is above the first heading.
+func (m *Model) CurrentSection() *indexer.Section {
+	if m.index == nil {
+		return nil
+	}
+	heading := m.headingAt(m.lineOffset)
+	if heading == nil {
+		return nil
+	}
+	path := sectionPath(m.index.TableOfContents(), heading)
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// headingAt returns the last heading that starts at or before the given line.
+func (m *Model) headingAt(lineOffset int) ast.Node {
+	if m.spanTree == nil || lineOffset < 0 || lineOffset >= len(m.lines) {
+		return nil
+	}
+	topOffset := m.lines[lineOffset].start
+
+	var heading ast.Node
+	for s := m.spanTree; s != nil; s = s.Next {
+		if s.Start > topOffset {
+			break
+		}
+		if _, ok := s.Node.(*ast.Heading); ok {
+			heading = s.Node
+		}
+	}
+	return heading
+}
+
+// sectionPath returns the chain of sections from the top level down to the
+// section that starts with the given node.
+func sectionPath(root *indexer.Section, start ast.Node) []*indexer.Section {
+	for _, s := range root.Subsections {
+		if s.Start == start {
+			return []*indexer.Section{s}
+		}
+		if path := sectionPath(s, start); path != nil {
+			return append([]*indexer.Section{s}, path...)
+		}
+	}
+	return nil
+}
+
+// tocEntries flattens the section tree into the rows currently shown in the
+// sidebar, omitting the subsections of collapsed sections.
+func (m *Model) tocEntries() []tocEntry {
+	if m.index == nil {
+		return nil
+	}
+	var entries []tocEntry
+	var walk func(s *indexer.Section, depth int)
+	walk = func(s *indexer.Section, depth int) {
+		for _, sub := range s.Subsections {
+			var title string
+			if h, ok := sub.Start.(*ast.Heading); ok {
+				title = string(h.Text(m.markdown))
+			}
+			collapsed := m.toc.collapsed[sub]
+			entries = append(entries, tocEntry{
+				section:     sub,
+				depth:       depth,
+				title:       title,
+				hasChildren: len(sub.Subsections) > 0,
+				collapsed:   collapsed,
+			})
+			if !collapsed {
+				walk(sub, depth+1)
+			}
+		}
+	}
+	walk(m.index.TableOfContents(), 0)
+	return entries
+}
+
+// currentTOCEntry returns the index of the entry to highlight as the current
+// section: the current section itself, or its nearest visible ancestor if it
+// is hidden inside a collapsed section. Returns -1 if there is none.
+func (m *Model) currentTOCEntry(entries []tocEntry) int {
+	if m.index == nil {
+		return -1
+	}
+	heading := m.headingAt(m.lineOffset)
+	if heading == nil {
+		return -1
+	}
+	path := sectionPath(m.index.TableOfContents(), heading)
+
+	current := -1
+	for i, e := range entries {
+		for _, s := range path {
+			if e.section == s {
+				current = i
+			}
+		}
+	}
+	return current
+}
+
+// SetSectionCollapsed collapses or expands the given section in the table of
+// contents sidebar.
+func (m *Model) SetSectionCollapsed(s *indexer.Section, collapsed bool) {
+	if collapsed {
+		if m.toc.collapsed == nil {
+			m.toc.collapsed = map[*indexer.Section]bool{}
+		}
+		m.toc.collapsed[s] = true
+	} else {
+		delete(m.toc.collapsed, s)
+	}
+}
+
+// Index returns the index of the document's headings and anchors, or nil if no
+// document is set.
+func (m *Model) Index() *indexer.DocumentIndex {
+	return m.index
+}
+
+// SelectSection scrolls the given section's heading to the top of the
+// viewport and selects it. The section must come from the Model's Index.
+func (m *Model) SelectSection(s *indexer.Section) {
+	if m.index == nil {
+		return
+	}
+	// Several sections may share an anchor; SelectAnchor cycles through them.
+	sections, _ := m.index.Lookup(s.Anchor)
+	for range sections {
+		if !m.SelectAnchor(s.Anchor) {
+			return
+		}
+		if m.selection != nil && m.selection.Node == s.Start {
+			break
+		}
+	}
+	if m.selection != nil {
+		m.scrollToOffset(m.selection.Start)
+	}
+}
+
+// SectionMarkdown returns the Markdown source of the given section, from its
+// heading up to the next heading at the same or a higher level. The section
+// must come from the Model's Index.
+func (m *Model) SectionMarkdown(s *indexer.Section) string {
+	start, end, ok := -1, -1, false
+	for n := s.Start; n != nil && n != s.End; n = n.NextSibling() {
+		if from, to, found := m.nodeSourceRange(n); found {
+			if !ok {
+				start = from
+			}
+			end, ok = to, true
+		}
+	}
+	if !ok {
+		return ""
+	}
+	return string(m.markdown[start:end])
+}
+
+// handleTOCKey handles key presses while the sidebar has focus. It reports
+// whether the key was handled.
+func (m *Model) handleTOCKey(msg tea.KeyPressMsg) (tea.Cmd, bool) {
+	entries := m.tocEntries()
+	last := len(entries) - 1
+
+	switch {
+	case key.Matches(msg, m.KeyMap.ToggleTOC):
+		m.SetTOC(false)
+	case key.Matches(msg, m.KeyMap.FocusTOC):
+		m.toc.focused = false
+	case key.Matches(msg, m.KeyMap.Up):
+		m.toc.cursor--
+	case key.Matches(msg, m.KeyMap.Down):
+		m.toc.cursor++
+	case key.Matches(msg, m.KeyMap.PageUp):
+		m.toc.cursor -= m.pageSize
+	case key.Matches(msg, m.KeyMap.PageDown):
+		m.toc.cursor += m.pageSize
+	case key.Matches(msg, m.KeyMap.GotoTop, m.KeyMap.Home):
+		m.toc.cursor = 0
+	case key.Matches(msg, m.KeyMap.GotoEnd):
+		m.toc.cursor = last
+	case key.Matches(msg, m.KeyMap.TOCCollapse):
+		if m.toc.cursor >= 0 && m.toc.cursor <= last {
+			e := entries[m.toc.cursor]
+			if e.hasChildren && !e.collapsed {
+				m.SetSectionCollapsed(e.section, true)
+			} else {
+				// Move to the parent entry.
+				for i := m.toc.cursor - 1; i >= 0; i-- {
+					if entries[i].depth < e.depth {
+						m.toc.cursor = i
+						break
+					}
+				}
+			}
+		}
+	case key.Matches(msg, m.KeyMap.TOCExpand):
+		if m.toc.cursor >= 0 && m.toc.cursor <= last {
+			e := entries[m.toc.cursor]
+			if e.collapsed {
+				m.SetSectionCollapsed(e.section, false)
+			} else if e.hasChildren {
+				m.toc.cursor++
+			}
+		}
+	case key.Matches(msg, m.KeyMap.TOCSelect):
+		if m.toc.cursor >= 0 && m.toc.cursor <= last {
+			m.SelectSection(entries[m.toc.cursor].section)
+			m.toc.focused = false
+		}
+	default:
+		return nil, false
+	}
+
+	if m.toc.cursor > last {
+		m.toc.cursor = last
+	}
+	if m.toc.cursor < 0 {
+		m.toc.cursor = 0
+	}
+	m.toc.offset = tocOffset(m.toc.offset, m.toc.cursor, m.pageSize, len(entries))
+	return nil, true
+}
+
+// tocOffset adjusts offset so that the entry at target is among the rows
+// shown.
+func tocOffset(offset, target, rows, count int) int {
+	if target >= 0 {
+		if target < offset {
+			offset = target
+		}
+		if target >= offset+rows {
+			offset = target - rows + 1
+		}
+	}
+	if offset > count-rows {
+		offset = count - rows
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// renderTOC renders the given number of sidebar rows. Each row is exactly
+// tocWidth columns wide and ends with a separator.
+func (m *Model) renderTOC(rows int) []string {
+	width := m.tocWidth()
+	textWidth := width - 2 // leading space and separator
+
+	baseStyle := lipgloss.NewStyle()
+	sepStyle := lipgloss.NewStyle()
+	if m.theme != nil {
+		if bg := m.theme.Get(chroma.Background).Background; bg.IsSet() {
+			baseStyle = baseStyle.Background(lipgloss.Color(bg.String()))
+		}
+		if c := m.theme.Get(chroma.Text).Colour; c.IsSet() {
+			baseStyle = baseStyle.Foreground(lipgloss.Color(c.String()))
+		}
+		if c := m.theme.Get(chroma.Comment).Colour; c.IsSet() {
+			sepStyle = sepStyle.Foreground(lipgloss.Color(c.String()))
+		}
+	}
+	currentStyle := baseStyle.Bold(true)
+	cursorStyle := baseStyle.Reverse(true)
+	if m.theme != nil {
+		if c := m.theme.Get(chroma.GenericHeading).Colour; c.IsSet() {
+			currentStyle = currentStyle.Foreground(lipgloss.Color(c.String()))
+		}
+	}
+
+	entries := m.tocEntries()
+	current := m.currentTOCEntry(entries)
+
+	target := current
+	if m.toc.focused {
+		target = m.toc.cursor
+	}
+	offset := tocOffset(m.toc.offset, target, rows, len(entries))
+
+	out := make([]string, rows)
+	for i := range out {
+		var text string
+		style := baseStyle
+		if i == 0 && len(entries) == 0 {
+			text = "No headings"
+			style = sepStyle
+		} else if idx := offset + i; idx < len(entries) {
+			e := entries[idx]
+			marker := "  "
+			if e.hasChildren {
+				if e.collapsed {
+					marker = "▸ "
+				} else {
+					marker = "▾ "
+				}
+			}
+			text = strings.Repeat("  ", e.depth) + marker + e.title
+			switch {
+			case m.toc.focused && idx == m.toc.cursor:
+				style = cursorStyle
+			case idx == current:
+				style = currentStyle
+			}
+		}
+
+		if ansi.StringWidth(text) > textWidth {
+			text = ansi.Truncate(text, textWidth, "…")
+		}
+		text += strings.Repeat(" ", max(textWidth-ansi.StringWidth(text), 0))
+		out[i] = baseStyle.Render(" ") + style.Render(text) + sepStyle.Render("│")
+	}
+	return out
+}
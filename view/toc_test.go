@@ -0,0 +1,180 @@
+package view
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/pgavlin/goldmark/ast"
+	"github.com/pgavlin/markdown-kit/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tocDoc() string {
+	filler := strings.Repeat("Some text.\n\n", 10)
+	return "# Intro\n\n" + filler +
+		"## Setup\n\n" + filler +
+		"### Install\n\n" + filler +
+		"## Usage\n\n" + filler +
+		"# Reference\n\n" + filler
+}
+
+func tocTitles(entries []tocEntry) []string {
+	titles := make([]string, len(entries))
+	for i, e := range entries {
+		titles[i] = e.title
+	}
+	return titles
+}
+
+func TestTOC_OptionShrinksContent(t *testing.T) {
+	m := NewModel(WithTOC(true), WithTOCWidth(24))
+	m.SetText("test.md", tocDoc())
+	m.SetSize(80, 10)
+
+	assert.True(t, m.TOCVisible())
+	assert.Equal(t, 56, m.EffectiveWidth())
+
+	lines := strings.Split(m.View(), "\n")
+	require.Len(t, lines, 10)
+	for _, l := range lines {
+		assert.Equal(t, 80, ansi.StringWidth(l))
+	}
+	first := ansi.Strip(lines[0])
+	assert.True(t, strings.HasPrefix(first, " ▾ Intro"), "sidebar should lead the line: %q", first)
+	assert.Equal(t, "│", string([]rune(first)[23]))
+
+	m.SetTOC(false)
+	assert.Equal(t, 80, m.EffectiveWidth())
+}
+
+func TestTOC_HiddenWhenTooNarrow(t *testing.T) {
+	m := NewModel(WithTOC(true))
+	m.SetText("test.md", tocDoc())
+	m.SetSize(22, 10)
+	assert.Equal(t, 22, m.EffectiveWidth())
+}
+
+func TestTOC_Entries(t *testing.T) {
+	m := NewModel()
+	m.SetText("test.md", tocDoc())
+
+	entries := m.tocEntries()
+	assert.Equal(t, []string{"Intro", "Setup", "Install", "Usage", "Reference"}, tocTitles(entries))
+	assert.Equal(t, []int{0, 1, 2, 1, 0}, []int{entries[0].depth, entries[1].depth, entries[2].depth, entries[3].depth, entries[4].depth})
+	assert.True(t, entries[0].hasChildren)
+	assert.False(t, entries[4].hasChildren)
+
+	m.SetSectionCollapsed(entries[1].section, true)
+	assert.Equal(t, []string{"Intro", "Setup", "Usage", "Reference"}, tocTitles(m.tocEntries()))
+}
+
+func TestTOC_CurrentSectionTracksScroll(t *testing.T) {
+	m := NewModel(WithTOC(true))
+	m.SetText("test.md", tocDoc())
+	m.SetSize(80, 10)
+
+	require.NotNil(t, m.CurrentSection())
+	assert.Equal(t, "intro", m.CurrentSection().Anchor)
+
+	m.SelectAnchor("install")
+	m.scrollToOffset(m.selection.Start)
+	assert.Equal(t, "install", m.CurrentSection().Anchor)
+	entries := m.tocEntries()
+	assert.Equal(t, 2, m.currentTOCEntry(entries))
+
+	// A collapsed ancestor stands in for hidden subsections.
+	m.SetSectionCollapsed(entries[1].section, true)
+	assert.Equal(t, 1, m.currentTOCEntry(m.tocEntries()))
+}
+
+func TestTOC_KeyNavigationAndSelect(t *testing.T) {
+	m := NewModel(WithTheme(styles.Pulumi))
+	m.SetText("test.md", tocDoc())
+	m.SetGutter(true)
+	m.SetSize(80, 10)
+
+	m, _ = m.Update(keyMsg('t'))
+	assert.True(t, m.TOCVisible())
+	assert.True(t, m.TOCFocused())
+	assert.Equal(t, 0, m.toc.cursor)
+
+	lines := strings.Split(m.View(), "\n")
+	assert.Contains(t, ansi.Strip(lines[len(lines)-1]), "-- CONTENTS --")
+
+	// Navigation keys move the sidebar cursor, not the document.
+	m, _ = m.Update(keyMsg('j'))
+	m, _ = m.Update(keyMsg('j'))
+	assert.Equal(t, 2, m.toc.cursor)
+	assert.Equal(t, 0, m.LineOffset())
+
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	assert.False(t, m.TOCFocused())
+	assert.True(t, m.TOCVisible())
+	require.NotNil(t, m.Selection())
+	heading, ok := m.Selection().Node.(*ast.Heading)
+	require.True(t, ok)
+	assert.Equal(t, "Install", string(heading.Text(m.markdown)))
+	assert.Equal(t, "install", m.CurrentSection().Anchor)
+
+	// Once unfocused, navigation keys scroll the document again.
+	offset := m.LineOffset()
+	m, _ = m.Update(keyMsg('j'))
+	assert.Equal(t, offset+1, m.LineOffset())
+
+	m, _ = m.Update(keyMsg('t'))
+	assert.False(t, m.TOCVisible())
+}
+
+func TestTOC_CollapseExpandKeys(t *testing.T) {
+	m := NewModel()
+	m.SetText("test.md", tocDoc())
+	m.SetSize(80, 10)
+
+	m, _ = m.Update(keyMsg('o'))
+	require.True(t, m.TOCFocused())
+
+	// Collapse "Intro", then expand it again.
+	m, _ = m.Update(keyMsg('h'))
+	assert.Equal(t, []string{"Intro", "Reference"}, tocTitles(m.tocEntries()))
+	m, _ = m.Update(keyMsg('l'))
+	assert.Len(t, m.tocEntries(), 5)
+
+	// Expanding an expanded section moves to its first child; collapsing a
+	// leaf moves to its parent.
+	m, _ = m.Update(keyMsg('l'))
+	assert.Equal(t, 1, m.toc.cursor)
+	m, _ = m.Update(keyMsg('j'))
+	m, _ = m.Update(keyMsg('h'))
+	assert.Equal(t, 1, m.toc.cursor)
+
+	// Unhandled keys return focus to the document.
+	m, _ = m.Update(keyMsg('/'))
+	assert.False(t, m.TOCFocused())
+	assert.True(t, m.Searching())
+}
+
+func TestTOC_NoHeadings(t *testing.T) {
+	m := NewModel(WithTOC(true))
+	m.SetText("test.md", "Just text.\n")
+	m.SetSize(80, 5)
+
+	assert.Nil(t, m.CurrentSection())
+	assert.Contains(t, ansi.Strip(m.View()), "No headings")
+}
+
+func TestSectionMarkdown(t *testing.T) {
+	m := NewModel()
+	m.SetText("test.md", "# Intro\n\nText.\n\n## Setup\n\n```sh\nmake\n```\n\n### Install\n\nRun it.\n\n## Usage\n\nUse it.\n")
+	m.SetSize(80, 10)
+
+	sections, ok := m.Index().Lookup("setup")
+	require.True(t, ok)
+	assert.Equal(t, "## Setup\n\n```sh\nmake\n```\n\n### Install\n\nRun it.", m.SectionMarkdown(sections[0]))
+
+	sections, ok = m.Index().Lookup("usage")
+	require.True(t, ok)
+	assert.Equal(t, "## Usage\n\nUse it.", m.SectionMarkdown(sections[0]))
+}